@@ -0,0 +1,133 @@
+package cephfs
+
+/*
+#include <unistd.h>
+*/
+import "C"
+
+import (
+	"os"
+	"strconv"
+)
+
+// AccessMode is a set of permissions checked by Access.
+type AccessMode uint32
+
+const (
+	// AccessExists only checks that the file exists.
+	AccessExists = AccessMode(C.F_OK)
+	// AccessRead checks that the file can be read.
+	AccessRead = AccessMode(C.R_OK)
+	// AccessWrite checks that the file can be written.
+	AccessWrite = AccessMode(C.W_OK)
+	// AccessExec checks that the file can be executed, or the directory
+	// searched.
+	AccessExec = AccessMode(C.X_OK)
+)
+
+// credentials returns the user and groups used by the mount for permission
+// checks. Unless the credentials were set with SetMountPerms they are taken
+// from the client_mount_uid and client_mount_gid options, or from the
+// process, the same way libcephfs picks them. The supplementary groups of the
+// process are included when the group is taken from the process.
+func (mount *MountInfo) credentials() (uint32, []uint32, error) {
+	if p := mount.perms; p != nil {
+		gids := []uint32{uint32(p.gid)}
+		for _, gid := range p.gidList {
+			gids = append(gids, uint32(gid))
+		}
+		return uint32(p.uid), gids, nil
+	}
+	uid, _, err := mount.credentialOption("client_mount_uid", os.Getuid())
+	if err != nil {
+		return 0, nil, err
+	}
+	gid, isSet, err := mount.credentialOption("client_mount_gid", os.Getgid())
+	if err != nil {
+		return 0, nil, err
+	}
+	gids := []uint32{gid}
+	if !isSet {
+		groups, err := os.Getgroups()
+		if err != nil {
+			return 0, nil, err
+		}
+		for _, g := range groups {
+			if uint32(g) != gid {
+				gids = append(gids, uint32(g))
+			}
+		}
+	}
+	return uid, gids, nil
+}
+
+// credentialOption returns the value of a client_mount_uid/gid option and
+// true, or def and false if the option is not set.
+func (mount *MountInfo) credentialOption(name string, def int) (uint32, bool, error) {
+	value, err := mount.GetConfigOption(name)
+	if err != nil {
+		return 0, false, err
+	}
+	id, err := strconv.Atoi(value)
+	if err != nil || id < 0 {
+		return uint32(def), false, nil
+	}
+	return uint32(id), true, nil
+}
+
+// Access checks whether the mount may access the file at the given path
+// with the given mode, following symbolic links. It returns nil if all the
+// permissions in mode are granted.
+//
+// Access is an approximation computed in Go, it does not have the semantics
+// of access(2) and libcephfs is not asked to check the permissions. Only the
+// owner, group and other permission bits of the file are compared with the
+// credentials of the mount: POSIX ACLs, file capabilities and the
+// restrictions of the cephx capabilities of the client are not taken into
+// account. The credentials are the ones set with SetMountPerms or, if it was
+// not used, the client_mount_uid and client_mount_gid options or else the
+// user, group and supplementary groups of the process. A nil result does not
+// guarantee that a following operation on the file succeeds.
+func (mount *MountInfo) Access(path string, mode AccessMode) error {
+	stx, err := mount.Statx(path, StatxMode|StatxUid|StatxGid, 0)
+	if err != nil {
+		return err
+	}
+	if mode == AccessExists {
+		return nil
+	}
+	uid, gids, err := mount.credentials()
+	if err != nil {
+		return err
+	}
+	if uid == 0 {
+		// root may do anything, except executing files that nobody may
+		// execute
+		if mode&AccessExec == 0 || stx.isDir() || stx.Mode&0111 != 0 {
+			return nil
+		}
+		return errAccess
+	}
+	var bits AccessMode
+	switch {
+	case uid == stx.Uid:
+		bits = AccessMode(stx.Mode>>6) & 7
+	case containsGid(gids, stx.Gid):
+		bits = AccessMode(stx.Mode>>3) & 7
+	default:
+		bits = AccessMode(stx.Mode) & 7
+	}
+	if mode&^bits != 0 {
+		return errAccess
+	}
+	return nil
+}
+
+func containsGid(gids []uint32, gid uint32) bool {
+	for _, g := range gids {
+		if g == gid {
+			return true
+		}
+	}
+	return false
+}
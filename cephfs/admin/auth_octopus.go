@@ -0,0 +1,144 @@
+// +build !luminous,!mimic,!nautilus
+
+package admin
+
+import (
+	"github.com/ceph/go-ceph/internal/commands"
+)
+
+// SubVolumeAccessLevel is the level of access granted to a ceph client on a
+// subvolume.
+type SubVolumeAccessLevel string
+
+const (
+	// ReadOnlyAccess grants read-only access to the subvolume.
+	ReadOnlyAccess = SubVolumeAccessLevel("r")
+	// ReadWriteAccess grants read-write access to the subvolume.
+	ReadWriteAccess = SubVolumeAccessLevel("rw")
+)
+
+// SubVolumeAuthOptions are used to specify optional, non-identifying, values
+// to be used when authorizing a ceph client on a subvolume.
+type SubVolumeAuthOptions struct {
+	AccessLevel     SubVolumeAccessLevel
+	TenantID        string
+	AllowExistingID bool
+}
+
+type subVolumeAuthFields struct {
+	Prefix          string `json:"prefix"`
+	Format          string `json:"format"`
+	VolName         string `json:"vol_name"`
+	GroupName       string `json:"group_name,omitempty"`
+	SubName         string `json:"sub_name"`
+	AuthID          string `json:"auth_id"`
+	AccessLevel     string `json:"access_level,omitempty"`
+	TenantID        string `json:"tenant_id,omitempty"`
+	AllowExistingID bool   `json:"allow_existing_id,omitempty"`
+}
+
+func (o *SubVolumeAuthOptions) toFields(v, g, s, a string) *subVolumeAuthFields {
+	return &subVolumeAuthFields{
+		Prefix:          "fs subvolume authorize",
+		Format:          "json",
+		VolName:         v,
+		GroupName:       g,
+		SubName:         s,
+		AuthID:          a,
+		AccessLevel:     string(o.AccessLevel),
+		TenantID:        o.TenantID,
+		AllowExistingID: o.AllowExistingID,
+	}
+}
+
+// AuthorizeSubVolume grants the ceph client with the given auth ID access to
+// the subvolume, creating the client if needed. The secret key of the client
+// is returned. If o is nil ceph grants read-write access.
+//
+// Similar To:
+//  ceph fs subvolume authorize <volume> <subvolume> <auth_id> --group-name=<group>
+func (fsa *FSAdmin) AuthorizeSubVolume(
+	volume, group, subvolume, authID string,
+	o *SubVolumeAuthOptions) (string, error) {
+
+	if o == nil {
+		o = &SubVolumeAuthOptions{}
+	}
+	f := o.toFields(volume, group, subvolume, authID)
+	// ceph replies with the plain key, not json
+	return parsePathResponse(fsa.marshalMgrCommand(f))
+}
+
+// DeauthorizeSubVolume revokes the access of the ceph client with the given
+// auth ID to the subvolume.
+//
+// Similar To:
+//  ceph fs subvolume deauthorize <volume> <subvolume> <auth_id> --group-name=<group>
+func (fsa *FSAdmin) DeauthorizeSubVolume(volume, group, subvolume, authID string) error {
+	m := map[string]string{
+		"prefix":   "fs subvolume deauthorize",
+		"vol_name": volume,
+		"sub_name": subvolume,
+		"auth_id":  authID,
+		"format":   "json",
+	}
+	if group != NoGroup {
+		m["group_name"] = group
+	}
+	return fsa.marshalMgrCommand(m).NoData().End()
+}
+
+// parseAuthIDs merges the list of single valued objects, mapping an auth ID
+// to an access level, returned by ceph into one map.
+func parseAuthIDs(res commands.Response) (map[string]SubVolumeAccessLevel, error) {
+	var items []map[string]SubVolumeAccessLevel
+	if err := res.NoStatus().Unmarshal(&items).End(); err != nil {
+		return nil, err
+	}
+	ids := make(map[string]SubVolumeAccessLevel)
+	for _, item := range items {
+		for k, v := range item {
+			ids[k] = v
+		}
+	}
+	return ids, nil
+}
+
+// ListSubVolumeAuthIDs returns the auth IDs of the ceph clients authorized
+// to access the subvolume, mapped to their level of access.
+//
+// Similar To:
+//  ceph fs subvolume authorized_list <volume> <subvolume> --group-name=<group>
+func (fsa *FSAdmin) ListSubVolumeAuthIDs(
+	volume, group, subvolume string) (map[string]SubVolumeAccessLevel, error) {
+
+	m := map[string]string{
+		"prefix":   "fs subvolume authorized_list",
+		"vol_name": volume,
+		"sub_name": subvolume,
+		"format":   "json",
+	}
+	if group != NoGroup {
+		m["group_name"] = group
+	}
+	return parseAuthIDs(fsa.marshalMgrCommand(m))
+}
+
+// EvictSubVolumeClients evicts the clients that use the given auth ID and
+// have the subvolume mounted.
+//
+// Similar To:
+//  ceph fs subvolume evict <volume> <subvolume> <auth_id> --group-name=<group>
+func (fsa *FSAdmin) EvictSubVolumeClients(volume, group, subvolume, authID string) error {
+	m := map[string]string{
+		"prefix":   "fs subvolume evict",
+		"vol_name": volume,
+		"sub_name": subvolume,
+		"auth_id":  authID,
+		"format":   "json",
+	}
+	if group != NoGroup {
+		m["group_name"] = group
+	}
+	return fsa.marshalMgrCommand(m).NoData().End()
+}
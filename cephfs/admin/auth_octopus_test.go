@@ -0,0 +1,95 @@
+// +build !luminous,!mimic,!nautilus
+
+package admin
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ceph/go-ceph/internal/commands"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var sampleAuthIDs = []byte(`
+[
+    {
+        "alice": "rw"
+    },
+    {
+        "bob": "r"
+    }
+]
+`)
+
+func TestParseAuthIDs(t *testing.T) {
+	R := commands.NewResponse
+	t.Run("error", func(t *testing.T) {
+		_, err := parseAuthIDs(R(nil, "", errors.New("snark")))
+		assert.Error(t, err)
+		assert.Equal(t, "snark", err.Error())
+	})
+	t.Run("statusSet", func(t *testing.T) {
+		_, err := parseAuthIDs(R(nil, "unexpected!", nil))
+		assert.Error(t, err)
+	})
+	t.Run("empty", func(t *testing.T) {
+		ids, err := parseAuthIDs(R([]byte("[]"), "", nil))
+		assert.NoError(t, err)
+		assert.Len(t, ids, 0)
+	})
+	t.Run("ok", func(t *testing.T) {
+		ids, err := parseAuthIDs(R(sampleAuthIDs, "", nil))
+		assert.NoError(t, err)
+		assert.Equal(t,
+			map[string]SubVolumeAccessLevel{
+				"alice": ReadWriteAccess,
+				"bob":   ReadOnlyAccess,
+			},
+			ids)
+	})
+}
+
+func TestAuthorizeSubVolume(t *testing.T) {
+	if serverIsNautilus {
+		t.Skipf("can only execute on octopus and later servers")
+	}
+	fsa := getFSAdmin(t)
+	volume := "cephfs"
+	subname := "authMe1"
+
+	err := fsa.CreateSubVolume(volume, NoGroup, subname, nil)
+	assert.NoError(t, err)
+	defer func() {
+		err := fsa.RemoveSubVolume(volume, NoGroup, subname)
+		assert.NoError(t, err)
+	}()
+
+	key, err := fsa.AuthorizeSubVolume(volume, NoGroup, subname, "alice", nil)
+	assert.NoError(t, err)
+	assert.NotEqual(t, "", key)
+	assert.NotContains(t, key, "\n")
+
+	key, err = fsa.AuthorizeSubVolume(volume, NoGroup, subname, "bob",
+		&SubVolumeAuthOptions{AccessLevel: ReadOnlyAccess})
+	assert.NoError(t, err)
+	assert.NotEqual(t, "", key)
+
+	ids, err := fsa.ListSubVolumeAuthIDs(volume, NoGroup, subname)
+	assert.NoError(t, err)
+	assert.Equal(t, ReadWriteAccess, ids["alice"])
+	assert.Equal(t, ReadOnlyAccess, ids["bob"])
+
+	// nobody has the subvolume mounted, evict is a no-op
+	err = fsa.EvictSubVolumeClients(volume, NoGroup, subname, "alice")
+	assert.NoError(t, err)
+
+	err = fsa.DeauthorizeSubVolume(volume, NoGroup, subname, "alice")
+	assert.NoError(t, err)
+	err = fsa.DeauthorizeSubVolume(volume, NoGroup, subname, "bob")
+	assert.NoError(t, err)
+
+	ids, err = fsa.ListSubVolumeAuthIDs(volume, NoGroup, subname)
+	assert.NoError(t, err)
+	assert.Len(t, ids, 0)
+}
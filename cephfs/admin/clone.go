@@ -4,6 +4,8 @@ package admin
 
 import (
 	"strings"
+
+	"github.com/ceph/go-ceph/internal/commands"
 )
 
 const notProtectedSuffix = "is not protected"
@@ -13,7 +15,7 @@ const notProtectedSuffix = "is not protected"
 // requirement for a snapshot to be protected prior to cloning varies by Ceph
 // version.
 type NotProtectedError struct {
-	response
+	commands.Response
 }
 
 // CloneOptions are used to specify optional values to be used when creating a
@@ -52,11 +54,11 @@ func (fsa *FSAdmin) CloneSubVolumeSnapshot(volume, group, subvolume, snapshot, n
 	return checkCloneResponse(fsa.marshalMgrCommand(m))
 }
 
-func checkCloneResponse(res response) error {
-	if strings.HasSuffix(res.status, notProtectedSuffix) {
-		return NotProtectedError{response: res}
+func checkCloneResponse(res commands.Response) error {
+	if strings.HasSuffix(res.Status(), notProtectedSuffix) {
+		return NotProtectedError{Response: res}
 	}
-	return res.noData().End()
+	return res.NoData().End()
 }
 
 // CloneState is used to define constant values used to determine the state of
@@ -72,6 +74,8 @@ const (
 	CloneComplete = CloneState("complete")
 	// CloneFailed is the state of a failed clone.
 	CloneFailed = CloneState("failed")
+	// CloneCanceled is the state of a canceled clone.
+	CloneCanceled = CloneState("canceled")
 )
 
 // CloneSource contains values indicating the source of a clone.
@@ -82,19 +86,26 @@ type CloneSource struct {
 	Snapshot  string `json:"snapshot"`
 }
 
+// CloneFailure contains the error reported by ceph for a failed clone.
+type CloneFailure struct {
+	Errno    string `json:"errno"`
+	ErrorMsg string `json:"error_msg"`
+}
+
 // CloneStatus reports on the status of a subvolume clone.
 type CloneStatus struct {
-	State  CloneState  `json:"state"`
-	Source CloneSource `json:"source"`
+	State   CloneState    `json:"state"`
+	Source  CloneSource   `json:"source"`
+	Failure *CloneFailure `json:"failure,omitempty"`
 }
 
 type cloneStatusWrapper struct {
 	Status CloneStatus `json:"status"`
 }
 
-func parseCloneStatus(res response) (*CloneStatus, error) {
+func parseCloneStatus(res commands.Response) (*CloneStatus, error) {
 	var status cloneStatusWrapper
-	if err := res.noStatus().unmarshal(&status).End(); err != nil {
+	if err := res.NoStatus().Unmarshal(&status).End(); err != nil {
 		return nil, err
 	}
 	return &status.Status, nil
@@ -132,5 +143,5 @@ func (fsa *FSAdmin) CancelClone(volume, group, clone string) error {
 	if group != NoGroup {
 		m["group_name"] = group
 	}
-	return fsa.marshalMgrCommand(m).noData().End()
+	return fsa.marshalMgrCommand(m).NoData().End()
 }
@@ -7,6 +7,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ceph/go-ceph/internal/commands"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -33,8 +35,23 @@ var sampleCloneStatusInProg = []byte(`{
   }
 }`)
 
+var sampleCloneStatusFailed = []byte(`{
+  "status": {
+    "state": "failed",
+    "source": {
+      "volume": "cephfs",
+      "subvolume": "subvol1",
+      "snapshot": "snap1"
+    },
+    "failure": {
+      "errno": "122",
+      "error_msg": "Disk quota exceeded"
+    }
+  }
+}`)
+
 func TestParseCloneStatus(t *testing.T) {
-	R := newResponse
+	R := commands.NewResponse
 	t.Run("error", func(t *testing.T) {
 		_, err := parseCloneStatus(R(nil, "", errors.New("flub")))
 		assert.Error(t, err)
@@ -68,6 +85,18 @@ func TestParseCloneStatus(t *testing.T) {
 			assert.EqualValues(t, "subvol1", status.Source.SubVolume)
 			assert.EqualValues(t, "snap1", status.Source.Snapshot)
 			assert.EqualValues(t, "", status.Source.Group)
+			assert.Nil(t, status.Failure)
+		}
+	})
+	t.Run("okFailed", func(t *testing.T) {
+		status, err := parseCloneStatus(R(sampleCloneStatusFailed, "", nil))
+		assert.NoError(t, err)
+		if assert.NotNil(t, status) {
+			assert.EqualValues(t, CloneFailed, status.State)
+			if assert.NotNil(t, status.Failure) {
+				assert.Equal(t, "122", status.Failure.Errno)
+				assert.Equal(t, "Disk quota exceeded", status.Failure.ErrorMsg)
+			}
 		}
 	})
 }
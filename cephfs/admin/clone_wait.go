@@ -0,0 +1,147 @@
+// +build !luminous,!mimic
+
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultCloneMinInterval = 500 * time.Millisecond
+	defaultCloneMaxInterval = 10 * time.Second
+)
+
+// ErrCloneCanceled is returned by WaitForClone if the clone was canceled
+// before it completed.
+var ErrCloneCanceled = errors.New("clone canceled")
+
+// CloneFailedError is returned by WaitForClone if the clone failed. The
+// errno and reason are the values reported by ceph in the clone status.
+type CloneFailedError struct {
+	Errno  int
+	Reason string
+}
+
+// Error implements the error interface.
+func (e CloneFailedError) Error() string {
+	return fmt.Sprintf("clone failed: %s (errno %d)", e.Reason, e.Errno)
+}
+
+// ErrorCode returns the negated errno reported for the failed clone, like
+// the error codes of other ceph errors.
+func (e CloneFailedError) ErrorCode() int {
+	return -e.Errno
+}
+
+func newCloneFailedError(f *CloneFailure) CloneFailedError {
+	if f == nil {
+		return CloneFailedError{Reason: "unknown error"}
+	}
+	// ceph reports the errno as a string, keep zero if it is not a number
+	errno, _ := strconv.Atoi(f.Errno)
+	return CloneFailedError{Errno: errno, Reason: f.ErrorMsg}
+}
+
+// WaitForCloneOptions are used to specify optional values to be used when
+// waiting for a clone.
+type WaitForCloneOptions struct {
+	// MinInterval is the delay before the first status check is repeated.
+	// The delay doubles after every check, up to MaxInterval.
+	MinInterval time.Duration
+	MaxInterval time.Duration
+	// Progress, if set, receives the status of the clone after every check.
+	// The channel is closed when WaitForClone returns.
+	Progress chan<- CloneStatus
+}
+
+// WaitForClone polls the status of a clone, backing off between checks,
+// until the clone completes, fails, is canceled or the context ends. The
+// final status is returned once the clone is complete. A CloneFailedError is
+// returned for a failed clone and ErrCloneCanceled for a canceled one. If the
+// context ends first the clone is canceled, on a best effort basis, and the
+// error of the context is returned.
+func (fsa *FSAdmin) WaitForClone(
+	ctx context.Context,
+	volume, group, clone string,
+	o *WaitForCloneOptions) (*CloneStatus, error) {
+
+	if o == nil {
+		o = &WaitForCloneOptions{}
+	}
+	return waitForClone(
+		ctx,
+		func() (*CloneStatus, error) {
+			return fsa.CloneStatus(volume, group, clone)
+		},
+		func() error {
+			return fsa.CancelClone(volume, group, clone)
+		},
+		o)
+}
+
+func waitForClone(
+	ctx context.Context,
+	status func() (*CloneStatus, error),
+	cancel func() error,
+	o *WaitForCloneOptions) (*CloneStatus, error) {
+
+	if o.Progress != nil {
+		defer close(o.Progress)
+	}
+	interval := o.MinInterval
+	if interval <= 0 {
+		interval = defaultCloneMinInterval
+	}
+	maxInterval := o.MaxInterval
+	if maxInterval <= 0 {
+		maxInterval = defaultCloneMaxInterval
+	}
+	if interval > maxInterval {
+		interval = maxInterval
+	}
+
+	for {
+		s, err := status()
+		if err != nil {
+			return nil, err
+		}
+		if o.Progress != nil {
+			select {
+			case o.Progress <- *s:
+			case <-ctx.Done():
+				return nil, cancelClone(ctx, cancel)
+			}
+		}
+		switch s.State {
+		case CloneComplete:
+			return s, nil
+		case CloneFailed:
+			return s, newCloneFailedError(s.Failure)
+		case CloneCanceled:
+			return s, ErrCloneCanceled
+		}
+
+		t := time.NewTimer(interval)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return nil, cancelClone(ctx, cancel)
+		}
+		interval *= 2
+		if interval > maxInterval {
+			interval = maxInterval
+		}
+	}
+}
+
+// cancelClone stops the clone after the context ended. The clone may have
+// finished in the meantime, so errors from the cancel are not reported.
+func cancelClone(ctx context.Context, cancel func() error) error {
+	_ = cancel()
+	return ctx.Err()
+}
@@ -0,0 +1,190 @@
+// +build !luminous,!mimic
+
+package admin
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClone returns the given states in order, repeating the last one.
+type fakeClone struct {
+	states   []CloneState
+	failure  *CloneFailure
+	calls    int
+	canceled bool
+}
+
+func (f *fakeClone) status() (*CloneStatus, error) {
+	i := f.calls
+	if i >= len(f.states) {
+		i = len(f.states) - 1
+	}
+	f.calls++
+	return &CloneStatus{State: f.states[i], Failure: f.failure}, nil
+}
+
+func (f *fakeClone) cancel() error {
+	f.canceled = true
+	return nil
+}
+
+var fastWait = &WaitForCloneOptions{
+	MinInterval: time.Millisecond,
+	MaxInterval: 2 * time.Millisecond,
+}
+
+func TestWaitForCloneStates(t *testing.T) {
+	ctx := context.Background()
+	t.Run("complete", func(t *testing.T) {
+		f := &fakeClone{states: []CloneState{
+			ClonePending, CloneInProgress, CloneInProgress, CloneComplete}}
+		s, err := waitForClone(ctx, f.status, f.cancel, fastWait)
+		assert.NoError(t, err)
+		if assert.NotNil(t, s) {
+			assert.Equal(t, CloneComplete, s.State)
+		}
+		assert.Equal(t, 4, f.calls)
+		assert.False(t, f.canceled)
+	})
+	t.Run("failed", func(t *testing.T) {
+		f := &fakeClone{
+			states:  []CloneState{CloneInProgress, CloneFailed},
+			failure: &CloneFailure{Errno: "122", ErrorMsg: "Disk quota exceeded"},
+		}
+		s, err := waitForClone(ctx, f.status, f.cancel, fastWait)
+		assert.Error(t, err)
+		var cfe CloneFailedError
+		if assert.True(t, errors.As(err, &cfe)) {
+			assert.Equal(t, 122, cfe.Errno)
+			assert.Equal(t, -122, cfe.ErrorCode())
+			assert.Equal(t, "Disk quota exceeded", cfe.Reason)
+		}
+		if assert.NotNil(t, s) {
+			assert.Equal(t, CloneFailed, s.State)
+		}
+	})
+	t.Run("failedNoDetails", func(t *testing.T) {
+		f := &fakeClone{states: []CloneState{CloneFailed}}
+		_, err := waitForClone(ctx, f.status, f.cancel, fastWait)
+		var cfe CloneFailedError
+		if assert.True(t, errors.As(err, &cfe)) {
+			assert.Equal(t, 0, cfe.Errno)
+		}
+	})
+	t.Run("canceled", func(t *testing.T) {
+		f := &fakeClone{states: []CloneState{ClonePending, CloneCanceled}}
+		_, err := waitForClone(ctx, f.status, f.cancel, fastWait)
+		assert.Equal(t, ErrCloneCanceled, err)
+		assert.False(t, f.canceled)
+	})
+	t.Run("statusError", func(t *testing.T) {
+		_, err := waitForClone(
+			ctx,
+			func() (*CloneStatus, error) { return nil, errors.New("bloop") },
+			func() error { return nil },
+			fastWait)
+		assert.Error(t, err)
+		assert.Equal(t, "bloop", err.Error())
+	})
+}
+
+func TestWaitForCloneContext(t *testing.T) {
+	f := &fakeClone{states: []CloneState{CloneInProgress}}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := waitForClone(ctx, f.status, f.cancel, fastWait)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.True(t, f.canceled)
+	assert.Greater(t, f.calls, 1)
+}
+
+func TestWaitForCloneProgress(t *testing.T) {
+	f := &fakeClone{states: []CloneState{
+		ClonePending, CloneInProgress, CloneComplete}}
+	ch := make(chan CloneStatus)
+	o := &WaitForCloneOptions{
+		MinInterval: time.Millisecond,
+		Progress:    ch,
+	}
+	var states []CloneState
+	done := make(chan struct{})
+	go func() {
+		for s := range ch {
+			states = append(states, s.State)
+		}
+		close(done)
+	}()
+	_, err := waitForClone(context.Background(), f.status, f.cancel, o)
+	assert.NoError(t, err)
+	<-done
+	assert.Equal(t,
+		[]CloneState{ClonePending, CloneInProgress, CloneComplete},
+		states)
+}
+
+func TestWaitForClone(t *testing.T) {
+	fsa := getFSAdmin(t)
+	volume := "cephfs"
+	group := "waitGroup"
+	subname := "waitSrc"
+	snapname := "waitSnap"
+	clonename := "waitClone"
+
+	err := fsa.CreateSubVolumeGroup(volume, group, nil)
+	assert.NoError(t, err)
+	defer func() {
+		err := fsa.RemoveSubVolumeGroup(volume, group)
+		assert.NoError(t, err)
+	}()
+
+	err = fsa.CreateSubVolume(volume, group, subname, nil)
+	assert.NoError(t, err)
+	defer func() {
+		err := fsa.RemoveSubVolume(volume, group, subname)
+		assert.NoError(t, err)
+	}()
+
+	err = fsa.CreateSubVolumeSnapshot(volume, group, subname, snapname)
+	assert.NoError(t, err)
+	defer func() {
+		err := fsa.RemoveSubVolumeSnapshot(volume, group, subname, snapname)
+		assert.NoError(t, err)
+	}()
+
+	err = fsa.CloneSubVolumeSnapshot(
+		volume, group, subname, snapname, clonename,
+		&CloneOptions{TargetGroup: group})
+	var x NotProtectedError
+	if errors.As(err, &x) {
+		err = fsa.ProtectSubVolumeSnapshot(volume, group, subname, snapname)
+		assert.NoError(t, err)
+		defer func() {
+			err := fsa.UnprotectSubVolumeSnapshot(volume, group, subname, snapname)
+			assert.NoError(t, err)
+		}()
+
+		err = fsa.CloneSubVolumeSnapshot(
+			volume, group, subname, snapname, clonename,
+			&CloneOptions{TargetGroup: group})
+	}
+	assert.NoError(t, err)
+	defer func() {
+		err := fsa.RemoveSubVolume(volume, group, clonename)
+		assert.NoError(t, err)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	status, err := fsa.WaitForClone(ctx, volume, group, clonename, &WaitForCloneOptions{
+		MinInterval: 5 * time.Millisecond,
+	})
+	assert.NoError(t, err)
+	if assert.NotNil(t, status) {
+		assert.Equal(t, CloneComplete, status.State)
+	}
+}
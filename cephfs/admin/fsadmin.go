@@ -6,15 +6,27 @@ import (
 	"encoding/json"
 	"strconv"
 
+	"github.com/ceph/go-ceph/internal/commands"
 	"github.com/ceph/go-ceph/rados"
 )
 
 // RadosCommander provides an interface to execute JSON-formatted commands that
 // allow the cephfs administrative functions to interact with the Ceph cluster.
-type RadosCommander interface {
-	MgrCommand(buf [][]byte) ([]byte, string, error)
-	MonCommand(buf []byte) ([]byte, string, error)
-}
+type RadosCommander = commands.RadosCommander
+
+var (
+	// ErrStatusNotEmpty may be returned if a call should not have a status
+	// string set but one is.
+	ErrStatusNotEmpty = commands.ErrStatusNotEmpty
+	// ErrBodyNotEmpty may be returned if a call should have an empty body but
+	// a body value is present.
+	ErrBodyNotEmpty = commands.ErrBodyNotEmpty
+)
+
+// NotImplementedError error values will be returned in the case that an API
+// call is not available in the version of Ceph that is running in the target
+// cluster.
+type NotImplementedError = commands.NotImplementedError
 
 // FSAdmin is used to administrate CephFS within a ceph cluster.
 type FSAdmin struct {
@@ -59,38 +71,38 @@ func (fsa *FSAdmin) validate() error {
 
 // rawMgrCommand takes a byte buffer and sends it to the MGR as a command.
 // The buffer is expected to contain preformatted JSON.
-func (fsa *FSAdmin) rawMgrCommand(buf []byte) response {
+func (fsa *FSAdmin) rawMgrCommand(buf []byte) commands.Response {
 	if err := fsa.validate(); err != nil {
-		return response{err: err}
+		return commands.NewResponse(nil, "", err)
 	}
-	return newResponse(fsa.conn.MgrCommand([][]byte{buf}))
+	return commands.NewResponse(fsa.conn.MgrCommand([][]byte{buf}))
 }
 
 // marshalMgrCommand takes an generic interface{} value, converts it to JSON and
 // sends the json to the MGR as a command.
-func (fsa *FSAdmin) marshalMgrCommand(v interface{}) response {
+func (fsa *FSAdmin) marshalMgrCommand(v interface{}) commands.Response {
 	b, err := json.Marshal(v)
 	if err != nil {
-		return response{err: err}
+		return commands.NewResponse(nil, "", err)
 	}
 	return fsa.rawMgrCommand(b)
 }
 
 // rawMonCommand takes a byte buffer and sends it to the MON as a command.
 // The buffer is expected to contain preformatted JSON.
-func (fsa *FSAdmin) rawMonCommand(buf []byte) response {
+func (fsa *FSAdmin) rawMonCommand(buf []byte) commands.Response {
 	if err := fsa.validate(); err != nil {
-		return response{err: err}
+		return commands.NewResponse(nil, "", err)
 	}
-	return newResponse(fsa.conn.MonCommand(buf))
+	return commands.NewResponse(fsa.conn.MonCommand(buf))
 }
 
 // marshalMonCommand takes an generic interface{} value, converts it to JSON and
 // sends the json to the MGR as a command.
-func (fsa *FSAdmin) marshalMonCommand(v interface{}) response {
+func (fsa *FSAdmin) marshalMonCommand(v interface{}) commands.Response {
 	b, err := json.Marshal(v)
 	if err != nil {
-		return response{err: err}
+		return commands.NewResponse(nil, "", err)
 	}
 	return fsa.rawMonCommand(b)
 }
@@ -99,9 +111,9 @@ type listNamedResult struct {
 	Name string `json:"name"`
 }
 
-func parseListNames(res response) ([]string, error) {
+func parseListNames(res commands.Response) ([]string, error) {
 	var r []listNamedResult
-	if err := res.noStatus().unmarshal(&r).End(); err != nil {
+	if err := res.NoStatus().Unmarshal(&r).End(); err != nil {
 		return nil, err
 	}
 	vl := make([]string, len(r))
@@ -113,11 +125,11 @@ func parseListNames(res response) ([]string, error) {
 
 // parsePathResponse returns a cleaned up path from requests that get a path
 // unless an error is encountered, then an error is returned.
-func parsePathResponse(res response) (string, error) {
-	if res2 := res.noStatus(); !res2.Ok() {
+func parsePathResponse(res commands.Response) (string, error) {
+	if res2 := res.NoStatus(); !res2.Ok() {
 		return "", res.End()
 	}
-	b := res.body
+	b := res.Body()
 	// if there's a trailing newline in the buffer strip it.
 	// ceph assumes a CLI wants the output of the buffer and there's
 	// no format=json mode available currently.
@@ -10,6 +10,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ceph/go-ceph/internal/commands"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -96,6 +98,33 @@ func (t *tracingCommander) MonCommand(buf []byte) ([]byte, string, error) {
 	return r, s, err
 }
 
+// recordingCommander keeps the JSON of the last command sent to it and
+// returns a canned reply. It allows testing the exact commands sent to ceph
+// without a cluster, or for commands that would be destructive to run.
+type recordingCommander struct {
+	mdsSpec string
+	cmd     string
+	body    []byte
+	status  string
+	err     error
+}
+
+func (r *recordingCommander) MgrCommand(buf [][]byte) ([]byte, string, error) {
+	r.cmd = string(buf[0])
+	return r.body, r.status, r.err
+}
+
+func (r *recordingCommander) MonCommand(buf []byte) ([]byte, string, error) {
+	r.cmd = string(buf)
+	return r.body, r.status, r.err
+}
+
+func (r *recordingCommander) MdsCommand(mdsSpec string, args [][]byte) ([]byte, string, error) {
+	r.mdsSpec = mdsSpec
+	r.cmd = string(args[0])
+	return r.body, r.status, r.err
+}
+
 func getFSAdmin(t *testing.T) *FSAdmin {
 	if cachedFSAdmin != nil {
 		return cachedFSAdmin
@@ -137,7 +166,7 @@ func TestBadMarshal(t *testing.T) {
 }
 
 func TestParseListNames(t *testing.T) {
-	R := newResponse
+	R := commands.NewResponse
 	t.Run("error", func(t *testing.T) {
 		_, err := parseListNames(R(nil, "", errors.New("bonk")))
 		assert.Error(t, err)
@@ -161,22 +190,22 @@ func TestParseListNames(t *testing.T) {
 }
 
 func TestCheckEmptyResponseExpected(t *testing.T) {
-	R := newResponse
+	R := commands.NewResponse
 	t.Run("error", func(t *testing.T) {
-		err := R(nil, "", errors.New("bonk")).noData().End()
+		err := R(nil, "", errors.New("bonk")).NoData().End()
 		assert.Error(t, err)
 		assert.Equal(t, "bonk", err.Error())
 	})
 	t.Run("statusSet", func(t *testing.T) {
-		err := R(nil, "unexpected!", nil).noData().End()
+		err := R(nil, "unexpected!", nil).NoData().End()
 		assert.Error(t, err)
 	})
 	t.Run("someJSON", func(t *testing.T) {
-		err := R([]byte(`{"trouble": true}`), "", nil).noData().End()
+		err := R([]byte(`{"trouble": true}`), "", nil).NoData().End()
 		assert.Error(t, err)
 	})
 	t.Run("ok", func(t *testing.T) {
-		err := R([]byte{}, "", nil).noData().End()
+		err := R([]byte{}, "", nil).NoData().End()
 		assert.NoError(t, err)
 	})
 }
@@ -0,0 +1,124 @@
+// +build !luminous,!mimic
+
+package admin
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/ceph/go-ceph/internal/commands"
+	"github.com/ceph/go-ceph/rados"
+)
+
+// FailMDS marks an MDS daemon as failed, a standby daemon takes over its
+// rank if one is available. The MDS may be given as a role (e.g. "cephfs:0"),
+// a daemon name or a GID.
+//
+// Similar To:
+//  ceph mds fail <role_or_gid>
+func (fsa *FSAdmin) FailMDS(roleOrGID string) error {
+	m := map[string]string{
+		"prefix":      "mds fail",
+		"role_or_gid": roleOrGID,
+		"format":      "json",
+	}
+	// ceph may report on the failed daemon in the status string
+	return fsa.marshalMonCommand(m).NoBody().End()
+}
+
+// RepairedMDS marks a damaged MDS rank as repaired, so that a daemon may be
+// started for it again. The rank is given as a role (e.g. "cephfs:0").
+//
+// Similar To:
+//  ceph mds repaired <role>
+func (fsa *FSAdmin) RepairedMDS(role string) error {
+	m := map[string]string{
+		"prefix": "mds repaired",
+		"role":   role,
+		"format": "json",
+	}
+	return fsa.marshalMonCommand(m).NoBody().End()
+}
+
+// MdsCommander provides an interface to execute JSON-formatted commands that
+// are handled by the MDS daemons directly. The cephfs.MountInfo type
+// implements this interface.
+type MdsCommander interface {
+	MdsCommand(mdsSpec string, args [][]byte) ([]byte, string, error)
+}
+
+// marshalMdsCommand takes an generic interface{} value, converts it to JSON
+// and sends the json to the MDS daemons matching mdsSpec as a command.
+func marshalMdsCommand(mc MdsCommander, mdsSpec string, v interface{}) commands.Response {
+	if mc == nil {
+		return commands.NewResponse(nil, "", rados.ErrNotConnected)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return commands.NewResponse(nil, "", err)
+	}
+	return commands.NewResponse(mc.MdsCommand(mdsSpec, [][]byte{b}))
+}
+
+// ClientMetadata contains the values a client reports about itself when it
+// opens a session with an MDS.
+type ClientMetadata struct {
+	EntityID      string `json:"entity_id"`
+	Hostname      string `json:"hostname"`
+	Root          string `json:"root"`
+	MountPoint    string `json:"mount_point"`
+	Pid           string `json:"pid"`
+	CephVersion   string `json:"ceph_version"`
+	KernelVersion string `json:"kernel_version"`
+}
+
+// ClientSession reports the state of the session of a client with an MDS.
+type ClientSession struct {
+	ID                int64          `json:"id"`
+	Inst              string         `json:"inst"`
+	State             string         `json:"state"`
+	NumLeases         int            `json:"num_leases"`
+	NumCaps           int            `json:"num_caps"`
+	RequestLoadAvg    int            `json:"request_load_avg"`
+	Uptime            float64        `json:"uptime"`
+	RequestsInFlight  int            `json:"requests_in_flight"`
+	CompletedRequests int            `json:"completed_requests"`
+	Reconnecting      bool           `json:"reconnecting"`
+	ClientMetadata    ClientMetadata `json:"client_metadata"`
+}
+
+func parseClientSessions(res commands.Response) ([]ClientSession, error) {
+	var sessions []ClientSession
+	if err := res.NoStatus().Unmarshal(&sessions).End(); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// ListClientSessions returns the client sessions open on the MDS daemons
+// matching mdsSpec (e.g. "0" for rank 0, or a daemon name).
+//
+// Similar To:
+//  ceph tell mds.<mdsSpec> session ls
+func ListClientSessions(mc MdsCommander, mdsSpec string) ([]ClientSession, error) {
+	m := map[string]string{
+		"prefix": "session ls",
+		"format": "json",
+	}
+	return parseClientSessions(marshalMdsCommand(mc, mdsSpec, m))
+}
+
+// EvictClientSession evicts the client with the given session ID from the
+// MDS daemons matching mdsSpec. The client is blocklisted by default, see the
+// mds_session_blocklist_on_evict option.
+//
+// Similar To:
+//  ceph tell mds.<mdsSpec> client evict id=<id>
+func EvictClientSession(mc MdsCommander, mdsSpec string, id int64) error {
+	m := map[string]interface{}{
+		"prefix":  "client evict",
+		"filters": []string{"id=" + strconv.FormatInt(id, 10)},
+		"format":  "json",
+	}
+	return marshalMdsCommand(mc, mdsSpec, m).NoStatus().End()
+}
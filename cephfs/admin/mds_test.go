@@ -0,0 +1,162 @@
+// +build !luminous,!mimic
+
+package admin
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ceph/go-ceph/cephfs"
+	"github.com/ceph/go-ceph/internal/commands"
+	"github.com/ceph/go-ceph/rados"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var _ MdsCommander = &cephfs.MountInfo{}
+
+var sampleSessionList1 = []byte(`
+[
+    {
+        "id": 4305,
+        "entity": {
+            "name": {
+                "type": "client",
+                "num": 4305
+            },
+            "addr": {
+                "type": "v1",
+                "addr": "127.0.0.1:0",
+                "nonce": 2849413397
+            }
+        },
+        "state": "open",
+        "num_leases": 0,
+        "num_caps": 1,
+        "request_load_avg": 2,
+        "uptime": 25.418463917,
+        "requests_in_flight": 0,
+        "completed_requests": 0,
+        "reconnecting": false,
+        "recall_caps": {
+            "value": 0,
+            "halflife": 60
+        },
+        "inst": "client.4305 v1:127.0.0.1:0/2849413397",
+        "client_metadata": {
+            "client_features": {
+                "feature_bits": "0x0000000000003bff"
+            },
+            "metric_spec": {
+                "metric_flags": {
+                    "feature_bits": "0x"
+                }
+            },
+            "ceph_sha1": "no_version",
+            "ceph_version": "ceph version Development (no_version) pacific (rc)",
+            "entity_id": "admin",
+            "hostname": "buildbox",
+            "pid": "2048",
+            "root": "/"
+        }
+    }
+]
+`)
+
+func TestParseClientSessions(t *testing.T) {
+	R := commands.NewResponse
+	t.Run("error", func(t *testing.T) {
+		_, err := parseClientSessions(R(nil, "", errors.New("boop")))
+		assert.Error(t, err)
+		assert.Equal(t, "boop", err.Error())
+	})
+	t.Run("statusSet", func(t *testing.T) {
+		_, err := parseClientSessions(R(nil, "unexpected!", nil))
+		assert.Error(t, err)
+	})
+	t.Run("empty", func(t *testing.T) {
+		sessions, err := parseClientSessions(R([]byte("[]"), "", nil))
+		assert.NoError(t, err)
+		assert.Len(t, sessions, 0)
+	})
+	t.Run("ok", func(t *testing.T) {
+		sessions, err := parseClientSessions(R(sampleSessionList1, "", nil))
+		assert.NoError(t, err)
+		if assert.Len(t, sessions, 1) {
+			s := sessions[0]
+			assert.EqualValues(t, 4305, s.ID)
+			assert.Equal(t, "open", s.State)
+			assert.Equal(t, 1, s.NumCaps)
+			assert.Equal(t, 2, s.RequestLoadAvg)
+			assert.False(t, s.Reconnecting)
+			assert.Equal(t, "client.4305 v1:127.0.0.1:0/2849413397", s.Inst)
+			assert.Equal(t, "admin", s.ClientMetadata.EntityID)
+			assert.Equal(t, "buildbox", s.ClientMetadata.Hostname)
+			assert.Equal(t, "/", s.ClientMetadata.Root)
+			assert.Equal(t, "2048", s.ClientMetadata.Pid)
+		}
+	})
+}
+
+func TestMdsCommanderNotConnected(t *testing.T) {
+	res := marshalMdsCommand(nil, "0", map[string]string{"prefix": "session ls"})
+	assert.Equal(t, rados.ErrNotConnected, res.Unwrap())
+
+	_, err := ListClientSessions(nil, "0")
+	assert.Error(t, err)
+	err = EvictClientSession(nil, "0", 1)
+	assert.Error(t, err)
+}
+
+func TestListClientSessions(t *testing.T) {
+	mount := fsConnect(t)
+	defer func() {
+		assert.NoError(t, mount.Unmount())
+		assert.NoError(t, mount.Release())
+	}()
+
+	sessions, err := ListClientSessions(mount, "0")
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, len(sessions), 1)
+	for _, s := range sessions {
+		assert.NotEqual(t, int64(0), s.ID)
+		assert.NotEqual(t, "", s.State)
+	}
+}
+
+func TestMDSCommands(t *testing.T) {
+	rc := &recordingCommander{}
+	fsa := NewFromConn(rc)
+
+	// ceph reports on the failed daemon in the status
+	rc.status = "failed mds gid 4107"
+	err := fsa.FailMDS("cephfs:0")
+	assert.NoError(t, err)
+	assert.JSONEq(t,
+		`{"prefix": "mds fail", "role_or_gid": "cephfs:0", "format": "json"}`,
+		rc.cmd)
+
+	rc.status = ""
+	err = fsa.RepairedMDS("cephfs:1")
+	assert.NoError(t, err)
+	assert.JSONEq(t,
+		`{"prefix": "mds repaired", "role": "cephfs:1", "format": "json"}`,
+		rc.cmd)
+
+	rc.err = errors.New("EINVAL")
+	assert.Error(t, fsa.FailMDS("bogus"))
+	assert.Error(t, fsa.RepairedMDS("bogus"))
+}
+
+func TestEvictClientSessionCommand(t *testing.T) {
+	rc := &recordingCommander{}
+	err := EvictClientSession(rc, "cephfs:0", 4305)
+	assert.NoError(t, err)
+	assert.Equal(t, "cephfs:0", rc.mdsSpec)
+	assert.JSONEq(t,
+		`{"prefix": "client evict", "filters": ["id=4305"], "format": "json"}`,
+		rc.cmd)
+
+	rc.status = "unexpected"
+	assert.Error(t, EvictClientSession(rc, "cephfs:0", 4305))
+}
@@ -0,0 +1,105 @@
+// +build !luminous,!mimic,!nautilus,!octopus
+
+package admin
+
+import (
+	"github.com/ceph/go-ceph/internal/commands"
+)
+
+// SetSubVolumeMetadata sets a custom key-value pair on the subvolume. An
+// existing value for the key is replaced.
+//
+// Similar To:
+//  ceph fs subvolume metadata set <volume> <subvolume> <key> <value> --group-name=<group>
+func (fsa *FSAdmin) SetSubVolumeMetadata(volume, group, subvolume, key, value string) error {
+	m := map[string]string{
+		"prefix":   "fs subvolume metadata set",
+		"vol_name": volume,
+		"sub_name": subvolume,
+		"key_name": key,
+		"value":    value,
+		"format":   "json",
+	}
+	if group != NoGroup {
+		m["group_name"] = group
+	}
+	return fsa.marshalMgrCommand(m).NoData().End()
+}
+
+// GetSubVolumeMetadata returns the value of the custom metadata key stored
+// on the subvolume.
+//
+// Similar To:
+//  ceph fs subvolume metadata get <volume> <subvolume> <key> --group-name=<group>
+func (fsa *FSAdmin) GetSubVolumeMetadata(volume, group, subvolume, key string) (string, error) {
+	m := map[string]string{
+		"prefix":   "fs subvolume metadata get",
+		"vol_name": volume,
+		"sub_name": subvolume,
+		"key_name": key,
+		"format":   "json",
+	}
+	if group != NoGroup {
+		m["group_name"] = group
+	}
+	// like getpath, ceph replies with the plain value
+	return parsePathResponse(fsa.marshalMgrCommand(m))
+}
+
+func parseMetadataMap(res commands.Response) (map[string]string, error) {
+	var mm map[string]string
+	if err := res.NoStatus().Unmarshal(&mm).End(); err != nil {
+		return nil, err
+	}
+	return mm, nil
+}
+
+// ListSubVolumeMetadata returns all the custom metadata key-value pairs
+// stored on the subvolume.
+//
+// Similar To:
+//  ceph fs subvolume metadata ls <volume> <subvolume> --group-name=<group>
+func (fsa *FSAdmin) ListSubVolumeMetadata(volume, group, subvolume string) (map[string]string, error) {
+	m := map[string]string{
+		"prefix":   "fs subvolume metadata ls",
+		"vol_name": volume,
+		"sub_name": subvolume,
+		"format":   "json",
+	}
+	if group != NoGroup {
+		m["group_name"] = group
+	}
+	return parseMetadataMap(fsa.marshalMgrCommand(m))
+}
+
+// RemoveSubVolumeMetadata removes the custom metadata key from the
+// subvolume.
+//
+// Similar To:
+//  ceph fs subvolume metadata rm <volume> <subvolume> <key> --group-name=<group>
+func (fsa *FSAdmin) RemoveSubVolumeMetadata(volume, group, subvolume, key string) error {
+	return fsa.rmSubVolumeMetadata(volume, group, subvolume, key, commonRmFlags{})
+}
+
+// ForceRemoveSubVolumeMetadata removes the custom metadata key from the
+// subvolume. No error is returned if the key does not exist.
+//
+// Similar To:
+//  ceph fs subvolume metadata rm <volume> <subvolume> <key> --group-name=<group> --force
+func (fsa *FSAdmin) ForceRemoveSubVolumeMetadata(volume, group, subvolume, key string) error {
+	return fsa.rmSubVolumeMetadata(volume, group, subvolume, key, commonRmFlags{force: true})
+}
+
+func (fsa *FSAdmin) rmSubVolumeMetadata(volume, group, subvolume, key string, o commonRmFlags) error {
+	m := map[string]string{
+		"prefix":   "fs subvolume metadata rm",
+		"vol_name": volume,
+		"sub_name": subvolume,
+		"key_name": key,
+		"format":   "json",
+	}
+	if group != NoGroup {
+		m["group_name"] = group
+	}
+	return fsa.marshalMgrCommand(mergeFlags(m, o)).NoData().End()
+}
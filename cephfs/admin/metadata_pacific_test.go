@@ -0,0 +1,84 @@
+// +build !luminous,!mimic,!nautilus,!octopus
+
+package admin
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ceph/go-ceph/internal/commands"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMetadataMap(t *testing.T) {
+	R := commands.NewResponse
+	t.Run("error", func(t *testing.T) {
+		_, err := parseMetadataMap(R(nil, "", errors.New("flub")))
+		assert.Error(t, err)
+		assert.Equal(t, "flub", err.Error())
+	})
+	t.Run("statusSet", func(t *testing.T) {
+		_, err := parseMetadataMap(R(nil, "unexpected!", nil))
+		assert.Error(t, err)
+	})
+	t.Run("badJSON", func(t *testing.T) {
+		_, err := parseMetadataMap(R([]byte("[1]"), "", nil))
+		assert.Error(t, err)
+	})
+	t.Run("ok", func(t *testing.T) {
+		mm, err := parseMetadataMap(R([]byte(`{"color": "blue", "shape": "round"}`), "", nil))
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"color": "blue", "shape": "round"}, mm)
+	})
+}
+
+func TestSubVolumeMetadata(t *testing.T) {
+	if serverIsNautilus || serverIsOctopus {
+		t.Skipf("can only execute on pacific and later servers")
+	}
+	fsa := getFSAdmin(t)
+	volume := "cephfs"
+	group := "metaGroup"
+	subname := "meta1"
+
+	err := fsa.CreateSubVolumeGroup(volume, group, nil)
+	assert.NoError(t, err)
+	defer func() {
+		err := fsa.RemoveSubVolumeGroup(volume, group)
+		assert.NoError(t, err)
+	}()
+	err = fsa.CreateSubVolume(volume, group, subname, nil)
+	assert.NoError(t, err)
+	defer func() {
+		err := fsa.RemoveSubVolume(volume, group, subname)
+		assert.NoError(t, err)
+	}()
+
+	err = fsa.SetSubVolumeMetadata(volume, group, subname, "color", "blue")
+	assert.NoError(t, err)
+	err = fsa.SetSubVolumeMetadata(volume, group, subname, "shape", "round")
+	assert.NoError(t, err)
+
+	v, err := fsa.GetSubVolumeMetadata(volume, group, subname, "color")
+	assert.NoError(t, err)
+	assert.Equal(t, "blue", v)
+
+	mm, err := fsa.ListSubVolumeMetadata(volume, group, subname)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"color": "blue", "shape": "round"}, mm)
+
+	err = fsa.RemoveSubVolumeMetadata(volume, group, subname, "color")
+	assert.NoError(t, err)
+	_, err = fsa.GetSubVolumeMetadata(volume, group, subname, "color")
+	assert.Error(t, err)
+
+	err = fsa.RemoveSubVolumeMetadata(volume, group, subname, "color")
+	assert.Error(t, err)
+	err = fsa.ForceRemoveSubVolumeMetadata(volume, group, subname, "color")
+	assert.NoError(t, err)
+
+	mm, err = fsa.ListSubVolumeMetadata(volume, group, subname)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"shape": "round"}, mm)
+}
@@ -0,0 +1,38 @@
+// +build !luminous,!mimic,!nautilus
+
+package admin
+
+// PinType is used to select how a subvolume or subvolume group is pinned to
+// the MDS ranks of the file system.
+type PinType string
+
+const (
+	// ExportPin pins the directory to the MDS rank given by the pin setting.
+	ExportPin = PinType("export")
+	// DistributedPin spreads the immediate children of the directory over
+	// all MDS ranks when the pin setting is "1".
+	DistributedPin = PinType("distributed")
+	// RandomPin pins descendant directories to random MDS ranks, with the
+	// probability given by the pin setting.
+	RandomPin = PinType("random")
+)
+
+// PinSubVolume pins a subvolume to the MDS ranks of the file system. The
+// meaning of the setting depends on the pin type, see the PinType values.
+//
+// Similar To:
+//  ceph fs subvolume pin <volume> <subvolume> <pin_type> <pin_setting> --group-name=<group>
+func (fsa *FSAdmin) PinSubVolume(volume, group, subvolume string, pinType PinType, setting string) error {
+	m := map[string]string{
+		"prefix":      "fs subvolume pin",
+		"vol_name":    volume,
+		"sub_name":    subvolume,
+		"pin_type":    string(pinType),
+		"pin_setting": setting,
+		"format":      "json",
+	}
+	if group != NoGroup {
+		m["group_name"] = group
+	}
+	return fsa.marshalMgrCommand(m).NoStatus().End()
+}
@@ -0,0 +1,33 @@
+// +build !luminous,!mimic,!nautilus
+
+package admin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPinSubVolume(t *testing.T) {
+	if serverIsNautilus {
+		t.Skipf("can only execute on octopus and later servers")
+	}
+	fsa := getFSAdmin(t)
+	volume := "cephfs"
+	subname := "pinMe1"
+
+	err := fsa.CreateSubVolume(volume, NoGroup, subname, nil)
+	assert.NoError(t, err)
+	defer func() {
+		err := fsa.RemoveSubVolume(volume, NoGroup, subname)
+		assert.NoError(t, err)
+	}()
+
+	err = fsa.PinSubVolume(volume, NoGroup, subname, ExportPin, "0")
+	assert.NoError(t, err)
+	err = fsa.PinSubVolume(volume, NoGroup, subname, RandomPin, "0.01")
+	assert.NoError(t, err)
+
+	err = fsa.PinSubVolume(volume, NoGroup, "oops", ExportPin, "0")
+	assert.Error(t, err)
+}
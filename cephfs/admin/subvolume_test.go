@@ -7,6 +7,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ceph/go-ceph/internal/commands"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -316,7 +318,7 @@ var badSampleSubVolumeInfo2 = []byte(`
 `)
 
 func TestParseSubVolumeInfo(t *testing.T) {
-	R := newResponse
+	R := commands.NewResponse
 	t.Run("error", func(t *testing.T) {
 		_, err := parseSubVolumeInfo(R(nil, "", errors.New("gleep glop")))
 		assert.Error(t, err)
@@ -502,7 +504,7 @@ var sampleSubVolumeSnapshoInfo1 = []byte(`
 `)
 
 func TestParseSubVolumeSnapshotInfo(t *testing.T) {
-	R := newResponse
+	R := commands.NewResponse
 	t.Run("error", func(t *testing.T) {
 		_, err := parseSubVolumeSnapshotInfo(R(nil, "", errors.New("flub")))
 		assert.Error(t, err)
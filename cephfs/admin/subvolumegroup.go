@@ -2,6 +2,20 @@
 
 package admin
 
+import (
+	"path"
+
+	"github.com/ceph/go-ceph/internal/commands"
+)
+
+// snapDirName is the default name of the virtual directory that exposes
+// CephFS snapshots.
+const snapDirName = ".snap"
+
+// enosys is the error code returned by the mgr volumes module for commands
+// it still accepts but no longer supports.
+const enosys = -38
+
 // this is the internal type used to create JSON for ceph.
 // See SubVolumeGroupOptions for the type that users of the library
 // interact with.
@@ -48,7 +62,7 @@ func (fsa *FSAdmin) CreateSubVolumeGroup(volume, name string, o *SubVolumeGroupO
 		o = &SubVolumeGroupOptions{}
 	}
 	res := fsa.marshalMgrCommand(o.toFields(volume, name))
-	return res.noData().End()
+	return res.NoData().End()
 }
 
 // ListSubVolumeGroups returns a list of subvolume groups belonging to the
@@ -86,7 +100,7 @@ func (fsa *FSAdmin) rmSubVolumeGroup(volume, name string, o commonRmFlags) error
 		"group_name": name,
 		"format":     "json",
 	}, o))
-	return res.noData().End()
+	return res.NoData().End()
 }
 
 // SubVolumeGroupPath returns the path to the subvolume from the root of the
@@ -103,3 +117,92 @@ func (fsa *FSAdmin) SubVolumeGroupPath(volume, name string) (string, error) {
 	}
 	return parsePathResponse(fsa.marshalMgrCommand(m))
 }
+
+// CreateSubVolumeGroupSnapshot creates a snapshot of all the subvolumes of a
+// subvolume group.
+//
+// The mgr volumes module disables subvolume group snapshots in pacific and
+// in later octopus point releases. Those servers reply with ENOSYS, "subvolume
+// group snapshots are not supported", which is returned as a
+// NotImplementedError.
+//
+// Similar To:
+//  ceph fs subvolumegroup snapshot create <volume> <group_name> <name>
+func (fsa *FSAdmin) CreateSubVolumeGroupSnapshot(volume, group, name string) error {
+	m := map[string]string{
+		"prefix":     "fs subvolumegroup snapshot create",
+		"vol_name":   volume,
+		"group_name": group,
+		"snap_name":  name,
+		"format":     "json",
+	}
+	return parseSubVolumeGroupSnapshotCreate(fsa.marshalMgrCommand(m))
+}
+
+func parseSubVolumeGroupSnapshotCreate(res commands.Response) error {
+	if ec, ok := res.Unwrap().(interface{ ErrorCode() int }); ok {
+		if ec.ErrorCode() == enosys {
+			return NotImplementedError{Response: res}
+		}
+	}
+	return res.NoData().End()
+}
+
+// RemoveSubVolumeGroupSnapshot removes the specified snapshot from the
+// subvolume group.
+//
+// Similar To:
+//  ceph fs subvolumegroup snapshot rm <volume> <group_name> <name>
+func (fsa *FSAdmin) RemoveSubVolumeGroupSnapshot(volume, group, name string) error {
+	return fsa.rmSubVolumeGroupSnapshot(volume, group, name, commonRmFlags{})
+}
+
+// ForceRemoveSubVolumeGroupSnapshot removes the specified snapshot from the
+// subvolume group.
+//
+// Similar To:
+//  ceph fs subvolumegroup snapshot rm <volume> <group_name> <name> --force
+func (fsa *FSAdmin) ForceRemoveSubVolumeGroupSnapshot(volume, group, name string) error {
+	return fsa.rmSubVolumeGroupSnapshot(volume, group, name, commonRmFlags{force: true})
+}
+
+func (fsa *FSAdmin) rmSubVolumeGroupSnapshot(volume, group, name string, o commonRmFlags) error {
+	m := map[string]string{
+		"prefix":     "fs subvolumegroup snapshot rm",
+		"vol_name":   volume,
+		"group_name": group,
+		"snap_name":  name,
+		"format":     "json",
+	}
+	return fsa.marshalMgrCommand(mergeFlags(m, o)).NoData().End()
+}
+
+// ListSubVolumeGroupSnapshots returns a listing of snapshots for a given
+// subvolume group.
+//
+// Similar To:
+//  ceph fs subvolumegroup snapshot ls <volume> <group_name>
+func (fsa *FSAdmin) ListSubVolumeGroupSnapshots(volume, group string) ([]string, error) {
+	m := map[string]string{
+		"prefix":     "fs subvolumegroup snapshot ls",
+		"vol_name":   volume,
+		"group_name": group,
+		"format":     "json",
+	}
+	return parseListNames(fsa.marshalMgrCommand(m))
+}
+
+// SubVolumeGroupSnapshotPath returns the path to a snapshot of the subvolume
+// group from the root of the file system. Ceph has no command for this, the
+// path is built from the path of the group and the default snapshot
+// directory name.
+//
+// Similar To:
+//  ceph fs subvolumegroup getpath <volume> <group_name>
+func (fsa *FSAdmin) SubVolumeGroupSnapshotPath(volume, group, name string) (string, error) {
+	p, err := fsa.SubVolumeGroupPath(volume, group)
+	if err != nil {
+		return "", err
+	}
+	return path.Join(p, snapDirName, name), nil
+}
@@ -0,0 +1,137 @@
+// +build !luminous,!mimic,!nautilus,!octopus
+
+package admin
+
+import (
+	"encoding/json"
+
+	"github.com/ceph/go-ceph/internal/commands"
+)
+
+// SubVolumeGroupInfo reports various informational values about a subvolume
+// group.
+type SubVolumeGroupInfo struct {
+	Uid          int       `json:"uid"`
+	Gid          int       `json:"gid"`
+	Mode         int       `json:"mode"`
+	BytesPercent string    `json:"bytes_pcent"`
+	BytesUsed    ByteCount `json:"bytes_used"`
+	BytesQuota   QuotaSize `json:"-"`
+	DataPool     string    `json:"data_pool"`
+	MonAddrs     []string  `json:"mon_addrs"`
+	Atime        TimeStamp `json:"atime"`
+	Mtime        TimeStamp `json:"mtime"`
+	Ctime        TimeStamp `json:"ctime"`
+	CreatedAt    TimeStamp `json:"created_at"`
+}
+
+type subVolumeGroupInfoWrapper struct {
+	SubVolumeGroupInfo
+	VBytesQuota *quotaSizePlaceholder `json:"bytes_quota"`
+}
+
+func parseSubVolumeGroupInfo(res commands.Response) (*SubVolumeGroupInfo, error) {
+	var info subVolumeGroupInfoWrapper
+	if err := res.NoStatus().Unmarshal(&info).End(); err != nil {
+		return nil, err
+	}
+	if info.VBytesQuota != nil {
+		info.BytesQuota = info.VBytesQuota.Value
+	}
+	return &info.SubVolumeGroupInfo, nil
+}
+
+// SubVolumeGroupInfo returns information about the specified subvolume group.
+//
+// Similar To:
+//  ceph fs subvolumegroup info <volume> <group_name>
+func (fsa *FSAdmin) SubVolumeGroupInfo(volume, name string) (*SubVolumeGroupInfo, error) {
+	m := map[string]string{
+		"prefix":     "fs subvolumegroup info",
+		"vol_name":   volume,
+		"group_name": name,
+		"format":     "json",
+	}
+	return parseSubVolumeGroupInfo(fsa.marshalMgrCommand(m))
+}
+
+type subVolumeGroupResizeFields struct {
+	Prefix    string `json:"prefix"`
+	Format    string `json:"format"`
+	VolName   string `json:"vol_name"`
+	GroupName string `json:"group_name"`
+	NewSize   string `json:"new_size"`
+	NoShrink  bool   `json:"no_shrink"`
+}
+
+// SubVolumeGroupResizeResult reports the size values returned by the
+// ResizeSubVolumeGroup function, as reported by Ceph.
+type SubVolumeGroupResizeResult struct {
+	BytesUsed    ByteCount `json:"bytes_used"`
+	BytesQuota   QuotaSize `json:"-"`
+	BytesPercent string    `json:"bytes_pcent"`
+}
+
+type subVolumeGroupResizeWrapper struct {
+	SubVolumeGroupResizeResult
+	VBytesQuota *quotaSizePlaceholder `json:"bytes_quota"`
+}
+
+// parseSubVolumeGroupResizeResult merges the list of single valued objects
+// returned by ceph for the resize command into one result.
+func parseSubVolumeGroupResizeResult(res commands.Response) (*SubVolumeGroupResizeResult, error) {
+	var items []json.RawMessage
+	if err := res.NoStatus().Unmarshal(&items).End(); err != nil {
+		return nil, err
+	}
+	var result subVolumeGroupResizeWrapper
+	for _, item := range items {
+		if err := json.Unmarshal(item, &result); err != nil {
+			return nil, err
+		}
+	}
+	if result.VBytesQuota != nil {
+		result.BytesQuota = result.VBytesQuota.Value
+	}
+	return &result.SubVolumeGroupResizeResult, nil
+}
+
+// ResizeSubVolumeGroup will resize a CephFS subvolume group, the size limits
+// all the subvolumes of the group together. The newSize value may be a
+// ByteCount or the special Infinite constant. Setting noShrink to true will
+// prevent reducing the size of the group below the current used size.
+//
+// Similar To:
+//  ceph fs subvolumegroup resize <volume> <group_name> <new_size> [--no_shrink]
+func (fsa *FSAdmin) ResizeSubVolumeGroup(
+	volume, name string,
+	newSize QuotaSize, noShrink bool) (*SubVolumeGroupResizeResult, error) {
+
+	f := &subVolumeGroupResizeFields{
+		Prefix:    "fs subvolumegroup resize",
+		Format:    "json",
+		VolName:   volume,
+		GroupName: name,
+		NewSize:   newSize.resizeValue(),
+		NoShrink:  noShrink,
+	}
+	return parseSubVolumeGroupResizeResult(fsa.marshalMgrCommand(f))
+}
+
+// PinSubVolumeGroup pins a subvolume group to the MDS ranks of the file
+// system. The meaning of the setting depends on the pin type, see the
+// PinType values.
+//
+// Similar To:
+//  ceph fs subvolumegroup pin <volume> <group_name> <pin_type> <pin_setting>
+func (fsa *FSAdmin) PinSubVolumeGroup(volume, name string, pinType PinType, setting string) error {
+	m := map[string]string{
+		"prefix":      "fs subvolumegroup pin",
+		"vol_name":    volume,
+		"group_name":  name,
+		"pin_type":    string(pinType),
+		"pin_setting": setting,
+		"format":      "json",
+	}
+	return fsa.marshalMgrCommand(m).NoStatus().End()
+}
@@ -0,0 +1,232 @@
+// +build !luminous,!mimic,!nautilus,!octopus
+
+package admin
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ceph/go-ceph/internal/commands"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var sampleSubVolumeGroupInfo1 = []byte(`
+{
+    "atime": "2021-03-15 16:14:22",
+    "bytes_pcent": "undefined",
+    "bytes_quota": "infinite",
+    "bytes_used": 0,
+    "created_at": "2021-03-15 16:14:22",
+    "ctime": "2021-03-15 16:14:22",
+    "data_pool": "cephfs_data",
+    "gid": 0,
+    "mode": 16877,
+    "mon_addrs": [
+        "127.0.0.1:6789"
+    ],
+    "mtime": "2021-03-15 16:14:22",
+    "uid": 0
+}
+`)
+
+var sampleSubVolumeGroupInfo2 = []byte(`
+{
+    "atime": "2021-03-15 16:14:22",
+    "bytes_pcent": "0.00",
+    "bytes_quota": 10737418240,
+    "bytes_used": 1024,
+    "created_at": "2021-03-15 16:14:22",
+    "ctime": "2021-03-15 16:20:07",
+    "data_pool": "cephfs_data",
+    "gid": 200,
+    "mode": 16889,
+    "mon_addrs": [
+        "127.0.0.1:6789"
+    ],
+    "mtime": "2021-03-15 16:14:22",
+    "uid": 200
+}
+`)
+
+func TestParseSubVolumeGroupInfo(t *testing.T) {
+	R := commands.NewResponse
+	t.Run("error", func(t *testing.T) {
+		_, err := parseSubVolumeGroupInfo(R(nil, "", errors.New("gleep glop")))
+		assert.Error(t, err)
+		assert.Equal(t, "gleep glop", err.Error())
+	})
+	t.Run("statusSet", func(t *testing.T) {
+		_, err := parseSubVolumeGroupInfo(R(nil, "unexpected!", nil))
+		assert.Error(t, err)
+	})
+	t.Run("ok", func(t *testing.T) {
+		info, err := parseSubVolumeGroupInfo(R(sampleSubVolumeGroupInfo1, "", nil))
+		assert.NoError(t, err)
+		if assert.NotNil(t, info) {
+			assert.Equal(t, 0, info.Uid)
+			assert.Equal(t, Infinite, info.BytesQuota)
+			assert.Equal(t, 040755, info.Mode)
+			assert.Equal(t, "cephfs_data", info.DataPool)
+			assert.Equal(t, "2021-03-15 16:14:22", info.Ctime.String())
+		}
+	})
+	t.Run("ok2", func(t *testing.T) {
+		info, err := parseSubVolumeGroupInfo(R(sampleSubVolumeGroupInfo2, "", nil))
+		assert.NoError(t, err)
+		if assert.NotNil(t, info) {
+			assert.Equal(t, 200, info.Gid)
+			assert.Equal(t, ByteCount(10*gibiByte), info.BytesQuota)
+			assert.Equal(t, ByteCount(1024), info.BytesUsed)
+			assert.Equal(t, "0.00", info.BytesPercent)
+			assert.Equal(t, "2021-03-15 16:20:07", info.Ctime.String())
+		}
+	})
+}
+
+var sampleSubVolumeGroupResize1 = []byte(`
+[
+    {
+        "bytes_used": 1024
+    },
+    {
+        "bytes_quota": 21474836480
+    },
+    {
+        "bytes_pcent": "0.00"
+    }
+]
+`)
+
+var sampleSubVolumeGroupResize2 = []byte(`
+[
+    {
+        "bytes_used": 0
+    },
+    {
+        "bytes_quota": "infinite"
+    },
+    {
+        "bytes_pcent": "undefined"
+    }
+]
+`)
+
+func TestParseSubVolumeGroupResizeResult(t *testing.T) {
+	R := commands.NewResponse
+	t.Run("error", func(t *testing.T) {
+		_, err := parseSubVolumeGroupResizeResult(R(nil, "", errors.New("zork")))
+		assert.Error(t, err)
+		assert.Equal(t, "zork", err.Error())
+	})
+	t.Run("badJSON", func(t *testing.T) {
+		_, err := parseSubVolumeGroupResizeResult(R([]byte(`[{"bytes_used": "x"}]`), "", nil))
+		assert.Error(t, err)
+	})
+	t.Run("ok", func(t *testing.T) {
+		rr, err := parseSubVolumeGroupResizeResult(R(sampleSubVolumeGroupResize1, "", nil))
+		assert.NoError(t, err)
+		if assert.NotNil(t, rr) {
+			assert.Equal(t, ByteCount(1024), rr.BytesUsed)
+			assert.Equal(t, ByteCount(20*gibiByte), rr.BytesQuota)
+			assert.Equal(t, "0.00", rr.BytesPercent)
+		}
+	})
+	t.Run("infinite", func(t *testing.T) {
+		rr, err := parseSubVolumeGroupResizeResult(R(sampleSubVolumeGroupResize2, "", nil))
+		assert.NoError(t, err)
+		if assert.NotNil(t, rr) {
+			assert.Equal(t, ByteCount(0), rr.BytesUsed)
+			assert.Equal(t, Infinite, rr.BytesQuota)
+			assert.Equal(t, "undefined", rr.BytesPercent)
+		}
+	})
+}
+
+func TestSubVolumeGroupInfo(t *testing.T) {
+	if serverIsNautilus || serverIsOctopus {
+		t.Skipf("can only execute on pacific and later servers")
+	}
+	fsa := getFSAdmin(t)
+	volume := "cephfs"
+	group := "infoGroup"
+
+	err := fsa.CreateSubVolumeGroup(volume, group, &SubVolumeGroupOptions{
+		Uid:  200,
+		Gid:  200,
+		Mode: 0771,
+	})
+	assert.NoError(t, err)
+	defer func() {
+		err := fsa.RemoveSubVolumeGroup(volume, group)
+		assert.NoError(t, err)
+	}()
+
+	info, err := fsa.SubVolumeGroupInfo(volume, group)
+	assert.NoError(t, err)
+	if assert.NotNil(t, info) {
+		assert.Equal(t, 200, info.Uid)
+		assert.Equal(t, 200, info.Gid)
+		assert.Equal(t, 040771, info.Mode)
+		assert.Equal(t, Infinite, info.BytesQuota)
+	}
+
+	_, err = fsa.SubVolumeGroupInfo(volume, "oops")
+	assert.Error(t, err)
+}
+
+func TestResizeSubVolumeGroup(t *testing.T) {
+	if serverIsNautilus || serverIsOctopus {
+		t.Skipf("can only execute on pacific and later servers")
+	}
+	fsa := getFSAdmin(t)
+	volume := "cephfs"
+	group := "sizedSVG"
+
+	err := fsa.CreateSubVolumeGroup(volume, group, nil)
+	assert.NoError(t, err)
+	defer func() {
+		err := fsa.RemoveSubVolumeGroup(volume, group)
+		assert.NoError(t, err)
+	}()
+
+	rr, err := fsa.ResizeSubVolumeGroup(volume, group, 20*gibiByte, false)
+	assert.NoError(t, err)
+	if assert.NotNil(t, rr) {
+		assert.Equal(t, ByteCount(20*gibiByte), rr.BytesQuota)
+	}
+
+	rr, err = fsa.ResizeSubVolumeGroup(volume, group, 10*gibiByte, true)
+	assert.NoError(t, err)
+	assert.NotNil(t, rr)
+
+	rr, err = fsa.ResizeSubVolumeGroup(volume, group, Infinite, true)
+	assert.NoError(t, err)
+	assert.NotNil(t, rr)
+}
+
+func TestPinSubVolumeGroup(t *testing.T) {
+	if serverIsNautilus || serverIsOctopus {
+		t.Skipf("can only execute on pacific and later servers")
+	}
+	fsa := getFSAdmin(t)
+	volume := "cephfs"
+	group := "pinnedSVG"
+
+	err := fsa.CreateSubVolumeGroup(volume, group, nil)
+	assert.NoError(t, err)
+	defer func() {
+		err := fsa.RemoveSubVolumeGroup(volume, group)
+		assert.NoError(t, err)
+	}()
+
+	err = fsa.PinSubVolumeGroup(volume, group, ExportPin, "0")
+	assert.NoError(t, err)
+	err = fsa.PinSubVolumeGroup(volume, group, DistributedPin, "1")
+	assert.NoError(t, err)
+	err = fsa.PinSubVolumeGroup(volume, group, RandomPin, "0.01")
+	assert.NoError(t, err)
+
+	err = fsa.PinSubVolumeGroup(volume, group, PinType("bogus"), "0")
+	assert.Error(t, err)
+}
@@ -3,6 +3,7 @@
 package admin
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -119,3 +120,76 @@ func TestSubVolumeGroupPath(t *testing.T) {
 	assert.Error(t, err)
 	assert.Equal(t, "", path)
 }
+
+func TestSubVolumeGroupSnapshots(t *testing.T) {
+	fsa := getFSAdmin(t)
+	volume := "cephfs"
+	group := "snapSVG"
+	snapname := "gsnap1"
+
+	err := fsa.CreateSubVolumeGroup(volume, group, nil)
+	assert.NoError(t, err)
+	defer func() {
+		err := fsa.RemoveSubVolumeGroup(volume, group)
+		assert.NoError(t, err)
+	}()
+
+	err = fsa.CreateSubVolumeGroupSnapshot(volume, group, snapname)
+	var notImpl NotImplementedError
+	if errors.As(err, &notImpl) {
+		t.Skipf("subvolume group snapshots are not supported: %v", err)
+	}
+	assert.NoError(t, err)
+
+	snaps, err := fsa.ListSubVolumeGroupSnapshots(volume, group)
+	assert.NoError(t, err)
+	assert.Contains(t, snaps, snapname)
+
+	p, err := fsa.SubVolumeGroupSnapshotPath(volume, group, snapname)
+	assert.NoError(t, err)
+	assert.Contains(t, p, "/volumes/"+group+"/.snap/"+snapname)
+
+	err = fsa.RemoveSubVolumeGroupSnapshot(volume, group, snapname)
+	assert.NoError(t, err)
+
+	snaps, err = fsa.ListSubVolumeGroupSnapshots(volume, group)
+	assert.NoError(t, err)
+	assert.NotContains(t, snaps, snapname)
+
+	// removing a missing snapshot only succeeds with force
+	err = fsa.RemoveSubVolumeGroupSnapshot(volume, group, snapname)
+	assert.Error(t, err)
+	err = fsa.ForceRemoveSubVolumeGroupSnapshot(volume, group, snapname)
+	assert.NoError(t, err)
+}
+
+type testCephError int
+
+func (e testCephError) Error() string  { return "ceph error" }
+func (e testCephError) ErrorCode() int { return int(e) }
+
+func TestCreateSubVolumeGroupSnapshotCommand(t *testing.T) {
+	rc := &recordingCommander{}
+	fsa := NewFromConn(rc)
+
+	err := fsa.CreateSubVolumeGroupSnapshot("cephfs", "grewp", "gsnap1")
+	assert.NoError(t, err)
+	assert.JSONEq(t,
+		`{"prefix": "fs subvolumegroup snapshot create", "vol_name": "cephfs",
+		"group_name": "grewp", "snap_name": "gsnap1", "format": "json"}`,
+		rc.cmd)
+
+	rc.status = "subvolume group snapshots are not supported"
+	rc.err = testCephError(enosys)
+	err = fsa.CreateSubVolumeGroupSnapshot("cephfs", "grewp", "gsnap1")
+	var notImpl NotImplementedError
+	if assert.True(t, errors.As(err, &notImpl)) {
+		assert.Contains(t, err.Error(), "not supported")
+	}
+
+	rc.status = "group 'grewp' does not exist"
+	rc.err = testCephError(-2)
+	err = fsa.CreateSubVolumeGroupSnapshot("cephfs", "grewp", "gsnap1")
+	assert.Error(t, err)
+	assert.False(t, errors.As(err, &notImpl))
+}
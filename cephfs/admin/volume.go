@@ -4,6 +4,10 @@ package admin
 
 import (
 	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/ceph/go-ceph/internal/commands"
 )
 
 var (
@@ -41,9 +45,9 @@ func (fsa *FSAdmin) ListFileSystems() ([]FSPoolInfo, error) {
 	return parseFsList(res)
 }
 
-func parseFsList(res response) ([]FSPoolInfo, error) {
+func parseFsList(res commands.Response) ([]FSPoolInfo, error) {
 	var listing []FSPoolInfo
-	if err := res.noStatus().unmarshal(&listing).End(); err != nil {
+	if err := res.NoStatus().Unmarshal(&listing).End(); err != nil {
 		return nil, err
 	}
 	return listing, nil
@@ -69,22 +73,21 @@ type fsDump struct {
 
 const (
 	dumpOkPrefix = "dumped fsmap epoch"
-	dumpOkLen    = len(dumpOkPrefix)
 
 	invalidTextualResponse = "this ceph version returns a non-parsable volume status response"
 )
 
-func parseDumpToIdents(res response) ([]VolumeIdent, error) {
+func parseDumpToIdents(res commands.Response) ([]VolumeIdent, error) {
 	if !res.Ok() {
 		return nil, res.End()
 	}
-	if len(res.status) >= dumpOkLen && res.status[:dumpOkLen] == dumpOkPrefix {
+	if strings.HasPrefix(res.Status(), dumpOkPrefix) {
 		// Unhelpfully, ceph drops a status string on success responses for this
 		// call. this hacks around that by ignoring its typical prefix
-		res.status = ""
+		res = commands.NewResponse(res.Body(), "", nil)
 	}
 	var dump fsDump
-	if err := res.noStatus().unmarshal(&dump).End(); err != nil {
+	if err := res.NoStatus().Unmarshal(&dump).End(); err != nil {
 		return nil, err
 	}
 	// copy the dump json into the simpler enumeration list
@@ -121,17 +124,19 @@ type VolumeStatus struct {
 	Pools      []VolumePool `json:"pools"`
 }
 
-func parseVolumeStatus(res response) (*VolumeStatus, error) {
+func parseVolumeStatus(res commands.Response) (*VolumeStatus, error) {
 	var vs VolumeStatus
-	res = res.noStatus()
+	res = res.NoStatus()
 	if !res.Ok() {
 		return nil, res.End()
 	}
-	res = res.unmarshal(&vs)
+	res = res.Unmarshal(&vs)
 	if !res.Ok() {
-		if bytes.HasPrefix(res.body, []byte("ceph")) {
-			res.status = invalidTextualResponse
-			return nil, NotImplementedError{response: res}
+		if bytes.HasPrefix(res.Body(), []byte("ceph")) {
+			return nil, NotImplementedError{
+				Response: commands.NewResponse(
+					res.Body(), invalidTextualResponse, res.Unwrap()),
+			}
 		}
 		return nil, res.End()
 	}
@@ -150,3 +155,145 @@ func (fsa *FSAdmin) VolumeStatus(name string) (*VolumeStatus, error) {
 	})
 	return parseVolumeStatus(res)
 }
+
+// CreateVolume creates a new CephFS volume, including the pools and the MDS
+// daemons needed to serve it if the cluster has an orchestrator.
+//
+// Similar To:
+//  ceph fs volume create <name>
+func (fsa *FSAdmin) CreateVolume(name string) error {
+	m := map[string]string{
+		"prefix": "fs volume create",
+		"name":   name,
+		"format": "json",
+	}
+	// ceph may report on the created daemons in the status string
+	return fsa.marshalMgrCommand(m).NoBody().End()
+}
+
+// RemoveVolume removes a CephFS volume, its pools and its MDS daemons. All
+// the data stored in the volume is lost. The cluster must be configured to
+// allow the removal of pools (mon_allow_pool_delete).
+//
+// Similar To:
+//  ceph fs volume rm <name> --yes-i-really-mean-it
+func (fsa *FSAdmin) RemoveVolume(name string) error {
+	m := map[string]string{
+		"prefix":               "fs volume rm",
+		"vol_name":             name,
+		"yes-i-really-mean-it": "--yes-i-really-mean-it",
+		"format":               "json",
+	}
+	return fsa.marshalMgrCommand(m).NoBody().End()
+}
+
+// MDSInfo reports the state of an MDS daemon taking part in a file system.
+type MDSInfo struct {
+	GID         int64  `json:"gid"`
+	Name        string `json:"name"`
+	Rank        int    `json:"rank"`
+	Incarnation int    `json:"incarnation"`
+	State       string `json:"state"`
+	Addr        string `json:"addr"`
+}
+
+// MDSMap reports the settings of a file system and the state of the MDS
+// daemons serving it.
+type MDSMap struct {
+	Epoch              int64              `json:"epoch"`
+	FSName             string             `json:"fs_name"`
+	Flags              int64              `json:"flags"`
+	Enabled            bool               `json:"enabled"`
+	MaxMDS             int                `json:"max_mds"`
+	StandbyCountWanted int                `json:"standby_count_wanted"`
+	SessionTimeout     int                `json:"session_timeout"`
+	SessionAutoclose   int                `json:"session_autoclose"`
+	MaxFileSize        uint64             `json:"max_file_size"`
+	In                 []int              `json:"in"`
+	Up                 map[string]int64   `json:"up"`
+	Failed             []int              `json:"failed"`
+	Damaged            []int              `json:"damaged"`
+	Stopped            []int              `json:"stopped"`
+	Info               map[string]MDSInfo `json:"info"`
+	DataPools          []int              `json:"data_pools"`
+	MetadataPool       int                `json:"metadata_pool"`
+}
+
+// FileSystemInfo reports the ID and MDS map of a file system.
+type FileSystemInfo struct {
+	ID     int64  `json:"id"`
+	MDSMap MDSMap `json:"mdsmap"`
+}
+
+func parseFileSystemInfo(res commands.Response) (*FileSystemInfo, error) {
+	var info FileSystemInfo
+	if err := res.NoStatus().Unmarshal(&info).End(); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// GetFileSystem returns the settings and MDS state of the named file system.
+//
+// Similar To:
+//  ceph fs get <name>
+func (fsa *FSAdmin) GetFileSystem(name string) (*FileSystemInfo, error) {
+	m := map[string]string{
+		"prefix":  "fs get",
+		"fs_name": name,
+		"format":  "json",
+	}
+	return parseFileSystemInfo(fsa.marshalMonCommand(m))
+}
+
+// SetFileSystemOption changes a setting of the named file system. Both the
+// option name and the value are passed to ceph as is.
+//
+// Similar To:
+//  ceph fs set <name> <option> <value>
+func (fsa *FSAdmin) SetFileSystemOption(name, option, value string) error {
+	m := map[string]string{
+		"prefix":  "fs set",
+		"fs_name": name,
+		"var":     option,
+		"val":     value,
+		"format":  "json",
+	}
+	// ceph may describe the change in the status string
+	return fsa.marshalMonCommand(m).NoBody().End()
+}
+
+// SetMaxMDS sets the number of active MDS daemons of the file system.
+//
+// Similar To:
+//  ceph fs set <name> max_mds <count>
+func (fsa *FSAdmin) SetMaxMDS(name string, count int) error {
+	return fsa.SetFileSystemOption(name, "max_mds", strconv.Itoa(count))
+}
+
+// SetStandbyCountWanted sets the number of standby MDS daemons below which
+// the cluster reports a health warning for the file system.
+//
+// Similar To:
+//  ceph fs set <name> standby_count_wanted <count>
+func (fsa *FSAdmin) SetStandbyCountWanted(name string, count int) error {
+	return fsa.SetFileSystemOption(name, "standby_count_wanted", strconv.Itoa(count))
+}
+
+// SetAllowStandbyReplay sets whether standby MDS daemons may follow the
+// journal of the active daemons of the file system.
+//
+// Similar To:
+//  ceph fs set <name> allow_standby_replay <allow>
+func (fsa *FSAdmin) SetAllowStandbyReplay(name string, allow bool) error {
+	return fsa.SetFileSystemOption(name, "allow_standby_replay", strconv.FormatBool(allow))
+}
+
+// SetJoinable sets whether MDS daemons may join the file system. Setting it
+// to false fails the file system.
+//
+// Similar To:
+//  ceph fs set <name> joinable <joinable>
+func (fsa *FSAdmin) SetJoinable(name string, joinable bool) error {
+	return fsa.SetFileSystemOption(name, "joinable", strconv.FormatBool(joinable))
+}
@@ -4,9 +4,14 @@ package admin
 
 import (
 	"errors"
+	"strconv"
+	"strings"
 	"testing"
 
+	"github.com/ceph/go-ceph/internal/commands"
+
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestListVolumes(t *testing.T) {
@@ -140,7 +145,7 @@ var sampleDump2 = []byte(`
 `)
 
 func TestParseDumpToIdents(t *testing.T) {
-	R := newResponse
+	R := commands.NewResponse
 	fakePrefix := dumpOkPrefix + " 5"
 	t.Run("error", func(t *testing.T) {
 		idents, err := parseDumpToIdents(R(nil, "", errors.New("boop")))
@@ -231,7 +236,7 @@ MDS version: ceph version 14.2.11 (f7fdb2f52131f54b891a2ec99d8205561242cdaf) nau
 `)
 
 func TestParseVolumeStatus(t *testing.T) {
-	R := newResponse
+	R := commands.NewResponse
 	t.Run("error", func(t *testing.T) {
 		_, err := parseVolumeStatus(R(nil, "", errors.New("bonk")))
 		assert.Error(t, err)
@@ -310,20 +315,20 @@ var sampleFsLs2 = []byte(`
 
 func TestParseFsList(t *testing.T) {
 	t.Run("error", func(t *testing.T) {
-		_, err := parseFsList(response{err: errors.New("eek")})
+		_, err := parseFsList(commands.NewResponse(nil, "", errors.New("eek")))
 		assert.Error(t, err)
 		assert.Equal(t, "eek", err.Error())
 	})
 	t.Run("statusSet", func(t *testing.T) {
-		_, err := parseFsList(response{status: "oof"})
+		_, err := parseFsList(commands.NewResponse(nil, "oof", nil))
 		assert.Error(t, err)
 	})
 	t.Run("badJSON", func(t *testing.T) {
-		_, err := parseFsList(response{body: []byte("______")})
+		_, err := parseFsList(commands.NewResponse([]byte("______"), "", nil))
 		assert.Error(t, err)
 	})
 	t.Run("ok1", func(t *testing.T) {
-		l, err := parseFsList(response{body: sampleFsLs1})
+		l, err := parseFsList(commands.NewResponse(sampleFsLs1, "", nil))
 		assert.NoError(t, err)
 		if assert.NotNil(t, l) && assert.Len(t, l, 1) {
 			fs := l[0]
@@ -337,7 +342,7 @@ func TestParseFsList(t *testing.T) {
 		}
 	})
 	t.Run("ok2", func(t *testing.T) {
-		l, err := parseFsList(response{body: sampleFsLs2})
+		l, err := parseFsList(commands.NewResponse(sampleFsLs2, "", nil))
 		assert.NoError(t, err)
 		if assert.NotNil(t, l) && assert.Len(t, l, 2) {
 			fs := l[0]
@@ -374,3 +379,234 @@ func TestListFileSystems(t *testing.T) {
 		assert.Contains(t, l[0].DataPools, "cephfs_data")
 	}
 }
+
+var sampleFsGet1 = []byte(`
+{
+  "mdsmap": {
+    "epoch": 12,
+    "flags": 18,
+    "ever_allowed_features": 0,
+    "explicitly_allowed_features": 0,
+    "created": "2021-03-18T14:21:09.151931+0000",
+    "modified": "2021-03-18T14:21:12.190373+0000",
+    "tableserver": 0,
+    "root": 0,
+    "session_timeout": 60,
+    "session_autoclose": 300,
+    "required_client_features": {},
+    "max_file_size": 1099511627776,
+    "last_failure": 0,
+    "last_failure_osd_epoch": 0,
+    "compat": {
+      "compat": {},
+      "ro_compat": {},
+      "incompat": {
+        "feature_1": "base v0.20"
+      }
+    },
+    "max_mds": 1,
+    "in": [
+      0
+    ],
+    "up": {
+      "mds_0": 4137
+    },
+    "failed": [],
+    "damaged": [],
+    "stopped": [],
+    "info": {
+      "gid_4137": {
+        "gid": 4137,
+        "name": "a",
+        "rank": 0,
+        "incarnation": 4,
+        "state": "up:active",
+        "state_seq": 5,
+        "addr": "127.0.0.1:6805/2391296101",
+        "join_fscid": -1,
+        "export_targets": [],
+        "features": 4540138292836696063,
+        "flags": 0
+      }
+    },
+    "data_pools": [
+      2
+    ],
+    "metadata_pool": 1,
+    "enabled": true,
+    "fs_name": "cephfs",
+    "balancer": "",
+    "standby_count_wanted": 0
+  },
+  "id": 1
+}
+`)
+
+func TestParseFileSystemInfo(t *testing.T) {
+	R := commands.NewResponse
+	t.Run("error", func(t *testing.T) {
+		_, err := parseFileSystemInfo(R(nil, "", errors.New("bonk")))
+		assert.Error(t, err)
+		assert.Equal(t, "bonk", err.Error())
+	})
+	t.Run("statusSet", func(t *testing.T) {
+		_, err := parseFileSystemInfo(R(nil, "unexpected!", nil))
+		assert.Error(t, err)
+	})
+	t.Run("badJSON", func(t *testing.T) {
+		_, err := parseFileSystemInfo(R([]byte("_XxXxX"), "", nil))
+		assert.Error(t, err)
+	})
+	t.Run("ok", func(t *testing.T) {
+		info, err := parseFileSystemInfo(R(sampleFsGet1, "", nil))
+		assert.NoError(t, err)
+		if assert.NotNil(t, info) {
+			assert.EqualValues(t, 1, info.ID)
+			assert.Equal(t, "cephfs", info.MDSMap.FSName)
+			assert.Equal(t, 1, info.MDSMap.MaxMDS)
+			assert.True(t, info.MDSMap.Enabled)
+			assert.Equal(t, []int{0}, info.MDSMap.In)
+			assert.EqualValues(t, 4137, info.MDSMap.Up["mds_0"])
+			assert.Len(t, info.MDSMap.Failed, 0)
+			assert.Equal(t, []int{2}, info.MDSMap.DataPools)
+			if assert.Contains(t, info.MDSMap.Info, "gid_4137") {
+				mi := info.MDSMap.Info["gid_4137"]
+				assert.Equal(t, "a", mi.Name)
+				assert.Equal(t, 0, mi.Rank)
+				assert.Equal(t, "up:active", mi.State)
+			}
+		}
+	})
+}
+
+func TestGetFileSystem(t *testing.T) {
+	fsa := getFSAdmin(t)
+
+	info, err := fsa.GetFileSystem("cephfs")
+	assert.NoError(t, err)
+	if assert.NotNil(t, info) {
+		assert.Equal(t, "cephfs", info.MDSMap.FSName)
+		assert.GreaterOrEqual(t, info.MDSMap.MaxMDS, 1)
+		assert.Len(t, info.MDSMap.Up, 1)
+	}
+
+	_, err = fsa.GetFileSystem("nope")
+	assert.Error(t, err)
+}
+
+func TestSetFileSystemOption(t *testing.T) {
+	fsa := getFSAdmin(t)
+	volume := "cephfs"
+
+	info, err := fsa.GetFileSystem(volume)
+	assert.NoError(t, err)
+	require.NotNil(t, info)
+	orig := info.MDSMap.StandbyCountWanted
+	defer func() {
+		err := fsa.SetStandbyCountWanted(volume, orig)
+		assert.NoError(t, err)
+	}()
+
+	err = fsa.SetStandbyCountWanted(volume, orig+1)
+	assert.NoError(t, err)
+	info, err = fsa.GetFileSystem(volume)
+	assert.NoError(t, err)
+	require.NotNil(t, info)
+	assert.Equal(t, orig+1, info.MDSMap.StandbyCountWanted)
+
+	err = fsa.SetMaxMDS(volume, info.MDSMap.MaxMDS)
+	assert.NoError(t, err)
+
+	err = fsa.SetFileSystemOption(volume, "not_an_option", "1")
+	assert.Error(t, err)
+}
+
+func TestCreateRemoveVolumeCommands(t *testing.T) {
+	rc := &recordingCommander{}
+	fsa := NewFromConn(rc)
+
+	// ceph reports the new pools and daemons in the status
+	rc.status = "Volume created successfully (no MDS daemons created)"
+	err := fsa.CreateVolume("vol1")
+	assert.NoError(t, err)
+	assert.JSONEq(t,
+		`{"prefix": "fs volume create", "name": "vol1", "format": "json"}`,
+		rc.cmd)
+
+	rc.status = ""
+	err = fsa.RemoveVolume("vol1")
+	assert.NoError(t, err)
+	assert.JSONEq(t,
+		`{"prefix": "fs volume rm", "vol_name": "vol1",
+		  "yes-i-really-mean-it": "--yes-i-really-mean-it", "format": "json"}`,
+		rc.cmd)
+
+	rc.body = []byte("unexpected")
+	assert.Error(t, fsa.CreateVolume("vol1"))
+	assert.Error(t, fsa.RemoveVolume("vol1"))
+	rc.body = nil
+	rc.err = errors.New("EPERM")
+	assert.Error(t, fsa.CreateVolume("vol1"))
+	assert.Error(t, fsa.RemoveVolume("vol1"))
+}
+
+// monAllowsPoolDelete returns true if the monitors are configured to allow
+// the removal of pools, which removing a volume requires.
+func monAllowsPoolDelete(t *testing.T, fsa *FSAdmin) bool {
+	// without a format ceph replies with the plain value of the option
+	res := fsa.marshalMonCommand(map[string]string{
+		"prefix": "config get",
+		"who":    "mon",
+		"key":    "mon_allow_pool_delete",
+	})
+	require.NoError(t, res.Unwrap())
+	allow, err := strconv.ParseBool(strings.TrimSpace(string(res.Body())))
+	return err == nil && allow
+}
+
+// multipleFileSystemsEnabled returns true if more than one file system may be
+// created in the cluster.
+func multipleFileSystemsEnabled(t *testing.T, fsa *FSAdmin) bool {
+	res := fsa.marshalMonCommand(map[string]string{
+		"prefix": "fs dump",
+		"format": "json",
+	})
+	var dump struct {
+		FeatureFlags struct {
+			EnableMultiple bool `json:"enable_multiple"`
+		} `json:"feature_flags"`
+	}
+	require.NoError(t, res.NoStatus().Unmarshal(&dump).End())
+	return dump.FeatureFlags.EnableMultiple
+}
+
+func TestCreateRemoveVolume(t *testing.T) {
+	fsa := getFSAdmin(t)
+	if !monAllowsPoolDelete(t, fsa) {
+		t.Skipf("mon_allow_pool_delete is not enabled")
+	}
+	if !multipleFileSystemsEnabled(t, fsa) {
+		t.Skipf("multiple file systems are not enabled")
+	}
+	volume := "createdVolume"
+
+	err := fsa.CreateVolume(volume)
+	require.NoError(t, err)
+	removed := false
+	defer func() {
+		if !removed {
+			assert.NoError(t, fsa.RemoveVolume(volume))
+		}
+	}()
+
+	vl, err := fsa.ListVolumes()
+	assert.NoError(t, err)
+	assert.Contains(t, vl, volume)
+
+	err = fsa.RemoveVolume(volume)
+	assert.NoError(t, err)
+	removed = err == nil
+	vl, err = fsa.ListVolumes()
+	assert.NoError(t, err)
+	assert.NotContains(t, vl, volume)
+}
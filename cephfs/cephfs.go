@@ -18,6 +18,8 @@ import (
 // MountInfo exports ceph's ceph_mount_info from libcephfs.cc
 type MountInfo struct {
 	mount *C.struct_ceph_mount_info
+	// perms are the credentials set with SetMountPerms, if any
+	perms *UserPerm
 }
 
 func createMount(id *C.char) (*MountInfo, error) {
@@ -17,6 +17,8 @@ import (
 type Directory struct {
 	mount *MountInfo
 	dir   *C.struct_ceph_dir_result
+	// ll is set for directories opened with the low level API
+	ll bool
 }
 
 // OpenDir returns a new Directory handle open for I/O.
@@ -44,7 +46,11 @@ func (mount *MountInfo) OpenDir(path string) (*Directory, error) {
 //
 // Implements:
 //  int ceph_closedir(struct ceph_mount_info *cmount, struct ceph_dir_result *dirp);
+//  int ceph_ll_releasedir(struct ceph_mount_info *cmount, struct ceph_dir_result* dir);
 func (dir *Directory) Close() error {
+	if dir.ll {
+		return getError(C.ceph_ll_releasedir(dir.mount.mount, dir.dir))
+	}
 	return getError(C.ceph_closedir(dir.mount.mount, dir.dir))
 }
 
@@ -46,6 +46,10 @@ var (
 	// ErrEmptyArgument may be returned if a function argument is passed
 	// a zero-length slice or map.
 	ErrEmptyArgument = errors.New("Argument must contain at least one item")
+
+	// ErrInodeReleased may be returned when an InodeRef is used after its
+	// reference on the inode was dropped.
+	ErrInodeReleased = errors.New("inode reference has been released")
 )
 
 // Public CephFSErrors:
@@ -54,13 +58,21 @@ const (
 	// ErrNotConnected may be returned when client is not connected
 	// to a cluster.
 	ErrNotConnected = cephFSError(-C.ENOTCONN)
+	// ErrWouldBlock may be returned when a lock can not be acquired without
+	// waiting.
+	ErrWouldBlock = cephFSError(-C.EWOULDBLOCK)
 )
 
 // Private errors:
 
 const (
+	errAccess      = cephFSError(-C.EACCES)
+	errBadFile     = cephFSError(-C.EBADF)
+	errExist       = cephFSError(-C.EEXIST)
 	errInvalid     = cephFSError(-C.EINVAL)
 	errNameTooLong = cephFSError(-C.ENAMETOOLONG)
+	errNoData      = cephFSError(-C.ENODATA)
 	errNoEntry     = cephFSError(-C.ENOENT)
+	errNotDir      = cephFSError(-C.ENOTDIR)
 	errRange       = cephFSError(-C.ERANGE)
 )
@@ -0,0 +1,433 @@
+// +build go1.16
+
+package cephfs
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/ceph/go-ceph/internal/filemode"
+)
+
+// FS provides access to the file tree of a mounted file system through the
+// interfaces of the io/fs package. FS implements fs.FS, fs.ReadDirFS,
+// fs.StatFS and fs.ReadFileFS as well as WritableFS.
+//
+// The errors returned by FS are of type *fs.PathError and wrap
+// syscall.Errno values, so they can be checked with errors.Is against
+// fs.ErrNotExist, fs.ErrExist and similar errors.
+type FS struct {
+	mount *MountInfo
+	root  string
+}
+
+// WritableFS extends the read only interfaces of the io/fs package with
+// functions that modify the file system.
+type WritableFS interface {
+	fs.FS
+	// OpenFile opens the named file with the given os.O_* flags. If the
+	// file is created it gets the permission bits of perm.
+	OpenFile(name string, flag int, perm fs.FileMode) (WritableFile, error)
+	// Create creates or truncates the named file.
+	Create(name string) (WritableFile, error)
+	// WriteFile writes data to the named file, creating it if needed.
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+	// Mkdir creates the named directory.
+	Mkdir(name string, perm fs.FileMode) error
+	// Remove removes the named file or empty directory.
+	Remove(name string) error
+	// Rename renames oldname to newname.
+	Rename(oldname, newname string) error
+	// Chmod changes the permission bits of the named file.
+	Chmod(name string, mode fs.FileMode) error
+	// Chown changes the owner and group of the named file.
+	Chown(name string, uid, gid int) error
+}
+
+// WritableFile is a file opened through WritableFS.
+type WritableFile interface {
+	fs.File
+	io.ReaderAt
+	io.Writer
+	io.WriterAt
+	io.Seeker
+	Sync() error
+	Truncate(size int64) error
+}
+
+// FS returns an FS for the tree of the mounted file system rooted at the
+// directory root. Names passed to the FS are always relative to root, as
+// required by the io/fs package. If root is empty the current working
+// directory of the mount is used.
+func (mount *MountInfo) FS(root string) *FS {
+	return &FS{mount: mount, root: root}
+}
+
+var (
+	_ fs.ReadDirFS  = (*FS)(nil)
+	_ fs.StatFS     = (*FS)(nil)
+	_ fs.ReadFileFS = (*FS)(nil)
+	_ WritableFS    = (*FS)(nil)
+)
+
+// fullPath validates the fs.FS name and converts it to a path of the mount.
+func (fsys *FS) fullPath(op, name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	if fsys.root == "" {
+		return name, nil
+	}
+	return path.Join(fsys.root, name), nil
+}
+
+// sysError converts cephfs error codes to syscall.Errno values.
+func sysError(err error) error {
+	var cerr cephFSError
+	if errors.As(err, &cerr) {
+		return syscall.Errno(-cerr)
+	}
+	return err
+}
+
+// pathError wraps an error returned by the mount in an *fs.PathError.
+func pathError(op, name string, err error) error {
+	return &fs.PathError{Op: op, Path: name, Err: sysError(err)}
+}
+
+// Open opens the named file or directory for reading.
+func (fsys *FS) Open(name string) (fs.File, error) {
+	full, err := fsys.fullPath("open", name)
+	if err != nil {
+		return nil, err
+	}
+	stx, err := fsys.mount.Statx(full, StatxBasicStats, 0)
+	if err != nil {
+		return nil, pathError("open", name, err)
+	}
+	info := &fileInfo{name: path.Base(name), stx: stx}
+	if info.IsDir() {
+		dir, err := fsys.mount.OpenDir(full)
+		if err != nil {
+			return nil, pathError("open", name, err)
+		}
+		return &fsDir{name: name, dir: dir, info: info}, nil
+	}
+	f, err := fsys.mount.Open(full, os.O_RDONLY, 0)
+	if err != nil {
+		return nil, pathError("open", name, err)
+	}
+	return &fsFile{File: f, name: name}, nil
+}
+
+// Stat returns the fs.FileInfo of the named file, following symlinks.
+// The Sys method of the fs.FileInfo returns a *CephStatx.
+func (fsys *FS) Stat(name string) (fs.FileInfo, error) {
+	full, err := fsys.fullPath("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	stx, err := fsys.mount.Statx(full, StatxBasicStats, 0)
+	if err != nil {
+		return nil, pathError("stat", name, err)
+	}
+	return &fileInfo{name: path.Base(name), stx: stx}, nil
+}
+
+// ReadDir returns the entries of the named directory sorted by file name.
+func (fsys *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	full, err := fsys.fullPath("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := fsys.mount.OpenDir(full)
+	if err != nil {
+		return nil, pathError("readdir", name, err)
+	}
+	defer dir.Close()
+
+	d := &fsDir{name: name, dir: dir}
+	entries, err := d.ReadDir(-1)
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return entries, err
+}
+
+// ReadFile returns the content of the named file.
+func (fsys *FS) ReadFile(name string) ([]byte, error) {
+	full, err := fsys.fullPath("readfile", name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := fsys.mount.Open(full, os.O_RDONLY, 0)
+	if err != nil {
+		return nil, pathError("readfile", name, err)
+	}
+	defer f.Close()
+
+	var size int
+	if stx, err := f.Fstatx(StatxSize, 0); err == nil {
+		size = int(stx.Size)
+	}
+	data := make([]byte, 0, size+1)
+	for {
+		if len(data) == cap(data) {
+			data = append(data, 0)[:len(data)]
+		}
+		n, err := f.Read(data[len(data):cap(data)])
+		data = data[:len(data)+n]
+		if err == io.EOF {
+			return data, nil
+		}
+		if err != nil {
+			return data, pathError("readfile", name, err)
+		}
+	}
+}
+
+// OpenFile opens the named file with the given os.O_* flags. If the file is
+// created it gets the permission bits of perm.
+func (fsys *FS) OpenFile(name string, flag int, perm fs.FileMode) (WritableFile, error) {
+	full, err := fsys.fullPath("open", name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := fsys.mount.Open(full, flag, filemode.ToUnix(perm))
+	if err != nil {
+		return nil, pathError("open", name, err)
+	}
+	return &fsFile{File: f, name: name}, nil
+}
+
+// Create creates or truncates the named file. If the file is created it gets
+// the mode 0666, before the umask is applied.
+func (fsys *FS) Create(name string) (WritableFile, error) {
+	return fsys.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+}
+
+// WriteFile writes data to the named file, creating it with the permission
+// bits of perm if needed. An existing file is truncated first.
+func (fsys *FS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	f, err := fsys.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return pathError("write", name, err)
+	}
+	return nil
+}
+
+// Mkdir creates the named directory with the permission bits of perm.
+func (fsys *FS) Mkdir(name string, perm fs.FileMode) error {
+	full, err := fsys.fullPath("mkdir", name)
+	if err != nil {
+		return err
+	}
+	if err = fsys.mount.MakeDir(full, filemode.ToUnix(perm)); err != nil {
+		return pathError("mkdir", name, err)
+	}
+	return nil
+}
+
+// Remove removes the named file or empty directory.
+func (fsys *FS) Remove(name string) error {
+	full, err := fsys.fullPath("remove", name)
+	if err != nil {
+		return err
+	}
+	err = fsys.mount.Unlink(full)
+	if err == nil {
+		return nil
+	}
+	if derr := fsys.mount.RemoveDir(full); derr == nil {
+		return nil
+	} else if sysError(derr) != syscall.ENOTDIR {
+		err = derr
+	}
+	return pathError("remove", name, err)
+}
+
+// Rename renames oldname to newname, replacing newname if it exists and is
+// not a directory.
+func (fsys *FS) Rename(oldname, newname string) error {
+	from, err := fsys.fullPath("rename", oldname)
+	if err != nil {
+		return err
+	}
+	to, err := fsys.fullPath("rename", newname)
+	if err != nil {
+		return err
+	}
+	if err = fsys.mount.Rename(from, to); err != nil {
+		return pathError("rename", oldname, err)
+	}
+	return nil
+}
+
+// Chmod changes the permission bits of the named file to those of mode.
+func (fsys *FS) Chmod(name string, mode fs.FileMode) error {
+	full, err := fsys.fullPath("chmod", name)
+	if err != nil {
+		return err
+	}
+	if err = fsys.mount.Chmod(full, filemode.ToUnix(mode)); err != nil {
+		return pathError("chmod", name, err)
+	}
+	return nil
+}
+
+// Chown changes the owner and group of the named file.
+func (fsys *FS) Chown(name string, uid, gid int) error {
+	full, err := fsys.fullPath("chown", name)
+	if err != nil {
+		return err
+	}
+	if err = fsys.mount.Chown(full, uint32(uid), uint32(gid)); err != nil {
+		return pathError("chown", name, err)
+	}
+	return nil
+}
+
+// fsFile adapts File to the fs.File and WritableFile interfaces.
+type fsFile struct {
+	*File
+	name string
+}
+
+func (f *fsFile) Stat() (fs.FileInfo, error) {
+	stx, err := f.Fstatx(StatxBasicStats, 0)
+	if err != nil {
+		return nil, pathError("stat", f.name, err)
+	}
+	return &fileInfo{name: path.Base(f.name), stx: stx}, nil
+}
+
+func (f *fsFile) Read(buf []byte) (int, error) {
+	if len(buf) == 0 {
+		return 0, nil
+	}
+	return f.File.Read(buf)
+}
+
+func (f *fsFile) ReadAt(buf []byte, off int64) (int, error) {
+	if len(buf) == 0 {
+		return 0, nil
+	}
+	// io.ReaderAt requires an error if fewer than len(buf) bytes are read
+	n, err := f.File.ReadAt(buf, off)
+	for err == nil && n < len(buf) {
+		var m int
+		m, err = f.File.ReadAt(buf[n:], off+int64(n))
+		n += m
+	}
+	return n, err
+}
+
+// fsDir adapts Directory to the fs.ReadDirFile interface.
+type fsDir struct {
+	name string
+	dir  *Directory
+	info *fileInfo
+	eof  bool
+}
+
+func (d *fsDir) Stat() (fs.FileInfo, error) {
+	return d.info, nil
+}
+
+func (d *fsDir) Read([]byte) (int, error) {
+	return 0, pathError("read", d.name, syscall.EISDIR)
+}
+
+func (d *fsDir) Close() error {
+	if err := d.dir.Close(); err != nil {
+		return pathError("close", d.name, err)
+	}
+	return nil
+}
+
+func (d *fsDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	entries := []fs.DirEntry{}
+	for !d.eof && (n <= 0 || len(entries) < n) {
+		de, err := d.dir.ReadDirPlus(StatxBasicStats, AtSymlinkNofollow)
+		if err != nil {
+			return entries, pathError("readdir", d.name, err)
+		}
+		if de == nil {
+			d.eof = true
+			break
+		}
+		if de.Name() == "." || de.Name() == ".." {
+			continue
+		}
+		entries = append(entries, &fsDirEntry{de})
+	}
+	if n > 0 && len(entries) == 0 {
+		return entries, io.EOF
+	}
+	return entries, nil
+}
+
+// fsDirEntry adapts DirEntryPlus to the fs.DirEntry interface.
+type fsDirEntry struct {
+	de *DirEntryPlus
+}
+
+func (e *fsDirEntry) Name() string {
+	return e.de.Name()
+}
+
+func (e *fsDirEntry) IsDir() bool {
+	return e.Type().IsDir()
+}
+
+func (e *fsDirEntry) Type() fs.FileMode {
+	return filemode.FromUnix(e.de.Statx().Mode).Type()
+}
+
+func (e *fsDirEntry) Info() (fs.FileInfo, error) {
+	return &fileInfo{name: e.de.Name(), stx: e.de.Statx()}, nil
+}
+
+// fileInfo adapts CephStatx to the fs.FileInfo interface.
+type fileInfo struct {
+	name string
+	stx  *CephStatx
+}
+
+func (fi *fileInfo) Name() string {
+	return fi.name
+}
+
+func (fi *fileInfo) Size() int64 {
+	return int64(fi.stx.Size)
+}
+
+func (fi *fileInfo) Mode() fs.FileMode {
+	return filemode.FromUnix(fi.stx.Mode)
+}
+
+func (fi *fileInfo) ModTime() time.Time {
+	return time.Unix(fi.stx.Mtime.Sec, fi.stx.Mtime.Nsec)
+}
+
+func (fi *fileInfo) IsDir() bool {
+	return fi.Mode().IsDir()
+}
+
+// Sys returns the *CephStatx the fs.FileInfo is based on.
+func (fi *fileInfo) Sys() interface{} {
+	return fi.stx
+}
@@ -0,0 +1,140 @@
+// +build go1.16
+
+package cephfs
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"syscall"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFS(t *testing.T) {
+	mount := fsConnect(t)
+	defer fsDisconnect(t, mount)
+
+	root := "/fstest"
+	require.NoError(t, mount.MakeDir(root, 0755))
+	defer func() { assert.NoError(t, mount.RemoveDir(root)) }()
+
+	fsys := mount.FS(root)
+
+	t.Run("writable", func(t *testing.T) {
+		err := fsys.Mkdir("dir", 0750)
+		assert.NoError(t, err)
+		err = fsys.Mkdir("dir", 0750)
+		assert.True(t, errors.Is(err, fs.ErrExist))
+
+		err = fsys.WriteFile("a.txt", []byte("hello cephfs"), 0640)
+		assert.NoError(t, err)
+
+		f, err := fsys.Create("dir/b.txt")
+		require.NoError(t, err)
+		_, err = f.Write([]byte("1234567890"))
+		assert.NoError(t, err)
+		assert.NoError(t, f.Truncate(5))
+		assert.NoError(t, f.Sync())
+		assert.NoError(t, f.Close())
+
+		err = fsys.Chmod("dir/b.txt", 0600)
+		assert.NoError(t, err)
+
+		err = fsys.WriteFile("tmp.txt", []byte("x"), 0600)
+		assert.NoError(t, err)
+		err = fsys.Rename("tmp.txt", "dir/c.txt")
+		assert.NoError(t, err)
+	})
+	defer func() {
+		for _, name := range []string{"dir/c.txt", "dir/b.txt", "dir", "a.txt"} {
+			assert.NoError(t, fsys.Remove(name))
+		}
+	}()
+
+	t.Run("fstest", func(t *testing.T) {
+		err := fstest.TestFS(fsys, "a.txt", "dir/b.txt", "dir/c.txt")
+		assert.NoError(t, err)
+	})
+
+	t.Run("readFile", func(t *testing.T) {
+		data, err := fsys.ReadFile("a.txt")
+		assert.NoError(t, err)
+		assert.Equal(t, "hello cephfs", string(data))
+		data, err = fs.ReadFile(fsys, "dir/b.txt")
+		assert.NoError(t, err)
+		assert.Equal(t, "12345", string(data))
+	})
+
+	t.Run("stat", func(t *testing.T) {
+		fi, err := fsys.Stat("a.txt")
+		require.NoError(t, err)
+		assert.Equal(t, "a.txt", fi.Name())
+		assert.Equal(t, int64(12), fi.Size())
+		assert.Equal(t, fs.FileMode(0640), fi.Mode())
+		assert.False(t, fi.IsDir())
+		assert.False(t, fi.ModTime().IsZero())
+		assert.IsType(t, &CephStatx{}, fi.Sys())
+
+		fi, err = fsys.Stat("dir")
+		require.NoError(t, err)
+		assert.True(t, fi.IsDir())
+		assert.Equal(t, fs.ModeDir|0750, fi.Mode())
+
+		_, err = fsys.Stat("missing")
+		assert.True(t, errors.Is(err, fs.ErrNotExist))
+		var perr *fs.PathError
+		if assert.True(t, errors.As(err, &perr)) {
+			assert.Equal(t, "stat", perr.Op)
+			assert.Equal(t, "missing", perr.Path)
+		}
+
+		_, err = fsys.Stat("/a.txt")
+		assert.True(t, errors.Is(err, fs.ErrInvalid))
+	})
+
+	t.Run("readDir", func(t *testing.T) {
+		entries, err := fsys.ReadDir(".")
+		require.NoError(t, err)
+		if assert.Len(t, entries, 2) {
+			assert.Equal(t, "a.txt", entries[0].Name())
+			assert.False(t, entries[0].IsDir())
+			assert.Equal(t, "dir", entries[1].Name())
+			assert.True(t, entries[1].IsDir())
+			assert.Equal(t, fs.ModeDir, entries[1].Type())
+			info, err := entries[1].Info()
+			assert.NoError(t, err)
+			assert.Equal(t, fs.ModeDir|0750, info.Mode())
+		}
+
+		f, err := fsys.Open("dir")
+		require.NoError(t, err)
+		defer f.Close()
+		d, ok := f.(fs.ReadDirFile)
+		require.True(t, ok)
+		_, err = d.Read(make([]byte, 4))
+		assert.True(t, errors.Is(err, syscall.EISDIR))
+		entries, err = d.ReadDir(1)
+		assert.NoError(t, err)
+		assert.Len(t, entries, 1)
+		entries, err = d.ReadDir(1)
+		assert.NoError(t, err)
+		assert.Len(t, entries, 1)
+		entries, err = d.ReadDir(1)
+		assert.Equal(t, io.EOF, err)
+		assert.Len(t, entries, 0)
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		err := fsys.Remove("dir")
+		assert.True(t, errors.Is(err, syscall.ENOTEMPTY))
+		err = fsys.Remove("missing")
+		assert.True(t, errors.Is(err, fs.ErrNotExist))
+		_, err = fsys.OpenFile("missing", os.O_RDONLY, 0)
+		assert.True(t, errors.Is(err, fs.ErrNotExist))
+	})
+}
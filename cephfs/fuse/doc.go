@@ -0,0 +1,15 @@
+/*
+Package fuse serves a mounted CephFS file system, a cephfs.MountInfo, to the
+local host through FUSE.
+
+The FUSE requests are mapped onto the path based and file descriptor based
+calls of the cephfs package, so that the server can be embedded in, and
+instrumented by, any Go program that already uses the cephfs package.
+
+flock(2) locks and POSIX record locks, taken with fcntl(2), on the mount point
+are forwarded to the cluster and so are enforced across all the clients of the
+file system. fallocate(2) calls are forwarded to the cluster as well.
+
+This package only supports ceph "mimic" and later.
+*/
+package fuse
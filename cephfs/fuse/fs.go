@@ -0,0 +1,130 @@
+// +build !luminous
+
+package fuse
+
+import (
+	"math"
+	"path"
+	"syscall"
+	"time"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+
+	"github.com/ceph/go-ceph/cephfs"
+)
+
+// statxWant is the set of stat fields requested from cephfs for FUSE
+// attributes.
+const statxWant = cephfs.StatxBasicStats
+
+// FS is a FUSE file system that serves a directory tree of a mounted CephFS.
+type FS struct {
+	mount *cephfs.MountInfo
+	root  string
+
+	// AttrValid is the time the kernel may cache the attributes and entries
+	// returned by the file system. It defaults to one second.
+	AttrValid time.Duration
+}
+
+// NewFS returns a FUSE file system that serves the directory root, and
+// everything below it, of the given mount.
+func NewFS(mount *cephfs.MountInfo, root string) *FS {
+	if root == "" {
+		root = "/"
+	}
+	return &FS{
+		mount:     mount,
+		root:      path.Clean(root),
+		AttrValid: time.Second,
+	}
+}
+
+// statfs fills the FUSE file system statistics from the mount.
+func (fsys *FS) statfs(out *fuse.StatfsOut) syscall.Errno {
+	st, err := fsys.mount.StatFS(fsys.root)
+	if err != nil {
+		return toErrno(err)
+	}
+	out.Blocks = st.Blocks
+	out.Bfree = st.Bfree
+	out.Bavail = st.Bavail
+	out.Files = st.Files
+	out.Ffree = st.Ffree
+	out.Bsize = uint32(st.Bsize)
+	out.Frsize = uint32(st.Frsize)
+	out.NameLen = uint32(st.Namemax)
+	return 0
+}
+
+// errorCoder is implemented by the errors of the cephfs package.
+type errorCoder interface {
+	ErrorCode() int
+}
+
+// toErrno converts an error returned by the cephfs package to the
+// syscall.Errno returned to the kernel. Errors that do not carry an error
+// code are reported as EIO.
+func toErrno(err error) syscall.Errno {
+	if err == nil {
+		return 0
+	}
+	if ec, ok := err.(errorCoder); ok && ec.ErrorCode() < 0 {
+		return syscall.Errno(-ec.ErrorCode())
+	}
+	return syscall.EIO
+}
+
+// fillAttr sets the FUSE attributes from the stat information of a file.
+func fillAttr(attr *fuse.Attr, stx *cephfs.CephStatx) {
+	attr.Ino = uint64(stx.Inode)
+	attr.Size = stx.Size
+	attr.Blocks = stx.Blocks
+	attr.Atime = uint64(stx.Atime.Sec)
+	attr.Atimensec = uint32(stx.Atime.Nsec)
+	attr.Mtime = uint64(stx.Mtime.Sec)
+	attr.Mtimensec = uint32(stx.Mtime.Nsec)
+	attr.Ctime = uint64(stx.Ctime.Sec)
+	attr.Ctimensec = uint32(stx.Ctime.Nsec)
+	attr.Mode = uint32(stx.Mode)
+	attr.Nlink = stx.Nlink
+	attr.Uid = stx.Uid
+	attr.Gid = stx.Gid
+	attr.Rdev = uint32(stx.Rdev)
+	attr.Blksize = stx.Blksize
+}
+
+// toTimespec converts a time.Time to a cephfs timestamp.
+func toTimespec(t time.Time) cephfs.Timespec {
+	return cephfs.Timespec{Sec: t.Unix(), Nsec: int64(t.Nanosecond())}
+}
+
+// offsetMax is the end offset the kernel uses for byte-range locks that
+// extend to the end of the file.
+const offsetMax = math.MaxInt64
+
+// toCephLock converts a FUSE byte-range lock, whose end offset is inclusive,
+// to a cephfs lock. Both use the lock types of fcntl.
+func toCephLock(lk *fuse.FileLock) *cephfs.FileLock {
+	lock := &cephfs.FileLock{
+		Type:   cephfs.LockType(lk.Typ),
+		Whence: cephfs.SeekSet,
+		Start:  int64(lk.Start),
+		Pid:    int(lk.Pid),
+	}
+	if lk.End < offsetMax {
+		lock.Len = int64(lk.End-lk.Start) + 1
+	}
+	return lock
+}
+
+// fromCephLock converts a cephfs byte-range lock back to a FUSE lock.
+func fromCephLock(lock *cephfs.FileLock, lk *fuse.FileLock) {
+	lk.Typ = uint32(lock.Type)
+	lk.Start = uint64(lock.Start)
+	lk.End = offsetMax
+	if lock.Len > 0 {
+		lk.End = uint64(lock.Start+lock.Len) - 1
+	}
+	lk.Pid = uint32(lock.Pid)
+}
@@ -0,0 +1,182 @@
+// +build !luminous
+
+package fuse
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ceph/go-ceph/cephfs"
+)
+
+type testCephError int
+
+func (e testCephError) Error() string  { return "ceph error" }
+func (e testCephError) ErrorCode() int { return int(e) }
+
+func TestToErrno(t *testing.T) {
+	assert.Equal(t, syscall.Errno(0), toErrno(nil))
+	assert.Equal(t, syscall.ENOENT, toErrno(testCephError(-2)))
+	assert.Equal(t, syscall.EEXIST, toErrno(testCephError(-17)))
+	assert.Equal(t, syscall.EIO, toErrno(os.ErrInvalid))
+}
+
+func TestCephLocks(t *testing.T) {
+	lk := &fuse.FileLock{Start: 10, End: 19, Typ: syscall.F_WRLCK, Pid: 42}
+	lock := toCephLock(lk)
+	assert.Equal(t, cephfs.LockTypeWrite, lock.Type)
+	assert.Equal(t, cephfs.SeekSet, lock.Whence)
+	assert.EqualValues(t, 10, lock.Start)
+	assert.EqualValues(t, 10, lock.Len)
+	assert.Equal(t, 42, lock.Pid)
+	var out fuse.FileLock
+	fromCephLock(lock, &out)
+	assert.Equal(t, *lk, out)
+
+	// a lock to the end of the file
+	lk = &fuse.FileLock{Start: 5, End: offsetMax, Typ: syscall.F_RDLCK}
+	lock = toCephLock(lk)
+	assert.Equal(t, cephfs.LockTypeRead, lock.Type)
+	assert.EqualValues(t, 0, lock.Len)
+	fromCephLock(lock, &out)
+	assert.Equal(t, *lk, out)
+
+	fromCephLock(&cephfs.FileLock{Type: cephfs.LockTypeUnlock}, &out)
+	assert.EqualValues(t, syscall.F_UNLCK, out.Typ)
+}
+
+func fsConnect(t *testing.T) *cephfs.MountInfo {
+	mount, err := cephfs.CreateMount()
+	require.NoError(t, err)
+	require.NoError(t, mount.ReadDefaultConfigFile())
+	require.NoError(t, mount.Mount())
+	return mount
+}
+
+func TestServe(t *testing.T) {
+	if _, err := os.Stat("/dev/fuse"); err != nil {
+		t.Skip("FUSE is not available:", err)
+	}
+	mount := fsConnect(t)
+	defer func() {
+		assert.NoError(t, mount.Unmount())
+		assert.NoError(t, mount.Release())
+	}()
+	require.NoError(t, mount.MakeDir("/fuse-serve", 0755))
+	defer func() { assert.NoError(t, mount.RemoveAll("/fuse-serve")) }()
+
+	dir, err := ioutil.TempDir("", "cephfs-fuse")
+	require.NoError(t, err)
+	defer os.Remove(dir)
+
+	srv, err := Mount(NewFS(mount, "/fuse-serve"), dir, nil)
+	if err != nil {
+		t.Skip("can not mount FUSE file system:", err)
+	}
+	served := make(chan struct{})
+	go func() {
+		srv.Serve()
+		close(served)
+	}()
+	defer func() {
+		require.NoError(t, srv.Unmount())
+		select {
+		case <-served:
+		case <-time.After(10 * time.Second):
+			t.Error("timed out waiting for the server to stop")
+		}
+	}()
+
+	// files written through FUSE are visible through cephfs
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0750))
+	name := filepath.Join(dir, "sub", "file")
+	require.NoError(t, ioutil.WriteFile(name, []byte("hello fuse"), 0640))
+	stx, err := mount.Statx("/fuse-serve/sub/file", cephfs.StatxBasicStats, 0)
+	require.NoError(t, err)
+	assert.EqualValues(t, 10, stx.Size)
+	assert.EqualValues(t, 0640, stx.Mode&0777)
+
+	data, err := ioutil.ReadFile(name)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello fuse", string(data))
+
+	mtime := time.Unix(1000000, 0)
+	require.NoError(t, os.Chtimes(name, mtime, mtime))
+	fi, err := os.Stat(name)
+	require.NoError(t, err)
+	assert.Equal(t, mtime, fi.ModTime())
+
+	// flock locks taken through FUSE are held in the cluster
+	lf, err := os.Open(name)
+	require.NoError(t, err)
+	require.NoError(t, syscall.Flock(int(lf.Fd()), syscall.LOCK_EX))
+	cf, err := mount.Open("/fuse-serve/sub/file", os.O_RDONLY, 0)
+	require.NoError(t, err)
+	assert.Error(t, cf.Flock(cephfs.LockEX|cephfs.LockNB, 4242))
+	require.NoError(t, syscall.Flock(int(lf.Fd()), syscall.LOCK_UN))
+	assert.NoError(t, cf.Flock(cephfs.LockEX|cephfs.LockNB, 4242))
+	assert.NoError(t, cf.Flock(cephfs.LockUN, 4242))
+	assert.NoError(t, cf.Close())
+	assert.NoError(t, lf.Close())
+
+	// POSIX locks taken through FUSE are held in the cluster
+	lf, err = os.OpenFile(name, os.O_RDWR, 0)
+	require.NoError(t, err)
+	flk := &syscall.Flock_t{Type: syscall.F_WRLCK}
+	require.NoError(t, syscall.FcntlFlock(lf.Fd(), syscall.F_SETLK, flk))
+	in, _, err := mount.LookupPath("/fuse-serve/sub/file", cephfs.StatxIno, 0, nil)
+	require.NoError(t, err)
+	fh, err := in.Open(os.O_RDWR, nil)
+	require.NoError(t, err)
+	lock := &cephfs.FileLock{Type: cephfs.LockTypeWrite, Whence: cephfs.SeekSet}
+	assert.Error(t, fh.SetLk(lock, 4242, false))
+	flk.Type = syscall.F_UNLCK
+	require.NoError(t, syscall.FcntlFlock(lf.Fd(), syscall.F_SETLK, flk))
+	assert.NoError(t, fh.SetLk(lock, 4242, false))
+	lock.Type = cephfs.LockTypeUnlock
+	assert.NoError(t, fh.SetLk(lock, 4242, false))
+	assert.NoError(t, fh.Close())
+	assert.NoError(t, in.Release())
+
+	// renaming the directory keeps the nodes below it usable
+	require.NoError(t, os.Rename(filepath.Join(dir, "sub"), filepath.Join(dir, "moved")))
+	name = filepath.Join(dir, "moved", "file")
+	data, err = ioutil.ReadFile(name)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello fuse", string(data))
+
+	// files opened before the rename are synced and allocated through
+	// their open handle
+	require.NoError(t, syscall.Fallocate(int(lf.Fd()), 0, 0, 4096))
+	assert.NoError(t, lf.Sync())
+	assert.NoError(t, syscall.Fdatasync(int(lf.Fd())))
+	assert.NoError(t, lf.Close())
+	stx, err = mount.Statx("/fuse-serve/moved/file", cephfs.StatxBasicStats, 0)
+	require.NoError(t, err)
+	assert.EqualValues(t, 4096, stx.Size)
+
+	require.NoError(t, os.Symlink("file", filepath.Join(dir, "moved", "link")))
+	target, err := os.Readlink(filepath.Join(dir, "moved", "link"))
+	assert.NoError(t, err)
+	assert.Equal(t, "file", target)
+
+	f, err := os.Open(filepath.Join(dir, "moved"))
+	require.NoError(t, err)
+	names, err := f.Readdirnames(-1)
+	f.Close()
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"file", "link"}, names)
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "moved", "link")))
+	require.NoError(t, os.Remove(name))
+	_, err = mount.Statx("/fuse-serve/moved/file", cephfs.StatxBasicStats, 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,201 @@
+// +build !luminous
+
+package fuse
+
+import (
+	"context"
+	"io"
+	"sync"
+	"syscall"
+
+	"github.com/hanwen/go-fuse/v2/fs"
+	"github.com/hanwen/go-fuse/v2/fuse"
+
+	"github.com/ceph/go-ceph/cephfs"
+)
+
+// fuseFsyncFdatasync is the FUSE_FSYNC_FDATASYNC flag of a fsync request,
+// set when only the data of the file needs to be synchronized.
+const fuseFsyncFdatasync = 1 << 0
+
+// fileHandle is an open file of the file system.
+type fileHandle struct {
+	mount *cephfs.MountInfo
+	file  *cephfs.File
+	flags int
+
+	// mu protects lk.
+	mu sync.Mutex
+	// lk is a low level handle of the same file, which holds the POSIX
+	// locks taken through this handle. It is opened on the first lock
+	// request, as byte-range locks are only available through the low
+	// level API.
+	lk *cephfs.FileHandle
+}
+
+var (
+	_ fs.FileReader    = (*fileHandle)(nil)
+	_ fs.FileWriter    = (*fileHandle)(nil)
+	_ fs.FileFsyncer   = (*fileHandle)(nil)
+	_ fs.FileAllocater = (*fileHandle)(nil)
+	_ fs.FileGetlker   = (*fileHandle)(nil)
+	_ fs.FileSetlker   = (*fileHandle)(nil)
+	_ fs.FileSetlkwer  = (*fileHandle)(nil)
+	_ fs.FileReleaser  = (*fileHandle)(nil)
+)
+
+func newFileHandle(mount *cephfs.MountInfo, f *cephfs.File, flags int) *fileHandle {
+	return &fileHandle{mount: mount, file: f, flags: flags}
+}
+
+// Read reads data from the file at the given offset.
+func (h *fileHandle) Read(
+	ctx context.Context, dest []byte, off int64) (fuse.ReadResult, syscall.Errno) {
+
+	n, err := h.file.ReadAt(dest, off)
+	if err != nil && err != io.EOF {
+		return nil, toErrno(err)
+	}
+	return fuse.ReadResultData(dest[:n]), 0
+}
+
+// Write writes data to the file at the given offset.
+func (h *fileHandle) Write(
+	ctx context.Context, data []byte, off int64) (uint32, syscall.Errno) {
+
+	n, err := h.file.WriteAt(data, off)
+	if err != nil {
+		return 0, toErrno(err)
+	}
+	return uint32(n), 0
+}
+
+// Fsync synchronizes the file with the cluster, only its data if the
+// request asks for fdatasync.
+func (h *fileHandle) Fsync(ctx context.Context, flags uint32) syscall.Errno {
+	sync := cephfs.SyncAll
+	if flags&fuseFsyncFdatasync != 0 {
+		sync = cephfs.SyncDataOnly
+	}
+	return toErrno(h.file.Fsync(sync))
+}
+
+// Allocate preallocates, or with the punch hole mode releases, the space of
+// the given range of the file.
+func (h *fileHandle) Allocate(
+	ctx context.Context, off, size uint64, mode uint32) syscall.Errno {
+
+	err := h.file.Fallocate(cephfs.FallocFlags(mode), int64(off), int64(size))
+	return toErrno(err)
+}
+
+// lockHandle returns the low level handle of the file used for POSIX locks.
+// The handle is opened by the inode number of the open file, so that it
+// refers to the same file even if the file has been renamed or removed.
+func (h *fileHandle) lockHandle() (*cephfs.FileHandle, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.lk != nil {
+		return h.lk, nil
+	}
+	stx, err := h.file.Fstatx(cephfs.StatxIno, 0)
+	if err != nil {
+		return nil, err
+	}
+	in, err := h.mount.LookupInode(stx.Inode)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Release()
+	lk, err := in.Open(h.flags&syscall.O_ACCMODE, nil)
+	if err != nil {
+		return nil, err
+	}
+	h.lk = lk
+	return lk, nil
+}
+
+// flock applies a flock lock request to the file.
+func (h *fileHandle) flock(owner uint64, lk *fuse.FileLock, wait bool) syscall.Errno {
+	var op cephfs.LockOp
+	switch lk.Typ {
+	case syscall.F_RDLCK:
+		op = cephfs.LockSH
+	case syscall.F_WRLCK:
+		op = cephfs.LockEX
+	case syscall.F_UNLCK:
+		op = cephfs.LockUN
+	default:
+		return syscall.EINVAL
+	}
+	if !wait {
+		op |= cephfs.LockNB
+	}
+	return toErrno(h.file.Flock(op, owner))
+}
+
+// setlk applies a flock or POSIX lock request to the file. Waiting for a
+// conflicting lock is done by libcephfs and is not interrupted when the
+// request is canceled.
+func (h *fileHandle) setlk(
+	owner uint64, lk *fuse.FileLock, flags uint32, wait bool) syscall.Errno {
+
+	if flags&fuse.FUSE_LK_FLOCK != 0 {
+		return h.flock(owner, lk, wait)
+	}
+	fh, err := h.lockHandle()
+	if err != nil {
+		return toErrno(err)
+	}
+	return toErrno(fh.SetLk(toCephLock(lk), owner, wait))
+}
+
+// Getlk returns the first lock that conflicts with the POSIX lock lk, or a
+// lock of type F_UNLCK if there is none.
+func (h *fileHandle) Getlk(
+	ctx context.Context, owner uint64, lk *fuse.FileLock,
+	flags uint32, out *fuse.FileLock) syscall.Errno {
+
+	fh, err := h.lockHandle()
+	if err != nil {
+		return toErrno(err)
+	}
+	lock, err := fh.GetLk(toCephLock(lk), owner)
+	if err != nil {
+		return toErrno(err)
+	}
+	fromCephLock(lock, out)
+	return 0
+}
+
+// Setlk acquires or releases a lock on the file, or fails with EAGAIN if a
+// conflicting lock is held by another owner.
+func (h *fileHandle) Setlk(
+	ctx context.Context, owner uint64, lk *fuse.FileLock, flags uint32) syscall.Errno {
+
+	return h.setlk(owner, lk, flags, false)
+}
+
+// Setlkw acquires a lock on the file, waiting for conflicting locks to be
+// released.
+func (h *fileHandle) Setlkw(
+	ctx context.Context, owner uint64, lk *fuse.FileLock, flags uint32) syscall.Errno {
+
+	return h.setlk(owner, lk, flags, true)
+}
+
+// Release closes the file. Closing the cephfs file and its low level handle
+// releases the flock and POSIX locks still held through them.
+func (h *fileHandle) Release(ctx context.Context) syscall.Errno {
+	var err error
+	h.mu.Lock()
+	if h.lk != nil {
+		err = h.lk.Close()
+		h.lk = nil
+	}
+	h.mu.Unlock()
+	if cerr := h.file.Close(); err == nil {
+		err = cerr
+	}
+	return toErrno(err)
+}
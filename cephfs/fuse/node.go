@@ -0,0 +1,368 @@
+// +build !luminous
+
+package fuse
+
+import (
+	"context"
+	"os"
+	"path"
+	"syscall"
+
+	"github.com/hanwen/go-fuse/v2/fs"
+	"github.com/hanwen/go-fuse/v2/fuse"
+
+	"github.com/ceph/go-ceph/cephfs"
+)
+
+// node is a file, directory or other entry of the file system. The FUSE
+// library keeps track of the tree of nodes known to the kernel, including
+// renames, and the node is found in the mount by its path in that tree.
+type node struct {
+	fs.Inode
+	fsys *FS
+}
+
+var (
+	_ fs.NodeGetattrer     = (*node)(nil)
+	_ fs.NodeSetattrer     = (*node)(nil)
+	_ fs.NodeStatfser      = (*node)(nil)
+	_ fs.NodeLookuper      = (*node)(nil)
+	_ fs.NodeReaddirer     = (*node)(nil)
+	_ fs.NodeOpener        = (*node)(nil)
+	_ fs.NodeCreater       = (*node)(nil)
+	_ fs.NodeMkdirer       = (*node)(nil)
+	_ fs.NodeUnlinker      = (*node)(nil)
+	_ fs.NodeRmdirer       = (*node)(nil)
+	_ fs.NodeRenamer       = (*node)(nil)
+	_ fs.NodeSymlinker     = (*node)(nil)
+	_ fs.NodeReadlinker    = (*node)(nil)
+	_ fs.NodeLinker        = (*node)(nil)
+	_ fs.NodeFsyncer       = (*node)(nil)
+	_ fs.NodeSetlker       = (*node)(nil)
+	_ fs.NodeSetlkwer      = (*node)(nil)
+	_ fs.NodeGetxattrer    = (*node)(nil)
+	_ fs.NodeListxattrer   = (*node)(nil)
+	_ fs.NodeSetxattrer    = (*node)(nil)
+	_ fs.NodeRemovexattrer = (*node)(nil)
+)
+
+// fullPath returns the path of the node within the mount.
+func (n *node) fullPath() string {
+	return path.Join(n.fsys.root, n.Path(nil))
+}
+
+func (n *node) child(name string) string {
+	return path.Join(n.fullPath(), name)
+}
+
+func (n *node) mount() *cephfs.MountInfo {
+	return n.fsys.mount
+}
+
+// newChild returns the inode of the child entry with the given stat
+// information, and fills its FUSE attributes.
+func (n *node) newChild(
+	ctx context.Context, stx *cephfs.CephStatx, out *fuse.EntryOut) *fs.Inode {
+
+	fillAttr(&out.Attr, stx)
+	id := fs.StableAttr{
+		Mode: uint32(stx.Mode) & syscall.S_IFMT,
+		Ino:  uint64(stx.Inode),
+	}
+	return n.NewInode(ctx, &node{fsys: n.fsys}, id)
+}
+
+// lookupChild stats the named entry of the directory and returns its inode.
+func (n *node) lookupChild(
+	ctx context.Context, name string, out *fuse.EntryOut) (*fs.Inode, syscall.Errno) {
+
+	stx, err := n.mount().Statx(n.child(name), statxWant, cephfs.AtSymlinkNofollow)
+	if err != nil {
+		return nil, toErrno(err)
+	}
+	return n.newChild(ctx, stx, out), 0
+}
+
+// Getattr returns the attributes of the node. The attributes of an open file
+// are taken from the open file, which stays valid when the file is renamed
+// or removed.
+func (n *node) Getattr(ctx context.Context, f fs.FileHandle, out *fuse.AttrOut) syscall.Errno {
+	var (
+		stx *cephfs.CephStatx
+		err error
+	)
+	if h, ok := f.(*fileHandle); ok {
+		stx, err = h.file.Fstatx(statxWant, 0)
+	} else {
+		stx, err = n.mount().Statx(n.fullPath(), statxWant, cephfs.AtSymlinkNofollow)
+	}
+	if err != nil {
+		return toErrno(err)
+	}
+	fillAttr(&out.Attr, stx)
+	return 0
+}
+
+// Setattr changes the attributes of the node selected by the request.
+func (n *node) Setattr(
+	ctx context.Context, f fs.FileHandle, in *fuse.SetAttrIn, out *fuse.AttrOut) syscall.Errno {
+
+	var (
+		stx  cephfs.CephStatx
+		mask cephfs.SetattrMask
+	)
+	if mode, ok := in.GetMode(); ok {
+		stx.Mode = uint16(mode & 07777)
+		mask |= cephfs.SetattrMode
+	}
+	if uid, ok := in.GetUID(); ok {
+		stx.Uid = uid
+		mask |= cephfs.SetattrUid
+	}
+	if gid, ok := in.GetGID(); ok {
+		stx.Gid = gid
+		mask |= cephfs.SetattrGid
+	}
+	if size, ok := in.GetSize(); ok {
+		stx.Size = size
+		mask |= cephfs.SetattrSize
+	}
+	if atime, ok := in.GetATime(); ok {
+		stx.Atime = toTimespec(atime)
+		mask |= cephfs.SetattrAtime
+	}
+	if mtime, ok := in.GetMTime(); ok {
+		stx.Mtime = toTimespec(mtime)
+		mask |= cephfs.SetattrMtime
+	}
+	if mask != 0 {
+		err := n.mount().SetAttrx(n.fullPath(), &stx, mask, cephfs.AtSymlinkNofollow)
+		if err != nil {
+			return toErrno(err)
+		}
+	}
+	return n.Getattr(ctx, f, out)
+}
+
+// Statfs returns the file system statistics of the mount.
+func (n *node) Statfs(ctx context.Context, out *fuse.StatfsOut) syscall.Errno {
+	return n.fsys.statfs(out)
+}
+
+// Lookup returns the inode of the named entry of the directory.
+func (n *node) Lookup(
+	ctx context.Context, name string, out *fuse.EntryOut) (*fs.Inode, syscall.Errno) {
+
+	return n.lookupChild(ctx, name, out)
+}
+
+// Readdir returns the entries of the directory. The entry types are taken
+// from the stat information returned along with every entry.
+func (n *node) Readdir(ctx context.Context) (fs.DirStream, syscall.Errno) {
+	dir, err := n.mount().OpenDir(n.fullPath())
+	if err != nil {
+		return nil, toErrno(err)
+	}
+	defer dir.Close()
+
+	var entries []fuse.DirEntry
+	for {
+		entry, err := dir.ReadDirPlus(cephfs.StatxIno|cephfs.StatxMode, cephfs.AtSymlinkNofollow)
+		if err != nil {
+			return nil, toErrno(err)
+		}
+		if entry == nil {
+			break
+		}
+		name := entry.Name()
+		if name == "." || name == ".." {
+			continue
+		}
+		stx := entry.Statx()
+		entries = append(entries, fuse.DirEntry{
+			Mode: uint32(stx.Mode) & syscall.S_IFMT,
+			Name: name,
+			Ino:  uint64(stx.Inode),
+		})
+	}
+	return fs.NewListDirStream(entries), 0
+}
+
+// Open opens the file.
+func (n *node) Open(
+	ctx context.Context, flags uint32) (fs.FileHandle, uint32, syscall.Errno) {
+
+	f, err := n.mount().Open(n.fullPath(), int(flags), 0)
+	if err != nil {
+		return nil, 0, toErrno(err)
+	}
+	return newFileHandle(n.mount(), f, int(flags)), 0, 0
+}
+
+// Create creates and opens a file in the directory.
+func (n *node) Create(
+	ctx context.Context, name string, flags, mode uint32,
+	out *fuse.EntryOut) (*fs.Inode, fs.FileHandle, uint32, syscall.Errno) {
+
+	f, err := n.mount().Open(n.child(name), int(flags)|os.O_CREATE, mode&07777)
+	if err != nil {
+		return nil, nil, 0, toErrno(err)
+	}
+	stx, err := f.Fstatx(statxWant, 0)
+	if err != nil {
+		f.Close()
+		return nil, nil, 0, toErrno(err)
+	}
+	return n.newChild(ctx, stx, out), newFileHandle(n.mount(), f, int(flags)), 0, 0
+}
+
+// Mkdir creates a directory in the directory.
+func (n *node) Mkdir(
+	ctx context.Context, name string, mode uint32,
+	out *fuse.EntryOut) (*fs.Inode, syscall.Errno) {
+
+	if err := n.mount().MakeDir(n.child(name), mode&07777); err != nil {
+		return nil, toErrno(err)
+	}
+	return n.lookupChild(ctx, name, out)
+}
+
+// Unlink removes a file from the directory.
+func (n *node) Unlink(ctx context.Context, name string) syscall.Errno {
+	return toErrno(n.mount().Unlink(n.child(name)))
+}
+
+// Rmdir removes an empty directory from the directory.
+func (n *node) Rmdir(ctx context.Context, name string) syscall.Errno {
+	return toErrno(n.mount().RemoveDir(n.child(name)))
+}
+
+// Rename moves an entry of the directory to the directory newParent. The
+// flags of renameat2 are not supported.
+func (n *node) Rename(
+	ctx context.Context, name string, newParent fs.InodeEmbedder,
+	newName string, flags uint32) syscall.Errno {
+
+	if flags != 0 {
+		return syscall.EINVAL
+	}
+	np, ok := newParent.(*node)
+	if !ok {
+		return syscall.EXDEV
+	}
+	return toErrno(n.mount().Rename(n.child(name), np.child(newName)))
+}
+
+// Symlink creates a symbolic link in the directory.
+func (n *node) Symlink(
+	ctx context.Context, target, name string,
+	out *fuse.EntryOut) (*fs.Inode, syscall.Errno) {
+
+	if err := n.mount().Symlink(target, n.child(name)); err != nil {
+		return nil, toErrno(err)
+	}
+	return n.lookupChild(ctx, name, out)
+}
+
+// Readlink returns the target of the symbolic link.
+func (n *node) Readlink(ctx context.Context) ([]byte, syscall.Errno) {
+	target, err := n.mount().Readlink(n.fullPath())
+	if err != nil {
+		return nil, toErrno(err)
+	}
+	return []byte(target), 0
+}
+
+// Link creates a hard link to the node target in the directory.
+func (n *node) Link(
+	ctx context.Context, target fs.InodeEmbedder, name string,
+	out *fuse.EntryOut) (*fs.Inode, syscall.Errno) {
+
+	tn, ok := target.(*node)
+	if !ok {
+		return nil, syscall.EXDEV
+	}
+	if err := n.mount().Link(tn.fullPath(), n.child(name)); err != nil {
+		return nil, toErrno(err)
+	}
+	return n.lookupChild(ctx, name, out)
+}
+
+// Fsync synchronizes an open file through the handle the kernel passes
+// along. Directories have no open handle in this file system, syncing one
+// synchronizes the whole mount.
+func (n *node) Fsync(ctx context.Context, f fs.FileHandle, flags uint32) syscall.Errno {
+	if h, ok := f.(*fileHandle); ok {
+		return h.Fsync(ctx, flags)
+	}
+	return toErrno(n.mount().SyncFs())
+}
+
+// Setlk forwards the lock request to the open file. The FUSE library only
+// looks for the Setlk and Setlkw methods on the node.
+func (n *node) Setlk(
+	ctx context.Context, f fs.FileHandle, owner uint64,
+	lk *fuse.FileLock, flags uint32) syscall.Errno {
+
+	h, ok := f.(*fileHandle)
+	if !ok {
+		return syscall.EBADF
+	}
+	return h.Setlk(ctx, owner, lk, flags)
+}
+
+// Setlkw forwards the waiting lock request to the open file.
+func (n *node) Setlkw(
+	ctx context.Context, f fs.FileHandle, owner uint64,
+	lk *fuse.FileLock, flags uint32) syscall.Errno {
+
+	h, ok := f.(*fileHandle)
+	if !ok {
+		return syscall.EBADF
+	}
+	return h.Setlkw(ctx, owner, lk, flags)
+}
+
+// Getxattr returns the value of an extended attribute of the node.
+func (n *node) Getxattr(
+	ctx context.Context, attr string, dest []byte) (uint32, syscall.Errno) {
+
+	value, err := n.mount().LgetXattr(n.fullPath(), attr)
+	if err != nil {
+		return 0, toErrno(err)
+	}
+	if len(value) > len(dest) {
+		return uint32(len(value)), syscall.ERANGE
+	}
+	return uint32(copy(dest, value)), 0
+}
+
+// Listxattr returns the names of the extended attributes of the node, each
+// one terminated by a null byte.
+func (n *node) Listxattr(ctx context.Context, dest []byte) (uint32, syscall.Errno) {
+	names, err := n.mount().LlistXattr(n.fullPath())
+	if err != nil {
+		return 0, toErrno(err)
+	}
+	var buf []byte
+	for _, name := range names {
+		buf = append(append(buf, name...), 0)
+	}
+	if len(buf) > len(dest) {
+		return uint32(len(buf)), syscall.ERANGE
+	}
+	return uint32(copy(dest, buf)), 0
+}
+
+// Setxattr sets an extended attribute of the node.
+func (n *node) Setxattr(
+	ctx context.Context, attr string, data []byte, flags uint32) syscall.Errno {
+
+	err := n.mount().LsetXattr(n.fullPath(), attr, data, cephfs.XattrFlags(flags))
+	return toErrno(err)
+}
+
+// Removexattr removes an extended attribute of the node.
+func (n *node) Removexattr(ctx context.Context, attr string) syscall.Errno {
+	return toErrno(n.mount().LremoveXattr(n.fullPath(), attr))
+}
@@ -0,0 +1,92 @@
+// +build !luminous
+
+package fuse
+
+import (
+	"syscall"
+
+	"github.com/hanwen/go-fuse/v2/fs"
+	"github.com/hanwen/go-fuse/v2/fuse"
+
+	"github.com/ceph/go-ceph/cephfs"
+)
+
+// MountOptions control how the file system is mounted on the local host.
+type MountOptions struct {
+	// FSName is the name of the file system shown in the mount table. It
+	// defaults to "cephfs".
+	FSName string
+	// AllowOther allows users other than the one running the server to
+	// access the mount point.
+	AllowOther bool
+	// DefaultPermissions makes the kernel check the permission bits of the
+	// files before forwarding requests.
+	DefaultPermissions bool
+	// ReadOnly mounts the file system read-only.
+	ReadOnly bool
+	// Debug logs every FUSE request and response.
+	Debug bool
+}
+
+// Server serves a FS on a local mount point.
+type Server struct {
+	server *fuse.Server
+}
+
+// Mount mounts the file system on the local directory dir. The file system
+// is not served until Serve is called.
+func Mount(fsys *FS, dir string, opts *MountOptions) (*Server, error) {
+	if opts == nil {
+		opts = &MountOptions{}
+	}
+	stx, err := fsys.mount.Statx(fsys.root, cephfs.StatxMode, 0)
+	if err != nil {
+		return nil, err
+	}
+	if stx.Mode&syscall.S_IFMT != syscall.S_IFDIR {
+		return nil, syscall.ENOTDIR
+	}
+
+	fsName := opts.FSName
+	if fsName == "" {
+		fsName = "cephfs"
+	}
+	timeout := fsys.AttrValid
+	options := &fs.Options{
+		MountOptions: fuse.MountOptions{
+			FsName:      fsName,
+			Name:        "cephfs",
+			AllowOther:  opts.AllowOther,
+			EnableLocks: true,
+			Debug:       opts.Debug,
+		},
+		EntryTimeout: &timeout,
+		AttrTimeout:  &timeout,
+		// report the permission bits of the files as they are
+		NullPermissions: true,
+	}
+	if opts.DefaultPermissions {
+		options.Options = append(options.Options, "default_permissions")
+	}
+	if opts.ReadOnly {
+		options.Options = append(options.Options, "ro")
+	}
+	root := &node{fsys: fsys}
+	server, err := fuse.NewServer(fs.NewNodeFS(root, options), dir, &options.MountOptions)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{server: server}, nil
+}
+
+// Serve serves the FUSE requests of the mount point. It returns once the
+// file system has been unmounted.
+func (s *Server) Serve() {
+	s.server.Serve()
+}
+
+// Unmount unmounts the file system from the mount point, which makes Serve
+// return.
+func (s *Server) Unmount() error {
+	return s.server.Unmount()
+}
@@ -0,0 +1,130 @@
+package cephfs
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+const (
+	dirLayoutXattr  = "ceph.dir.layout"
+	fileLayoutXattr = "ceph.file.layout"
+)
+
+// ErrNoLayout may be returned by GetLayout if a directory does not have a
+// layout of its own. The files created in such a directory use the layout of
+// the closest parent directory that has one, or the default layout of the
+// file system.
+var ErrNoLayout = errors.New("directory has no layout")
+
+// Layout describes how the data of a file is striped over RADOS objects.
+type Layout struct {
+	// Pool is the name of the data pool that holds the objects.
+	Pool string
+	// PoolNamespace is the RADOS namespace within the pool.
+	PoolNamespace string
+	// StripeUnit is the size, in bytes, of the blocks the data is striped
+	// in.
+	StripeUnit uint64
+	// StripeCount is the number of objects a stripe is spread over.
+	StripeCount uint64
+	// ObjectSize is the size, in bytes, of the objects.
+	ObjectSize uint64
+}
+
+// layoutXattr returns the name of the layout xattr of the file or directory
+// at path.
+func (mount *MountInfo) layoutXattr(path string) (string, error) {
+	stx, err := mount.Statx(path, StatxMode, 0)
+	if err != nil {
+		return "", err
+	}
+	if stx.isDir() {
+		return dirLayoutXattr, nil
+	}
+	return fileLayoutXattr, nil
+}
+
+// GetLayout returns the layout of the file or directory at the given path.
+// Directories only have a layout if one was set on them explicitly,
+// otherwise ErrNoLayout is returned.
+func (mount *MountInfo) GetLayout(path string) (*Layout, error) {
+	prefix, err := mount.layoutXattr(path)
+	if err != nil {
+		return nil, err
+	}
+	value, err := mount.getXattrString(path, prefix)
+	if err == errNoData {
+		return nil, ErrNoLayout
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseLayout(value)
+}
+
+// parseLayout parses the value of a layout xattr, a space separated list of
+// key=value pairs.
+func parseLayout(value string) (*Layout, error) {
+	l := &Layout{}
+	for _, field := range strings.Fields(value) {
+		kv := strings.SplitN(field, "=", 2)
+		if len(kv) != 2 {
+			return nil, errInvalid
+		}
+		var err error
+		switch kv[0] {
+		case "pool":
+			l.Pool = kv[1]
+		case "pool_namespace":
+			l.PoolNamespace = kv[1]
+		case "stripe_unit":
+			l.StripeUnit, err = strconv.ParseUint(kv[1], 10, 64)
+		case "stripe_count":
+			l.StripeCount, err = strconv.ParseUint(kv[1], 10, 64)
+		case "object_size":
+			l.ObjectSize, err = strconv.ParseUint(kv[1], 10, 64)
+		}
+		if err != nil {
+			return nil, errInvalid
+		}
+	}
+	return l, nil
+}
+
+// String returns the layout in the format of the layout xattrs. Fields with
+// a zero value are left out.
+func (l *Layout) String() string {
+	var fields []string
+	add := func(key string, v uint64) {
+		if v != 0 {
+			fields = append(fields, key+"="+strconv.FormatUint(v, 10))
+		}
+	}
+	add("stripe_unit", l.StripeUnit)
+	add("stripe_count", l.StripeCount)
+	add("object_size", l.ObjectSize)
+	if l.Pool != "" {
+		fields = append(fields, "pool="+l.Pool)
+	}
+	if l.PoolNamespace != "" {
+		fields = append(fields, "pool_namespace="+l.PoolNamespace)
+	}
+	return strings.Join(fields, " ")
+}
+
+// SetLayout sets the layout of the file or directory at the given path. All
+// the fields of the layout are set at once, fields with a zero value keep
+// their current, or inherited, value. The layout of a file can only be
+// changed while the file is empty.
+func (mount *MountInfo) SetLayout(path string, layout *Layout) error {
+	name, err := mount.layoutXattr(path)
+	if err != nil {
+		return err
+	}
+	value := layout.String()
+	if value == "" {
+		return errInvalid
+	}
+	return mount.SetXattr(path, name, []byte(value), XattrDefault)
+}
@@ -0,0 +1,87 @@
+package cephfs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLayoutString(t *testing.T) {
+	l, err := parseLayout(
+		"stripe_unit=4194304 stripe_count=1 object_size=4194304 pool=cephfs_data pool_namespace=ns1")
+	require.NoError(t, err)
+	assert.Equal(t, &Layout{
+		Pool:          "cephfs_data",
+		PoolNamespace: "ns1",
+		StripeUnit:    4194304,
+		StripeCount:   1,
+		ObjectSize:    4194304,
+	}, l)
+	assert.Equal(t,
+		"stripe_unit=4194304 stripe_count=1 object_size=4194304 pool=cephfs_data pool_namespace=ns1",
+		l.String())
+
+	l = &Layout{StripeCount: 2}
+	assert.Equal(t, "stripe_count=2", l.String())
+
+	_, err = parseLayout("stripe_unit=big")
+	assert.Equal(t, errInvalid, err)
+	_, err = parseLayout("pool")
+	assert.Equal(t, errInvalid, err)
+}
+
+func TestLayout(t *testing.T) {
+	mount := fsConnect(t)
+	defer fsDisconnect(t, mount)
+
+	dir := "/layoutdir"
+	require.NoError(t, mount.MakeDir(dir, 0755))
+	defer func() { assert.NoError(t, mount.RemoveAll(dir)) }()
+
+	_, err := mount.GetLayout(dir)
+	assert.Equal(t, ErrNoLayout, err)
+
+	fname := dir + "/file"
+	writeTestFile(t, mount, fname, "", 0644)
+	fl, err := mount.GetLayout(fname)
+	require.NoError(t, err)
+	assert.NotEqual(t, "", fl.Pool)
+	assert.NotZero(t, fl.ObjectSize)
+
+	err = mount.SetLayout(dir, &Layout{
+		Pool:        fl.Pool,
+		StripeUnit:  1 << 20,
+		StripeCount: 2,
+		ObjectSize:  1 << 22,
+	})
+	require.NoError(t, err)
+	dl, err := mount.GetLayout(dir)
+	require.NoError(t, err)
+	assert.Equal(t, fl.Pool, dl.Pool)
+	assert.Equal(t, uint64(1<<20), dl.StripeUnit)
+	assert.Equal(t, uint64(2), dl.StripeCount)
+	assert.Equal(t, uint64(1<<22), dl.ObjectSize)
+
+	// new files inherit the layout of the directory
+	writeTestFile(t, mount, dir+"/new", "", 0644)
+	nl, err := mount.GetLayout(dir + "/new")
+	assert.NoError(t, err)
+	assert.Equal(t, dl, nl)
+
+	// the layout of an empty file can be changed
+	err = mount.SetLayout(fname, &Layout{StripeCount: 4, StripeUnit: 1 << 20})
+	assert.NoError(t, err)
+	fl, err = mount.GetLayout(fname)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4), fl.StripeCount)
+
+	writeTestFile(t, mount, fname, "data", 0644)
+	err = mount.SetLayout(fname, &Layout{StripeCount: 1})
+	assert.Error(t, err)
+
+	err = mount.SetLayout(fname, &Layout{})
+	assert.Equal(t, errInvalid, err)
+	_, err = mount.GetLayout("/no.such.file")
+	assert.Equal(t, errNoEntry, err)
+}
@@ -0,0 +1,224 @@
+// +build !luminous
+//
+// ceph_mount_perms available in mimic & later
+
+package cephfs
+
+/*
+#cgo LDFLAGS: -lcephfs
+#cgo CPPFLAGS: -D_FILE_OFFSET_BITS=64
+#define _GNU_SOURCE
+#include <stdlib.h>
+#include <fcntl.h>
+#include <cephfs/libcephfs.h>
+*/
+import "C"
+
+import (
+	"io"
+	"unsafe"
+)
+
+// FileHandle represents a file opened with the low level API. Unlike File a
+// FileHandle does not use a file descriptor of the client.
+type FileHandle struct {
+	mount *MountInfo
+	fh    *C.struct_Fh
+	// delegCB is the index of the delegation recall callback, if any
+	delegCB uintptr
+}
+
+func (fh *FileHandle) validate() error {
+	if fh.fh == nil {
+		return errBadFile
+	}
+	return fh.mount.validate()
+}
+
+// Open opens the file inode with the given flags, the same os flags as a
+// local open call.
+//
+// Implements:
+//  int ceph_ll_open(struct ceph_mount_info *cmount, struct Inode *in, int flags,
+//                   struct Fh **fh, const UserPerm *perms);
+func (in *InodeRef) Open(flags int, perm *UserPerm) (*FileHandle, error) {
+	if err := in.validate(); err != nil {
+		return nil, err
+	}
+	var fh *C.struct_Fh
+	ret := C.ceph_ll_open(in.mount.mount, in.inode, C.int(flags), &fh, in.mount.cPerms(perm))
+	if err := getError(ret); err != nil {
+		return nil, err
+	}
+	return &FileHandle{mount: in.mount, fh: fh}, nil
+}
+
+// Create creates and opens a file with the given name and mode in the
+// directory inode. It returns a reference on the new inode, the open file
+// and the stat information of the file.
+//
+// Implements:
+//  int ceph_ll_create(struct ceph_mount_info *cmount, Inode *parent, const char *name,
+//                     mode_t mode, int oflags, Inode **outp, Fh **fhp,
+//                     struct ceph_statx *stx, unsigned want, unsigned lflags,
+//                     const UserPerm *perms);
+func (in *InodeRef) Create(
+	name string, mode uint32, oflags int, want StatxMask, flags AtFlags,
+	perm *UserPerm) (*InodeRef, *FileHandle, *CephStatx, error) {
+
+	if err := in.validate(); err != nil {
+		return nil, nil, nil, err
+	}
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+
+	var (
+		inode *C.struct_Inode
+		fh    *C.struct_Fh
+		stx   C.struct_ceph_statx
+	)
+	ret := C.ceph_ll_create(
+		in.mount.mount, in.inode, cName, C.mode_t(mode), C.int(oflags),
+		&inode, &fh, &stx, C.uint(want), C.uint(flags), in.mount.cPerms(perm))
+	if err := getError(ret); err != nil {
+		return nil, nil, nil, err
+	}
+	return in.mount.newInodeRef(inode),
+		&FileHandle{mount: in.mount, fh: fh},
+		cStructToCephStatx(stx),
+		nil
+}
+
+// Close closes the file handle. Calling Close on an already closed
+// FileHandle does nothing.
+//
+// Implements:
+//  int ceph_ll_close(struct ceph_mount_info *cmount, struct Fh* filehandle);
+func (fh *FileHandle) Close() error {
+	if fh.fh == nil {
+		// already closed
+		return nil
+	}
+	if err := fh.mount.validate(); err != nil {
+		return err
+	}
+	if err := getError(C.ceph_ll_close(fh.mount.mount, fh.fh)); err != nil {
+		return err
+	}
+	fh.releaseDelegationCallback()
+	fh.fh = nil
+	return nil
+}
+
+// ReadAt reads up to len(buf) bytes from the file starting at the given
+// offset. When nothing is left to read from the file, ReadAt returns 0,
+// io.EOF.
+//
+// Implements:
+//  int ceph_ll_read(struct ceph_mount_info *cmount, struct Fh* filehandle,
+//                   int64_t off, uint64_t len, char* buf);
+func (fh *FileHandle) ReadAt(buf []byte, offset int64) (int, error) {
+	if err := fh.validate(); err != nil {
+		return 0, err
+	}
+	if offset < 0 {
+		return 0, errInvalid
+	}
+	if len(buf) == 0 {
+		return 0, nil
+	}
+	ret := C.ceph_ll_read(
+		fh.mount.mount, fh.fh, C.int64_t(offset), C.uint64_t(len(buf)),
+		(*C.char)(unsafe.Pointer(&buf[0])))
+	switch {
+	case ret < 0:
+		return 0, getError(ret)
+	case ret == 0:
+		return 0, io.EOF
+	}
+	return int(ret), nil
+}
+
+// WriteAt writes the data in buf to the file starting at the given offset.
+// The number of bytes written is returned.
+//
+// Implements:
+//  int ceph_ll_write(struct ceph_mount_info *cmount, struct Fh* filehandle,
+//                    int64_t off, uint64_t len, const char *data);
+func (fh *FileHandle) WriteAt(buf []byte, offset int64) (int, error) {
+	if err := fh.validate(); err != nil {
+		return 0, err
+	}
+	if offset < 0 {
+		return 0, errInvalid
+	}
+	if len(buf) == 0 {
+		return 0, nil
+	}
+	ret := C.ceph_ll_write(
+		fh.mount.mount, fh.fh, C.int64_t(offset), C.uint64_t(len(buf)),
+		(*C.char)(unsafe.Pointer(&buf[0])))
+	if ret < 0 {
+		return 0, getError(ret)
+	}
+	return int(ret), nil
+}
+
+// Seek repositions the file handle based on the given offset.
+//
+// Implements:
+//  int64_t ceph_ll_lseek(struct ceph_mount_info *cmount, struct Fh* filehandle,
+//                        int64_t offset, int whence);
+func (fh *FileHandle) Seek(offset int64, whence int) (int64, error) {
+	if err := fh.validate(); err != nil {
+		return 0, err
+	}
+	switch whence {
+	case SeekSet, SeekCur, SeekEnd:
+	default:
+		return 0, errInvalid
+	}
+	ret := C.ceph_ll_lseek(fh.mount.mount, fh.fh, C.int64_t(offset), C.int(whence))
+	if ret < 0 {
+		return 0, getError(C.int(ret))
+	}
+	return int64(ret), nil
+}
+
+// Fsync ensures the file content that may be cached is committed to stable
+// storage.
+//
+// Implements:
+//  int ceph_ll_fsync(struct ceph_mount_info *cmount, struct Fh *fh, int syncdataonly);
+func (fh *FileHandle) Fsync(sync SyncChoice) error {
+	if err := fh.validate(); err != nil {
+		return err
+	}
+	return getError(C.ceph_ll_fsync(fh.mount.mount, fh.fh, C.int(sync)))
+}
+
+// Flush writes the data buffered for the file handle to the cluster.
+//
+// Implements:
+//  int ceph_ll_flush(struct ceph_mount_info *cmount, struct Fh *filehandle);
+func (fh *FileHandle) Flush() error {
+	if err := fh.validate(); err != nil {
+		return err
+	}
+	return getError(C.ceph_ll_flush(fh.mount.mount, fh.fh))
+}
+
+// Fallocate preallocates or releases disk space for the given byte range of
+// the file, the flags determine the operation to be performed.
+//
+// Implements:
+//  int ceph_ll_fallocate(struct ceph_mount_info *cmount, struct Fh *fh, int mode,
+//                        int64_t offset, int64_t length);
+func (fh *FileHandle) Fallocate(mode FallocFlags, offset, length int64) error {
+	if err := fh.validate(); err != nil {
+		return err
+	}
+	ret := C.ceph_ll_fallocate(
+		fh.mount.mount, fh.fh, C.int(mode), C.int64_t(offset), C.int64_t(length))
+	return getError(ret)
+}
@@ -0,0 +1,583 @@
+// +build !luminous
+//
+// ceph_mount_perms available in mimic & later
+
+package cephfs
+
+/*
+#cgo LDFLAGS: -lcephfs
+#cgo CPPFLAGS: -D_FILE_OFFSET_BITS=64
+#define _GNU_SOURCE
+#include <stdlib.h>
+#include <dirent.h>
+#include <cephfs/libcephfs.h>
+*/
+import "C"
+
+import (
+	"unsafe"
+
+	"github.com/ceph/go-ceph/internal/cutil"
+	"github.com/ceph/go-ceph/internal/retry"
+)
+
+// InodeRef is a referenced handle on an inode of the file system, as used by
+// the low level (ceph_ll_*) API. Operations on an InodeRef do not resolve
+// paths, which makes the low level API suitable to build file system
+// gateways on.
+//
+// Every InodeRef returned by the functions of the low level API holds a
+// reference on the inode that keeps it cached by the client. The reference
+// must be dropped with Release, or Forget, once the InodeRef is no longer
+// needed. An InodeRef can not be used after its reference was dropped.
+type InodeRef struct {
+	mount *MountInfo
+	inode *C.struct_Inode
+}
+
+// cPerms returns the C UserPerm for perm. If perm is nil the credentials of
+// the mount are used.
+//
+// Implements:
+//  UserPerm *ceph_mount_perms(struct ceph_mount_info *cmount);
+func (mount *MountInfo) cPerms(perm *UserPerm) *C.UserPerm {
+	if perm == nil {
+		return C.ceph_mount_perms(mount.mount)
+	}
+	return perm.userPerm
+}
+
+func (mount *MountInfo) newInodeRef(inode *C.struct_Inode) *InodeRef {
+	return &InodeRef{mount: mount, inode: inode}
+}
+
+func (in *InodeRef) validate() error {
+	if in.inode == nil {
+		return ErrInodeReleased
+	}
+	return in.mount.validate()
+}
+
+// LookupRoot returns a reference on the root inode of the mount.
+//
+// Implements:
+//  int ceph_ll_lookup_root(struct ceph_mount_info *cmount, Inode **parent);
+func (mount *MountInfo) LookupRoot() (*InodeRef, error) {
+	if err := mount.validate(); err != nil {
+		return nil, err
+	}
+	var inode *C.struct_Inode
+	ret := C.ceph_ll_lookup_root(mount.mount, &inode)
+	if err := getError(ret); err != nil {
+		return nil, err
+	}
+	return mount.newInodeRef(inode), nil
+}
+
+// LookupInode returns a reference on the inode with the given inode number.
+//
+// Implements:
+//  int ceph_ll_lookup_inode(struct ceph_mount_info *cmount, inodeno_t ino, Inode **inode);
+func (mount *MountInfo) LookupInode(ino Inode) (*InodeRef, error) {
+	if err := mount.validate(); err != nil {
+		return nil, err
+	}
+	var inode *C.struct_Inode
+	ret := C.ceph_ll_lookup_inode(mount.mount, C.inodeno_t(ino), &inode)
+	if err := getError(ret); err != nil {
+		return nil, err
+	}
+	return mount.newInodeRef(inode), nil
+}
+
+// LookupPath returns a reference on the inode at the given path, along with
+// its stat information. See Statx for a description of the want and flags
+// parameters. If perm is nil the credentials of the mount are used.
+//
+// Implements:
+//  int ceph_ll_walk(struct ceph_mount_info *cmount, const char* name, Inode **i,
+//                   struct ceph_statx *stx, unsigned int want, unsigned int flags,
+//                   const UserPerm *perms);
+func (mount *MountInfo) LookupPath(
+	path string, want StatxMask, flags AtFlags, perm *UserPerm) (*InodeRef, *CephStatx, error) {
+
+	if err := mount.validate(); err != nil {
+		return nil, nil, err
+	}
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+
+	var (
+		inode *C.struct_Inode
+		stx   C.struct_ceph_statx
+	)
+	ret := C.ceph_ll_walk(
+		mount.mount, cPath, &inode, &stx, C.uint(want), C.uint(flags),
+		mount.cPerms(perm))
+	if err := getError(ret); err != nil {
+		return nil, nil, err
+	}
+	return mount.newInodeRef(inode), cStructToCephStatx(stx), nil
+}
+
+// Ino returns the inode number of the inode.
+//
+// Implements:
+//  uint64_t ceph_ll_get_inode_ino(struct ceph_mount_info *cmount, Inode *in);
+func (in *InodeRef) Ino() (Inode, error) {
+	if err := in.validate(); err != nil {
+		return 0, err
+	}
+	return Inode(C.ceph_ll_get_inode_ino(in.mount.mount, in.inode)), nil
+}
+
+// Release drops the reference held by the InodeRef. Calling Release on an
+// already released InodeRef does nothing.
+//
+// Implements:
+//  int ceph_ll_put(struct ceph_mount_info *cmount, struct Inode *in);
+func (in *InodeRef) Release() error {
+	if in.inode == nil {
+		return nil
+	}
+	if err := in.mount.validate(); err != nil {
+		return err
+	}
+	if err := getError(C.ceph_ll_put(in.mount.mount, in.inode)); err != nil {
+		return err
+	}
+	in.inode = nil
+	return nil
+}
+
+// Forget drops count references on the inode at once. This matches the
+// semantics of FUSE forget requests, where a single request drops all the
+// references taken by lookups of the same inode. All the InodeRef values
+// sharing the inode must not be used afterwards, the InodeRef on which
+// Forget is called is marked as released.
+//
+// Implements:
+//  int ceph_ll_forget(struct ceph_mount_info *cmount, struct Inode *in, int count);
+func (in *InodeRef) Forget(count int) error {
+	if err := in.validate(); err != nil {
+		return err
+	}
+	if err := getError(C.ceph_ll_forget(in.mount.mount, in.inode, C.int(count))); err != nil {
+		return err
+	}
+	in.inode = nil
+	return nil
+}
+
+// Lookup returns a reference on the entry with the given name of the
+// directory inode, along with its stat information.
+//
+// Implements:
+//  int ceph_ll_lookup(struct ceph_mount_info *cmount, Inode *parent, const char *name,
+//                     Inode **out, struct ceph_statx *stx, unsigned want, unsigned flags,
+//                     const UserPerm *perms);
+func (in *InodeRef) Lookup(
+	name string, want StatxMask, flags AtFlags, perm *UserPerm) (*InodeRef, *CephStatx, error) {
+
+	if err := in.validate(); err != nil {
+		return nil, nil, err
+	}
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+
+	var (
+		inode *C.struct_Inode
+		stx   C.struct_ceph_statx
+	)
+	ret := C.ceph_ll_lookup(
+		in.mount.mount, in.inode, cName, &inode, &stx, C.uint(want),
+		C.uint(flags), in.mount.cPerms(perm))
+	if err := getError(ret); err != nil {
+		return nil, nil, err
+	}
+	return in.mount.newInodeRef(inode), cStructToCephStatx(stx), nil
+}
+
+// Getattr returns the stat information of the inode.
+//
+// Implements:
+//  int ceph_ll_getattr(struct ceph_mount_info *cmount, struct Inode *in,
+//                      struct ceph_statx *stx, unsigned int want, unsigned int flags,
+//                      const UserPerm *perms);
+func (in *InodeRef) Getattr(want StatxMask, flags AtFlags, perm *UserPerm) (*CephStatx, error) {
+	if err := in.validate(); err != nil {
+		return nil, err
+	}
+	var stx C.struct_ceph_statx
+	ret := C.ceph_ll_getattr(
+		in.mount.mount, in.inode, &stx, C.uint(want), C.uint(flags),
+		in.mount.cPerms(perm))
+	if err := getError(ret); err != nil {
+		return nil, err
+	}
+	return cStructToCephStatx(stx), nil
+}
+
+// Setattr applies the fields of stx selected by mask to the inode.
+//
+// Implements:
+//  int ceph_ll_setattr(struct ceph_mount_info *cmount, struct Inode *in,
+//                      struct ceph_statx *stx, int mask, const UserPerm *perms);
+func (in *InodeRef) Setattr(stx *CephStatx, mask SetattrMask, perm *UserPerm) error {
+	if err := in.validate(); err != nil {
+		return err
+	}
+	cStx := stx.toCStruct()
+	ret := C.ceph_ll_setattr(
+		in.mount.mount, in.inode, &cStx, C.int(mask), in.mount.cPerms(perm))
+	return getError(ret)
+}
+
+// Mkdir creates a directory with the given name and mode in the directory
+// inode and returns a reference on the new directory.
+//
+// Implements:
+//  int ceph_ll_mkdir(struct ceph_mount_info *cmount, Inode *parent, const char *name,
+//                    mode_t mode, Inode **out, struct ceph_statx *stx, unsigned want,
+//                    unsigned flags, const UserPerm *perms);
+func (in *InodeRef) Mkdir(
+	name string, mode uint32, want StatxMask, flags AtFlags,
+	perm *UserPerm) (*InodeRef, *CephStatx, error) {
+
+	if err := in.validate(); err != nil {
+		return nil, nil, err
+	}
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+
+	var (
+		inode *C.struct_Inode
+		stx   C.struct_ceph_statx
+	)
+	ret := C.ceph_ll_mkdir(
+		in.mount.mount, in.inode, cName, C.mode_t(mode), &inode, &stx,
+		C.uint(want), C.uint(flags), in.mount.cPerms(perm))
+	if err := getError(ret); err != nil {
+		return nil, nil, err
+	}
+	return in.mount.newInodeRef(inode), cStructToCephStatx(stx), nil
+}
+
+// Symlink creates a symbolic link with the given name in the directory inode
+// that points to target, and returns a reference on the new link.
+//
+// Implements:
+//  int ceph_ll_symlink(struct ceph_mount_info *cmount, Inode *in, const char *name,
+//                      const char *value, Inode **out, struct ceph_statx *stx,
+//                      unsigned want, unsigned flags, const UserPerm *perms);
+func (in *InodeRef) Symlink(
+	name, target string, want StatxMask, flags AtFlags,
+	perm *UserPerm) (*InodeRef, *CephStatx, error) {
+
+	if err := in.validate(); err != nil {
+		return nil, nil, err
+	}
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+	cTarget := C.CString(target)
+	defer C.free(unsafe.Pointer(cTarget))
+
+	var (
+		inode *C.struct_Inode
+		stx   C.struct_ceph_statx
+	)
+	ret := C.ceph_ll_symlink(
+		in.mount.mount, in.inode, cName, cTarget, &inode, &stx,
+		C.uint(want), C.uint(flags), in.mount.cPerms(perm))
+	if err := getError(ret); err != nil {
+		return nil, nil, err
+	}
+	return in.mount.newInodeRef(inode), cStructToCephStatx(stx), nil
+}
+
+// Readlink returns the target of the symbolic link inode.
+//
+// Implements:
+//  int ceph_ll_readlink(struct ceph_mount_info *cmount, struct Inode *in, char *buf,
+//                       size_t bufsize, const UserPerm *perms);
+func (in *InodeRef) Readlink(perm *UserPerm) (string, error) {
+	if err := in.validate(); err != nil {
+		return "", err
+	}
+	var (
+		ret C.int
+		buf []byte
+	)
+	retry.WithSizes(4096, 1<<16, func(size int) retry.Hint {
+		buf = make([]byte, size)
+		ret = C.ceph_ll_readlink(
+			in.mount.mount, in.inode, (*C.char)(unsafe.Pointer(&buf[0])),
+			C.size_t(size), in.mount.cPerms(perm))
+		// a result filling the whole buffer may be truncated
+		return retry.DoubleSize.If(int(ret) == size)
+	})
+	if err := getErrorIfNegative(ret); err != nil {
+		return "", err
+	}
+	return string(buf[:ret]), nil
+}
+
+// Link creates a hard link to the inode with the given name in the directory
+// inode newParent.
+//
+// Implements:
+//  int ceph_ll_link(struct ceph_mount_info *cmount, struct Inode *in,
+//                   struct Inode *newparent, const char *name, const UserPerm *perms);
+func (in *InodeRef) Link(newParent *InodeRef, name string, perm *UserPerm) error {
+	if err := in.validate(); err != nil {
+		return err
+	}
+	if err := newParent.validate(); err != nil {
+		return err
+	}
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+
+	ret := C.ceph_ll_link(
+		in.mount.mount, in.inode, newParent.inode, cName, in.mount.cPerms(perm))
+	return getError(ret)
+}
+
+// Unlink removes the entry with the given name from the directory inode.
+//
+// Implements:
+//  int ceph_ll_unlink(struct ceph_mount_info *cmount, struct Inode *in, const char *name,
+//                     const UserPerm *perms);
+func (in *InodeRef) Unlink(name string, perm *UserPerm) error {
+	if err := in.validate(); err != nil {
+		return err
+	}
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+
+	ret := C.ceph_ll_unlink(in.mount.mount, in.inode, cName, in.mount.cPerms(perm))
+	return getError(ret)
+}
+
+// Rmdir removes the empty directory with the given name from the directory
+// inode.
+//
+// Implements:
+//  int ceph_ll_rmdir(struct ceph_mount_info *cmount, struct Inode *in, const char *name,
+//                    const UserPerm *perms);
+func (in *InodeRef) Rmdir(name string, perm *UserPerm) error {
+	if err := in.validate(); err != nil {
+		return err
+	}
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+
+	ret := C.ceph_ll_rmdir(in.mount.mount, in.inode, cName, in.mount.cPerms(perm))
+	return getError(ret)
+}
+
+// Rename moves the entry with the given name of the directory inode to
+// newName in the directory inode newParent.
+//
+// Implements:
+//  int ceph_ll_rename(struct ceph_mount_info *cmount, struct Inode *parent,
+//                     const char *name, struct Inode *newparent, const char *newname,
+//                     const UserPerm *perms);
+func (in *InodeRef) Rename(name string, newParent *InodeRef, newName string, perm *UserPerm) error {
+	if err := in.validate(); err != nil {
+		return err
+	}
+	if err := newParent.validate(); err != nil {
+		return err
+	}
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+	cNewName := C.CString(newName)
+	defer C.free(unsafe.Pointer(cNewName))
+
+	ret := C.ceph_ll_rename(
+		in.mount.mount, in.inode, cName, newParent.inode, cNewName,
+		in.mount.cPerms(perm))
+	return getError(ret)
+}
+
+// OpenDir opens the directory inode for reading its entries. The returned
+// Directory must be closed with Close.
+//
+// Implements:
+//  int ceph_ll_opendir(struct ceph_mount_info *cmount, struct Inode *in,
+//                      struct ceph_dir_result **dirpp, const UserPerm *perms);
+func (in *InodeRef) OpenDir(perm *UserPerm) (*Directory, error) {
+	if err := in.validate(); err != nil {
+		return nil, err
+	}
+	var dir *C.struct_ceph_dir_result
+	ret := C.ceph_ll_opendir(in.mount.mount, in.inode, &dir, in.mount.cPerms(perm))
+	if err := getError(ret); err != nil {
+		return nil, err
+	}
+	return &Directory{mount: in.mount, dir: dir, ll: true}, nil
+}
+
+// ReadDirPlusInode reads a single directory entry and its stat information,
+// like ReadDirPlus, and also returns a reference on the inode of the entry.
+// Nil pointers are returned when the Directory stream has been exhausted.
+//
+// Implements:
+//  int ceph_readdirplus_r(struct ceph_mount_info *cmount, struct ceph_dir_result *dirp, struct dirent *de,
+//                         struct ceph_statx *stx, unsigned want, unsigned flags, struct Inode **out);
+func (dir *Directory) ReadDirPlusInode(
+	want StatxMask, flags AtFlags) (*DirEntryPlus, *InodeRef, error) {
+
+	var (
+		de    C.struct_dirent
+		s     C.struct_ceph_statx
+		inode *C.struct_Inode
+	)
+	ret := C.ceph_readdirplus_r(
+		dir.mount.mount, dir.dir, &de, &s, C.uint(want), C.uint(flags), &inode)
+	if ret < 0 {
+		return nil, nil, getError(ret)
+	}
+	if ret == 0 {
+		return nil, nil, nil // End-of-stream
+	}
+	return toDirEntryPlus(&de, s), dir.mount.newInodeRef(inode), nil
+}
+
+// GetXattr returns the value of the named extended attribute of the inode.
+//
+// Implements:
+//  int ceph_ll_getxattr(struct ceph_mount_info *cmount, struct Inode *in, const char *name,
+//                       void *value, size_t size, const UserPerm *perms);
+func (in *InodeRef) GetXattr(name string, perm *UserPerm) ([]byte, error) {
+	if err := in.validate(); err != nil {
+		return nil, err
+	}
+	if name == "" {
+		return nil, errInvalid
+	}
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+
+	var (
+		ret C.int
+		err error
+		buf []byte
+	)
+	// range from 1k to 64KiB
+	retry.WithSizes(1024, 1<<16, func(size int) retry.Hint {
+		buf = make([]byte, size)
+		ret = C.ceph_ll_getxattr(
+			in.mount.mount, in.inode, cName, unsafe.Pointer(&buf[0]),
+			C.size_t(size), in.mount.cPerms(perm))
+		err = getErrorIfNegative(ret)
+		return retry.DoubleSize.If(err == errRange)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return buf[:ret], nil
+}
+
+// SetXattr sets the named extended attribute of the inode.
+//
+// Implements:
+//  int ceph_ll_setxattr(struct ceph_mount_info *cmount, struct Inode *in, const char *name,
+//                       const void *value, size_t size, int flags, const UserPerm *perms);
+func (in *InodeRef) SetXattr(name string, value []byte, flags XattrFlags, perm *UserPerm) error {
+	if err := in.validate(); err != nil {
+		return err
+	}
+	if name == "" {
+		return errInvalid
+	}
+	var vptr unsafe.Pointer
+	if len(value) > 0 {
+		vptr = unsafe.Pointer(&value[0])
+	}
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+
+	ret := C.ceph_ll_setxattr(
+		in.mount.mount, in.inode, cName, vptr, C.size_t(len(value)),
+		C.int(flags), in.mount.cPerms(perm))
+	return getError(ret)
+}
+
+// ListXattr returns the names of the extended attributes of the inode.
+//
+// Implements:
+//  int ceph_ll_listxattr(struct ceph_mount_info *cmount, struct Inode *in, char *list,
+//                        size_t buf_size, size_t *list_size, const UserPerm *perms);
+func (in *InodeRef) ListXattr(perm *UserPerm) ([]string, error) {
+	if err := in.validate(); err != nil {
+		return nil, err
+	}
+	var (
+		listSize C.size_t
+		err      error
+		buf      []byte
+	)
+	// range from 1k to 64KiB
+	retry.WithSizes(1024, 1<<16, func(size int) retry.Hint {
+		buf = make([]byte, size)
+		ret := C.ceph_ll_listxattr(
+			in.mount.mount, in.inode, (*C.char)(unsafe.Pointer(&buf[0])),
+			C.size_t(size), &listSize, in.mount.cPerms(perm))
+		err = getErrorIfNegative(ret)
+		return retry.DoubleSize.If(err == errRange)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cutil.SplitSparseBuffer(buf[:listSize]), nil
+}
+
+// RemoveXattr removes the named extended attribute from the inode.
+//
+// Implements:
+//  int ceph_ll_removexattr(struct ceph_mount_info *cmount, struct Inode *in,
+//                          const char *name, const UserPerm *perms);
+func (in *InodeRef) RemoveXattr(name string, perm *UserPerm) error {
+	if err := in.validate(); err != nil {
+		return err
+	}
+	if name == "" {
+		return errInvalid
+	}
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+
+	ret := C.ceph_ll_removexattr(in.mount.mount, in.inode, cName, in.mount.cPerms(perm))
+	return getError(ret)
+}
+
+// StatFS returns file system wide statistics for the file system holding
+// the inode.
+//
+// Implements:
+//  int ceph_ll_statfs(struct ceph_mount_info *cmount, struct Inode *in, struct statvfs *stbuf);
+func (in *InodeRef) StatFS() (*CephStatVFS, error) {
+	if err := in.validate(); err != nil {
+		return nil, err
+	}
+	var statvfs C.struct_statvfs
+	ret := C.ceph_ll_statfs(in.mount.mount, in.inode, &statvfs)
+	if err := getError(ret); err != nil {
+		return nil, err
+	}
+	return cStructToCephStatVFS(statvfs), nil
+}
+
+// SyncInode synchronizes the cached data, and unless SyncDataOnly is given
+// the metadata, of the inode with the cluster.
+//
+// Implements:
+//  int ceph_ll_sync_inode(struct ceph_mount_info *cmount, struct Inode *in, int syncdataonly);
+func (in *InodeRef) SyncInode(sync SyncChoice) error {
+	if err := in.validate(); err != nil {
+		return err
+	}
+	return getError(C.ceph_ll_sync_inode(in.mount.mount, in.inode, C.int(sync)))
+}
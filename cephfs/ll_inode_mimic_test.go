@@ -0,0 +1,189 @@
+// +build !luminous
+
+package cephfs
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLowLevelInode(t *testing.T) {
+	mount := fsConnect(t)
+	defer fsDisconnect(t, mount)
+
+	root, err := mount.LookupRoot()
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, root.Release()) }()
+	rootIno, err := root.Ino()
+	assert.NoError(t, err)
+	assert.Equal(t, Inode(1), rootIno)
+
+	dir, stx, err := root.Mkdir("ll_dir", 0755, StatxBasicStats, 0, nil)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, dir.Release())
+		assert.NoError(t, root.Rmdir("ll_dir", nil))
+	}()
+	assert.True(t, stx.isDir())
+
+	t.Run("createReadWrite", func(t *testing.T) {
+		file, fh, stx, err := dir.Create(
+			"file", 0644, os.O_RDWR|os.O_CREATE, StatxBasicStats, 0, nil)
+		require.NoError(t, err)
+		defer func() {
+			assert.NoError(t, file.Release())
+			assert.NoError(t, dir.Unlink("file", nil))
+		}()
+		assert.True(t, stx.isRegular())
+
+		n, err := fh.WriteAt([]byte("hello world"), 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 11, n)
+		assert.NoError(t, fh.Fsync(SyncAll))
+		assert.NoError(t, fh.Close())
+		assert.NoError(t, fh.Close())
+		_, err = fh.ReadAt(make([]byte, 4), 0)
+		assert.Equal(t, errBadFile, err)
+
+		fh, err = file.Open(os.O_RDONLY, nil)
+		require.NoError(t, err)
+		defer func() { assert.NoError(t, fh.Close()) }()
+		buf := make([]byte, 5)
+		n, err = fh.ReadAt(buf, 6)
+		assert.NoError(t, err)
+		assert.Equal(t, "world", string(buf[:n]))
+		_, err = fh.ReadAt(buf, 11)
+		assert.Equal(t, io.EOF, err)
+		pos, err := fh.Seek(0, SeekEnd)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 11, pos)
+
+		// the same inode is found by lookup, by number and by path
+		ino, err := file.Ino()
+		require.NoError(t, err)
+		found, stx, err := dir.Lookup("file", StatxBasicStats, 0, nil)
+		require.NoError(t, err)
+		assert.Equal(t, ino, stx.Inode)
+		assert.EqualValues(t, 11, stx.Size)
+		assert.NoError(t, found.Release())
+		found, err = mount.LookupInode(ino)
+		require.NoError(t, err)
+		assert.NoError(t, found.Release())
+		found, stx, err = mount.LookupPath("/ll_dir/file", StatxIno, 0, nil)
+		require.NoError(t, err)
+		assert.Equal(t, ino, stx.Inode)
+		assert.NoError(t, found.Forget(1))
+		_, err = found.Ino()
+		assert.Equal(t, ErrInodeReleased, err)
+	})
+
+	t.Run("setattr", func(t *testing.T) {
+		file, fh, _, err := dir.Create(
+			"attrs", 0644, os.O_WRONLY|os.O_CREATE, 0, 0, nil)
+		require.NoError(t, err)
+		assert.NoError(t, fh.Close())
+		defer func() {
+			assert.NoError(t, file.Release())
+			assert.NoError(t, dir.Unlink("attrs", nil))
+		}()
+
+		err = file.Setattr(&CephStatx{
+			Mode:  0600,
+			Size:  100,
+			Mtime: Timespec{Sec: 1000},
+		}, SetattrMode|SetattrSize|SetattrMtime, nil)
+		assert.NoError(t, err)
+		stx, err := file.Getattr(StatxBasicStats, 0, nil)
+		require.NoError(t, err)
+		assert.EqualValues(t, 0600, stx.Mode&0777)
+		assert.EqualValues(t, 100, stx.Size)
+		assert.Equal(t, Timespec{Sec: 1000}, stx.Mtime)
+	})
+
+	t.Run("namespace", func(t *testing.T) {
+		sub, _, err := dir.Mkdir("sub", 0755, 0, 0, nil)
+		require.NoError(t, err)
+		defer func() {
+			assert.NoError(t, sub.Release())
+			assert.NoError(t, dir.Rmdir("sub", nil))
+		}()
+
+		link, _, err := dir.Symlink("link", "sub", StatxBasicStats, 0, nil)
+		require.NoError(t, err)
+		target, err := link.Readlink(nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "sub", target)
+
+		assert.NoError(t, link.Link(sub, "hardlink", nil))
+		assert.NoError(t, link.Release())
+		assert.NoError(t, dir.Rename("link", sub, "moved", nil))
+		assert.NoError(t, sub.Unlink("moved", nil))
+		assert.NoError(t, sub.Unlink("hardlink", nil))
+		_, _, err = dir.Lookup("link", 0, 0, nil)
+		assert.Equal(t, errNoEntry, err)
+
+		_, _, err = dir.Mkdir("sub", 0755, 0, 0, nil)
+		assert.Equal(t, errExist, err)
+	})
+
+	t.Run("readdir", func(t *testing.T) {
+		for _, name := range []string{"a", "b"} {
+			in, _, err := dir.Mkdir(name, 0755, 0, 0, nil)
+			require.NoError(t, err)
+			assert.NoError(t, in.Release())
+			defer func(name string) { assert.NoError(t, dir.Rmdir(name, nil)) }(name)
+		}
+
+		d, err := dir.OpenDir(nil)
+		require.NoError(t, err)
+		defer func() { assert.NoError(t, d.Close()) }()
+		names := map[string]Inode{}
+		for {
+			entry, in, err := d.ReadDirPlusInode(StatxIno, 0)
+			require.NoError(t, err)
+			if entry == nil {
+				break
+			}
+			ino, err := in.Ino()
+			assert.NoError(t, err)
+			assert.NoError(t, in.Release())
+			names[entry.Name()] = ino
+		}
+		assert.Contains(t, names, "a")
+		assert.Contains(t, names, "b")
+	})
+
+	t.Run("xattrs", func(t *testing.T) {
+		err := dir.SetXattr("user.ll", []byte("value"), XattrDefault, nil)
+		assert.NoError(t, err)
+		value, err := dir.GetXattr("user.ll", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "value", string(value))
+		names, err := dir.ListXattr(nil)
+		assert.NoError(t, err)
+		assert.Contains(t, names, "user.ll")
+		assert.NoError(t, dir.RemoveXattr("user.ll", nil))
+		_, err = dir.GetXattr("user.ll", nil)
+		assert.Equal(t, errNoData, err)
+	})
+
+	t.Run("statfs", func(t *testing.T) {
+		stat, err := root.StatFS()
+		assert.NoError(t, err)
+		assert.NotZero(t, stat.Bsize)
+		assert.NoError(t, root.SyncInode(SyncAll))
+	})
+
+	t.Run("released", func(t *testing.T) {
+		in, err := mount.LookupRoot()
+		require.NoError(t, err)
+		assert.NoError(t, in.Release())
+		assert.NoError(t, in.Release())
+		_, err = in.Getattr(StatxBasicStats, 0, nil)
+		assert.Equal(t, ErrInodeReleased, err)
+	})
+}
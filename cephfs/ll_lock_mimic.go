@@ -0,0 +1,218 @@
+// +build !luminous
+//
+// ceph_ll_delegation available in mimic & later
+
+package cephfs
+
+/*
+#cgo LDFLAGS: -lcephfs
+#cgo CPPFLAGS: -D_FILE_OFFSET_BITS=64
+#include <stdlib.h>
+#include <fcntl.h>
+#include <cephfs/libcephfs.h>
+
+extern void delegationRecallCallback(Fh *, uintptr_t);
+
+// inline wrapper to cast uintptr_t to void*
+static inline int wrap_ceph_ll_delegation(struct ceph_mount_info *cmount,
+	Fh *fh, unsigned cmd, uintptr_t arg) {
+		return ceph_ll_delegation(cmount, fh, cmd,
+			(ceph_deleg_cb_t)delegationRecallCallback, (void*)arg);
+	};
+*/
+import "C"
+
+import (
+	"github.com/ceph/go-ceph/internal/callbacks"
+)
+
+// LockType is the type of a byte-range (fcntl) lock.
+type LockType int16
+
+const (
+	// LockTypeRead is a shared lock that prevents others from taking a
+	// write lock on the range.
+	LockTypeRead = LockType(C.F_RDLCK)
+	// LockTypeWrite is an exclusive lock that prevents others from taking
+	// any lock on the range.
+	LockTypeWrite = LockType(C.F_WRLCK)
+	// LockTypeUnlock releases the locks held on the range.
+	LockTypeUnlock = LockType(C.F_UNLCK)
+)
+
+// FileLock describes a byte-range lock, like a struct flock of fcntl.
+type FileLock struct {
+	// Type is the type of the lock.
+	Type LockType
+	// Whence is the origin of Start, one of SeekSet, SeekCur or SeekEnd.
+	Whence int
+	// Start is the offset of the first byte of the range.
+	Start int64
+	// Len is the length of the range, zero extends the range to the end of
+	// the file, however large it grows.
+	Len int64
+	// Pid is the process holding a conflicting lock, as reported by GetLk.
+	Pid int
+}
+
+func (lock *FileLock) toCStruct() C.struct_flock {
+	return C.struct_flock{
+		l_type:   C.short(lock.Type),
+		l_whence: C.short(lock.Whence),
+		l_start:  C.off_t(lock.Start),
+		l_len:    C.off_t(lock.Len),
+		l_pid:    C.pid_t(lock.Pid),
+	}
+}
+
+func cStructToFileLock(fl C.struct_flock) *FileLock {
+	return &FileLock{
+		Type:   LockType(fl.l_type),
+		Whence: int(fl.l_whence),
+		Start:  int64(fl.l_start),
+		Len:    int64(fl.l_len),
+		Pid:    int(fl.l_pid),
+	}
+}
+
+// SetLk acquires, or with LockTypeUnlock releases, a byte-range lock on the
+// file. Locks are held by owner, an arbitrary identifier chosen by the
+// caller, and lock ranges held by the same owner are merged and split as
+// with fcntl. If wait is true SetLk blocks until the lock can be acquired,
+// otherwise ErrWouldBlock is returned when a conflicting lock is held.
+//
+// Byte-range locks are only available through the low level API, use
+// InodeRef.Open to get a FileHandle for a file.
+//
+// Implements:
+//  int ceph_ll_setlk(struct ceph_mount_info *cmount, Fh *fh, struct flock *fl,
+//                    uint64_t owner, int sleep);
+func (fh *FileHandle) SetLk(lock *FileLock, owner uint64, wait bool) error {
+	if err := fh.validate(); err != nil {
+		return err
+	}
+	fl := lock.toCStruct()
+	sleep := C.int(0)
+	if wait {
+		sleep = 1
+	}
+	ret := C.ceph_ll_setlk(fh.mount.mount, fh.fh, &fl, C.uint64_t(owner), sleep)
+	return getError(ret)
+}
+
+// GetLk tests whether the byte-range lock described by lock could be
+// acquired by owner. It returns the first conflicting lock, or a lock of
+// type LockTypeUnlock if there is none.
+//
+// Implements:
+//  int ceph_ll_getlk(struct ceph_mount_info *cmount, Fh *fh, struct flock *fl,
+//                    uint64_t owner);
+func (fh *FileHandle) GetLk(lock *FileLock, owner uint64) (*FileLock, error) {
+	if err := fh.validate(); err != nil {
+		return nil, err
+	}
+	fl := lock.toCStruct()
+	ret := C.ceph_ll_getlk(fh.mount.mount, fh.fh, &fl, C.uint64_t(owner))
+	if err := getError(ret); err != nil {
+		return nil, err
+	}
+	return cStructToFileLock(fl), nil
+}
+
+// DelegationType is the type of a delegation on an open file.
+type DelegationType uint
+
+const (
+	// DelegationNone returns a delegation held on the file.
+	DelegationNone = DelegationType(C.CEPH_DELEGATION_NONE)
+	// DelegationRead is a read delegation, it is recalled when another
+	// client opens the file for writing.
+	DelegationRead = DelegationType(C.CEPH_DELEGATION_RD)
+	// DelegationWrite is a write delegation, it is recalled when another
+	// client opens the file.
+	DelegationWrite = DelegationType(C.CEPH_DELEGATION_WR)
+)
+
+// delegationCallbacks tracks the recall callbacks of the active delegations
+var delegationCallbacks = callbacks.New()
+
+// DelegationRecallFunc is called when a delegation held on a file handle is
+// recalled. It is called from a libcephfs thread and must not block, it
+// should arrange for the delegation to be returned, by calling Delegation
+// with DelegationNone, from another goroutine. A delegation that is not
+// returned within the delegation timeout of the mount causes the client to
+// be blacklisted.
+type DelegationRecallFunc func(fh *FileHandle)
+
+type delegationCallbackCtx struct {
+	fh     *FileHandle
+	recall DelegationRecallFunc
+}
+
+// Delegation requests a delegation of the given type on the file, or with
+// DelegationNone returns the delegation held on it. While a delegation is
+// held the client may cache the file without checking for changes made by
+// other clients, recall is called once another client needs access to the
+// file.
+//
+// Implements:
+//  int ceph_ll_delegation(struct ceph_mount_info *cmount, Fh *fh, unsigned cmd,
+//                         ceph_deleg_cb_t cb, void *priv);
+func (fh *FileHandle) Delegation(dtype DelegationType, recall DelegationRecallFunc) error {
+	if err := fh.validate(); err != nil {
+		return err
+	}
+	if dtype == DelegationNone {
+		ret := C.wrap_ceph_ll_delegation(
+			fh.mount.mount, fh.fh, C.uint(dtype), C.uintptr_t(fh.delegCB))
+		if err := getError(ret); err != nil {
+			return err
+		}
+		fh.releaseDelegationCallback()
+		return nil
+	}
+	if recall == nil {
+		return errInvalid
+	}
+	index := delegationCallbacks.Add(delegationCallbackCtx{fh: fh, recall: recall})
+	ret := C.wrap_ceph_ll_delegation(
+		fh.mount.mount, fh.fh, C.uint(dtype), C.uintptr_t(index))
+	if err := getError(ret); err != nil {
+		delegationCallbacks.Remove(index)
+		return err
+	}
+	// a new delegation request replaces the callback of the previous one
+	fh.releaseDelegationCallback()
+	fh.delegCB = index
+	return nil
+}
+
+func (fh *FileHandle) releaseDelegationCallback() {
+	if fh.delegCB != 0 {
+		delegationCallbacks.Remove(fh.delegCB)
+		fh.delegCB = 0
+	}
+}
+
+// SetDelegationTimeout sets the time, in seconds, the client has to return a
+// recalled delegation before it is blacklisted by the MDS. The timeout must
+// be shorter than the session timeout of the MDS.
+//
+// Implements:
+//  int ceph_set_deleg_timeout(struct ceph_mount_info *cmount, uint32_t timeout);
+func (mount *MountInfo) SetDelegationTimeout(timeout uint32) error {
+	if err := mount.validate(); err != nil {
+		return err
+	}
+	return getError(C.ceph_set_deleg_timeout(mount.mount, C.uint32_t(timeout)))
+}
+
+//export delegationRecallCallback
+func delegationRecallCallback(_ *C.Fh, index uintptr) {
+	v := delegationCallbacks.Lookup(index)
+	if v == nil {
+		return
+	}
+	dcc := v.(delegationCallbackCtx)
+	dcc.recall(dcc.fh)
+}
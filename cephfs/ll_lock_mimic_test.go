@@ -0,0 +1,128 @@
+// +build !luminous
+
+package cephfs
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileHandleSetLk(t *testing.T) {
+	mount := fsConnect(t)
+	defer fsDisconnect(t, mount)
+
+	root, err := mount.LookupRoot()
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, root.Release()) }()
+	in, fh1, _, err := root.Create("ll_locks", 0644, os.O_RDWR|os.O_CREATE, 0, 0, nil)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, in.Release())
+		assert.NoError(t, root.Unlink("ll_locks", nil))
+	}()
+	defer func() { assert.NoError(t, fh1.Close()) }()
+	fh2, err := in.Open(os.O_RDWR, nil)
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, fh2.Close()) }()
+
+	const owner1, owner2 = 1001, 1002
+	wlock := &FileLock{Type: LockTypeWrite, Whence: SeekSet, Start: 0, Len: 10}
+	assert.NoError(t, fh1.SetLk(wlock, owner1, false))
+
+	// the owner itself sees no conflict
+	fl, err := fh1.GetLk(&FileLock{Type: LockTypeWrite, Start: 5, Len: 10}, owner1)
+	assert.NoError(t, err)
+	assert.Equal(t, LockTypeUnlock, fl.Type)
+
+	fl, err = fh2.GetLk(&FileLock{Type: LockTypeRead, Start: 5, Len: 10}, owner2)
+	assert.NoError(t, err)
+	assert.Equal(t, LockTypeWrite, fl.Type)
+	assert.EqualValues(t, 0, fl.Start)
+	assert.EqualValues(t, 10, fl.Len)
+
+	err = fh2.SetLk(&FileLock{Type: LockTypeRead, Start: 5, Len: 10}, owner2, false)
+	assert.Equal(t, ErrWouldBlock, err)
+	// a range that does not overlap can be locked
+	err = fh2.SetLk(&FileLock{Type: LockTypeWrite, Start: 10, Len: 10}, owner2, false)
+	assert.NoError(t, err)
+
+	// a blocking request waits for the conflicting lock to be released
+	done := make(chan error)
+	go func() {
+		done <- fh2.SetLk(&FileLock{Type: LockTypeWrite, Start: 0, Len: 10}, owner2, true)
+	}()
+	select {
+	case err := <-done:
+		t.Fatalf("blocking lock returned early: %v", err)
+	case <-time.After(500 * time.Millisecond):
+	}
+	unlock := &FileLock{Type: LockTypeUnlock, Start: 0, Len: 0}
+	assert.NoError(t, fh1.SetLk(unlock, owner1, false))
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for blocking lock")
+	}
+
+	fl, err = fh1.GetLk(&FileLock{Type: LockTypeRead, Start: 0, Len: 0}, owner1)
+	assert.NoError(t, err)
+	assert.Equal(t, LockTypeWrite, fl.Type)
+	assert.NoError(t, fh2.SetLk(unlock, owner2, false))
+
+	assert.NoError(t, fh2.Close())
+	err = fh2.SetLk(wlock, owner2, false)
+	assert.Equal(t, errBadFile, err)
+}
+
+func TestFileHandleDelegation(t *testing.T) {
+	mount := fsConnect(t)
+	defer fsDisconnect(t, mount)
+	assert.NoError(t, mount.SetDelegationTimeout(30))
+
+	root, err := mount.LookupRoot()
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, root.Release()) }()
+	in, fh, _, err := root.Create("ll_deleg", 0644, os.O_RDWR|os.O_CREATE, 0, 0, nil)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, in.Release())
+		assert.NoError(t, root.Unlink("ll_deleg", nil))
+	}()
+	assert.NoError(t, fh.Close())
+	fh, err = in.Open(os.O_RDONLY, nil)
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, fh.Close()) }()
+
+	assert.Equal(t, errInvalid, fh.Delegation(DelegationRead, nil))
+
+	recalled := make(chan *FileHandle, 1)
+	err = fh.Delegation(DelegationRead, func(rfh *FileHandle) {
+		recalled <- rfh
+	})
+	require.NoError(t, err)
+
+	// opening the file for writing from another client recalls the delegation
+	opened := make(chan error, 1)
+	go func() {
+		mount2 := fsConnect(t)
+		defer fsDisconnect(t, mount2)
+		f, err := mount2.Open("/ll_deleg", os.O_WRONLY, 0)
+		if err == nil {
+			err = f.Close()
+		}
+		opened <- err
+	}()
+	select {
+	case rfh := <-recalled:
+		assert.Equal(t, fh, rfh)
+		assert.NoError(t, fh.Delegation(DelegationNone, nil))
+	case <-time.After(20 * time.Second):
+		t.Fatal("timed out waiting for delegation recall")
+	}
+	assert.NoError(t, <-opened)
+}
@@ -18,5 +18,10 @@ import "C"
 // Implements:
 //  int ceph_mount_perms_set(struct ceph_mount_info *cmount, UserPerm *perm);
 func (mount *MountInfo) SetMountPerms(perm *UserPerm) error {
-	return getError(C.ceph_mount_perms_set(mount.mount, perm.userPerm))
+	ret := C.ceph_mount_perms_set(mount.mount, perm.userPerm)
+	if err := getError(ret); err != nil {
+		return err
+	}
+	mount.perms = perm
+	return nil
 }
@@ -29,3 +29,16 @@ func (mount *MountInfo) Chown(path string, user uint32, group uint32) error {
 	ret := C.ceph_chown(mount.mount, cPath, C.int(user), C.int(group))
 	return getError(ret)
 }
+
+// Lchown changes the ownership of a file/directory, if the path is a
+// symbolic link the ownership of the link itself is changed.
+//
+// Implements:
+//  int ceph_lchown(struct ceph_mount_info *cmount, const char *path, int uid, int gid);
+func (mount *MountInfo) Lchown(path string, user uint32, group uint32) error {
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+
+	ret := C.ceph_lchown(mount.mount, cPath, C.int(user), C.int(group))
+	return getError(ret)
+}
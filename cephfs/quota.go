@@ -0,0 +1,131 @@
+package cephfs
+
+import (
+	"strconv"
+	"strings"
+)
+
+const (
+	quotaMaxBytesXattr = "ceph.quota.max_bytes"
+	quotaMaxFilesXattr = "ceph.quota.max_files"
+
+	dirRbytesXattr   = "ceph.dir.rbytes"
+	dirRfilesXattr   = "ceph.dir.rfiles"
+	dirRsubdirsXattr = "ceph.dir.rsubdirs"
+)
+
+// Quota limits the space used by, and the number of files in, a directory
+// tree. A zero value means the corresponding limit is not set.
+type Quota struct {
+	// MaxBytes is the maximum number of bytes used by the files below the
+	// directory.
+	MaxBytes uint64
+	// MaxFiles is the maximum number of files and directories below the
+	// directory.
+	MaxFiles uint64
+}
+
+// getXattrString returns the value of a ceph virtual xattr as a string.
+func (mount *MountInfo) getXattrString(path, name string) (string, error) {
+	value, err := mount.GetXattr(path, name)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(value), "\x00"), nil
+}
+
+// getXattrUint returns the value of a numeric ceph virtual xattr.
+func (mount *MountInfo) getXattrUint(path, name string) (uint64, error) {
+	s, err := mount.getXattrString(path, name)
+	if err != nil {
+		return 0, err
+	}
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, errInvalid
+	}
+	return v, nil
+}
+
+// getQuotaValue returns the value of a quota xattr, an unset quota is
+// reported as zero.
+func (mount *MountInfo) getQuotaValue(path, name string) (uint64, error) {
+	v, err := mount.getXattrUint(path, name)
+	if err == errNoData {
+		return 0, nil
+	}
+	return v, err
+}
+
+// GetQuota returns the quota of the directory at the given path.
+func (mount *MountInfo) GetQuota(path string) (*Quota, error) {
+	maxBytes, err := mount.getQuotaValue(path, quotaMaxBytesXattr)
+	if err != nil {
+		return nil, err
+	}
+	maxFiles, err := mount.getQuotaValue(path, quotaMaxFilesXattr)
+	if err != nil {
+		return nil, err
+	}
+	return &Quota{MaxBytes: maxBytes, MaxFiles: maxFiles}, nil
+}
+
+// SetQuota sets the quota of the directory at the given path. Limits with a
+// zero value are removed.
+func (mount *MountInfo) SetQuota(path string, quota Quota) error {
+	err := mount.SetXattr(path, quotaMaxBytesXattr,
+		[]byte(strconv.FormatUint(quota.MaxBytes, 10)), XattrDefault)
+	if err != nil {
+		return err
+	}
+	return mount.SetXattr(path, quotaMaxFilesXattr,
+		[]byte(strconv.FormatUint(quota.MaxFiles, 10)), XattrDefault)
+}
+
+// ClearQuota removes all the quota limits of the directory at the given
+// path.
+func (mount *MountInfo) ClearQuota(path string) error {
+	return mount.SetQuota(path, Quota{})
+}
+
+// DirStats holds the recursive statistics that are maintained for every
+// directory of the file system. The statistics are propagated lazily and may
+// lag behind recent changes.
+type DirStats struct {
+	// Bytes is the size of all the files below the directory.
+	Bytes uint64
+	// Files is the number of files below the directory.
+	Files uint64
+	// Subdirs is the number of directories below the directory, including
+	// the directory itself.
+	Subdirs uint64
+	// Rctime is the most recent ctime of any file or directory below the
+	// directory.
+	Rctime Timespec
+}
+
+// GetDirStats returns the recursive statistics of the directory at the given
+// path.
+func (mount *MountInfo) GetDirStats(path string) (*DirStats, error) {
+	var (
+		ds  DirStats
+		err error
+	)
+	if ds.Bytes, err = mount.getXattrUint(path, dirRbytesXattr); err != nil {
+		return nil, err
+	}
+	if ds.Files, err = mount.getXattrUint(path, dirRfilesXattr); err != nil {
+		return nil, err
+	}
+	if ds.Subdirs, err = mount.getXattrUint(path, dirRsubdirsXattr); err != nil {
+		return nil, err
+	}
+	rctime, err := mount.GetXattr(path, dirRctimeXattr)
+	if err != nil {
+		return nil, err
+	}
+	if ds.Rctime, err = parseXattrTime(rctime); err != nil {
+		return nil, err
+	}
+	return &ds, nil
+}
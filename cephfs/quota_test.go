@@ -0,0 +1,64 @@
+package cephfs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuota(t *testing.T) {
+	mount := fsConnect(t)
+	defer fsDisconnect(t, mount)
+
+	dir := "/quotadir"
+	require.NoError(t, mount.MakeDir(dir, 0755))
+	defer func() { assert.NoError(t, mount.RemoveDir(dir)) }()
+
+	q, err := mount.GetQuota(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, &Quota{}, q)
+
+	err = mount.SetQuota(dir, Quota{MaxBytes: 10 << 20, MaxFiles: 100})
+	assert.NoError(t, err)
+	q, err = mount.GetQuota(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, &Quota{MaxBytes: 10 << 20, MaxFiles: 100}, q)
+
+	err = mount.SetQuota(dir, Quota{MaxFiles: 50})
+	assert.NoError(t, err)
+	q, err = mount.GetQuota(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, &Quota{MaxFiles: 50}, q)
+
+	err = mount.ClearQuota(dir)
+	assert.NoError(t, err)
+	q, err = mount.GetQuota(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, &Quota{}, q)
+
+	_, err = mount.GetQuota("/no.such.dir")
+	assert.Equal(t, errNoEntry, err)
+}
+
+func TestDirStats(t *testing.T) {
+	mount := fsConnect(t)
+	defer fsDisconnect(t, mount)
+
+	dir := "/dirstats"
+	require.NoError(t, mount.MkdirAll(dir+"/sub", 0755))
+	defer func() { assert.NoError(t, mount.RemoveAll(dir)) }()
+	writeTestFile(t, mount, dir+"/a", "12345", 0644)
+	writeTestFile(t, mount, dir+"/sub/b", "1234567890", 0644)
+	require.NoError(t, mount.SyncFs())
+
+	ds, err := mount.GetDirStats(dir)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(15), ds.Bytes)
+	assert.Equal(t, uint64(2), ds.Files)
+	assert.Equal(t, uint64(2), ds.Subdirs)
+	assert.NotZero(t, ds.Rctime.Sec)
+
+	_, err = mount.GetDirStats("/no.such.dir")
+	assert.Equal(t, errNoEntry, err)
+}
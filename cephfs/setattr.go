@@ -0,0 +1,110 @@
+package cephfs
+
+/*
+#cgo LDFLAGS: -lcephfs
+#cgo CPPFLAGS: -D_FILE_OFFSET_BITS=64
+#include <stdlib.h>
+#include <cephfs/libcephfs.h>
+*/
+import "C"
+
+import (
+	"unsafe"
+)
+
+// SetattrMask values select the fields of a CephStatx that are applied by
+// the setattr family of functions.
+type SetattrMask int
+
+const (
+	// SetattrMode sets the mode of the file.
+	SetattrMode = SetattrMask(C.CEPH_SETATTR_MODE)
+	// SetattrUid sets the owner of the file.
+	SetattrUid = SetattrMask(C.CEPH_SETATTR_UID)
+	// SetattrGid sets the group of the file.
+	SetattrGid = SetattrMask(C.CEPH_SETATTR_GID)
+	// SetattrMtime sets the modification time of the file.
+	SetattrMtime = SetattrMask(C.CEPH_SETATTR_MTIME)
+	// SetattrAtime sets the access time of the file.
+	SetattrAtime = SetattrMask(C.CEPH_SETATTR_ATIME)
+	// SetattrSize sets the size of the file.
+	SetattrSize = SetattrMask(C.CEPH_SETATTR_SIZE)
+	// SetattrCtime sets the status change time of the file.
+	SetattrCtime = SetattrMask(C.CEPH_SETATTR_CTIME)
+	// SetattrBtime sets the creation (birth) time of the file.
+	SetattrBtime = SetattrMask(C.CEPH_SETATTR_BTIME)
+)
+
+// SetAttrx applies the fields of stx selected by mask to the file at the
+// given path. If flags contains AtSymlinkNofollow a symbolic link is changed
+// itself rather than the file it points to.
+//
+// Implements:
+//  int ceph_setattrx(struct ceph_mount_info *cmount, const char *relpath,
+//                    struct ceph_statx *stx, int mask, int flags);
+func (mount *MountInfo) SetAttrx(path string, stx *CephStatx, mask SetattrMask, flags AtFlags) error {
+	if err := mount.validate(); err != nil {
+		return err
+	}
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+
+	cStx := stx.toCStruct()
+	ret := C.ceph_setattrx(mount.mount, cPath, &cStx, C.int(mask), C.int(flags))
+	return getError(ret)
+}
+
+// Fsetattrx applies the fields of stx selected by mask to the open file.
+//
+// Implements:
+//  int ceph_fsetattrx(struct ceph_mount_info *cmount, int fd, struct ceph_statx *stx, int mask);
+func (f *File) Fsetattrx(stx *CephStatx, mask SetattrMask) error {
+	if err := f.validate(); err != nil {
+		return err
+	}
+	cStx := stx.toCStruct()
+	ret := C.ceph_fsetattrx(f.mount.mount, f.fd, &cStx, C.int(mask))
+	return getError(ret)
+}
+
+// Utimes sets the access and modification times of the file at the given
+// path, following symbolic links. The times are set with nanosecond
+// precision.
+func (mount *MountInfo) Utimes(path string, atime, mtime Timespec) error {
+	stx := &CephStatx{Atime: atime, Mtime: mtime}
+	return mount.SetAttrx(path, stx, SetattrAtime|SetattrMtime, 0)
+}
+
+// Lutimes sets the access and modification times of the file at the given
+// path, like Utimes, but changes a symbolic link itself rather than the file
+// it points to.
+func (mount *MountInfo) Lutimes(path string, atime, mtime Timespec) error {
+	stx := &CephStatx{Atime: atime, Mtime: mtime}
+	return mount.SetAttrx(path, stx, SetattrAtime|SetattrMtime, AtSymlinkNofollow)
+}
+
+// Futimens sets the access and modification times of the open file with
+// nanosecond precision.
+func (f *File) Futimens(atime, mtime Timespec) error {
+	stx := &CephStatx{Atime: atime, Mtime: mtime}
+	return f.Fsetattrx(stx, SetattrAtime|SetattrMtime)
+}
+
+// Mknod creates a file system node at the given path. The mode contains
+// both the permission bits and the type of the node, one of S_IFREG,
+// S_IFIFO, S_IFSOCK, S_IFCHR or S_IFBLK. The dev value is the device number
+// of character and block devices, as made by unix.Mkdev, and is ignored for
+// the other types.
+//
+// Implements:
+//  int ceph_mknod(struct ceph_mount_info *cmount, const char *path, mode_t mode, dev_t rdev);
+func (mount *MountInfo) Mknod(path string, mode uint32, dev uint64) error {
+	if err := mount.validate(); err != nil {
+		return err
+	}
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+
+	ret := C.ceph_mknod(mount.mount, cPath, C.mode_t(mode), C.dev_t(dev))
+	return getError(ret)
+}
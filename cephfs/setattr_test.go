@@ -0,0 +1,146 @@
+package cephfs
+
+import (
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetAttrx(t *testing.T) {
+	mount := fsConnect(t)
+	defer fsDisconnect(t, mount)
+
+	fname := "/setattrx.txt"
+	writeTestFile(t, mount, fname, "some data", 0644)
+	defer func() { assert.NoError(t, mount.Unlink(fname)) }()
+
+	stx := &CephStatx{
+		Mode:  0600,
+		Size:  4,
+		Btime: Timespec{Sec: 1000, Nsec: 1},
+	}
+	err := mount.SetAttrx(fname, stx, SetattrMode|SetattrSize|SetattrBtime, 0)
+	assert.NoError(t, err)
+	st, err := mount.Statx(fname, StatxBasicStats|StatxBtime, 0)
+	require.NoError(t, err)
+	assert.EqualValues(t, 0600, st.Mode&0777)
+	assert.EqualValues(t, 4, st.Size)
+	assert.Equal(t, Timespec{Sec: 1000, Nsec: 1}, st.Btime)
+
+	err = mount.SetAttrx("/no-such-file", stx, SetattrMode, 0)
+	assert.Equal(t, errNoEntry, err)
+
+	t.Run("fsetattrx", func(t *testing.T) {
+		f, err := mount.Open(fname, os.O_RDWR, 0)
+		require.NoError(t, err)
+		defer func() { assert.NoError(t, f.Close()) }()
+		err = f.Fsetattrx(&CephStatx{Mode: 0640}, SetattrMode)
+		assert.NoError(t, err)
+		st, err := f.Fstatx(StatxMode, 0)
+		require.NoError(t, err)
+		assert.EqualValues(t, 0640, st.Mode&0777)
+	})
+}
+
+func TestUtimes(t *testing.T) {
+	mount := fsConnect(t)
+	defer fsDisconnect(t, mount)
+
+	fname := "/utimes.txt"
+	lname := "/utimes.link"
+	writeTestFile(t, mount, fname, "data", 0644)
+	defer func() { assert.NoError(t, mount.Unlink(fname)) }()
+	require.NoError(t, mount.Symlink(fname, lname))
+	defer func() { assert.NoError(t, mount.Unlink(lname)) }()
+
+	atime := Timespec{Sec: 1500000000, Nsec: 123456789}
+	mtime := Timespec{Sec: 1600000000, Nsec: 987654321}
+	assert.NoError(t, mount.Utimes(lname, atime, mtime))
+	st, err := mount.Statx(fname, StatxAtime|StatxMtime, 0)
+	require.NoError(t, err)
+	assert.Equal(t, atime, st.Atime)
+	assert.Equal(t, mtime, st.Mtime)
+
+	// Lutimes changes the link, not its target
+	ltime := Timespec{Sec: 1000000000}
+	assert.NoError(t, mount.Lutimes(lname, ltime, ltime))
+	st, err = mount.Statx(lname, StatxMtime, AtSymlinkNofollow)
+	require.NoError(t, err)
+	assert.Equal(t, ltime, st.Mtime)
+	st, err = mount.Statx(fname, StatxMtime, 0)
+	require.NoError(t, err)
+	assert.Equal(t, mtime, st.Mtime)
+
+	f, err := mount.Open(fname, os.O_RDONLY, 0)
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, f.Close()) }()
+	ftime := Timespec{Sec: 1700000000, Nsec: 42}
+	assert.NoError(t, f.Futimens(ftime, ftime))
+	st, err = f.Fstatx(StatxAtime|StatxMtime, 0)
+	require.NoError(t, err)
+	assert.Equal(t, ftime, st.Atime)
+	assert.Equal(t, ftime, st.Mtime)
+}
+
+func TestMknod(t *testing.T) {
+	mount := fsConnect(t)
+	defer fsDisconnect(t, mount)
+
+	fifo := "/mknod.fifo"
+	assert.NoError(t, mount.Mknod(fifo, syscall.S_IFIFO|0640, 0))
+	defer func() { assert.NoError(t, mount.Unlink(fifo)) }()
+	st, err := mount.Statx(fifo, StatxMode, 0)
+	require.NoError(t, err)
+	assert.EqualValues(t, syscall.S_IFIFO|0640, st.Mode)
+
+	assert.Equal(t, errExist, mount.Mknod(fifo, syscall.S_IFIFO|0640, 0))
+
+	// character devices need privileges the test cluster client may lack
+	dev := "/mknod.chr"
+	err = mount.Mknod(dev, syscall.S_IFCHR|0600, 0x0103)
+	if err == nil {
+		defer func() { assert.NoError(t, mount.Unlink(dev)) }()
+		st, err := mount.Statx(dev, StatxMode|StatxRdev, 0)
+		require.NoError(t, err)
+		assert.EqualValues(t, syscall.S_IFCHR|0600, st.Mode)
+		assert.EqualValues(t, 0x0103, st.Rdev)
+	}
+}
+
+func TestAccess(t *testing.T) {
+	mount := fsConnect(t)
+	defer fsDisconnect(t, mount)
+
+	fname := "/access.txt"
+	writeTestFile(t, mount, fname, "data", 0640)
+	defer func() { assert.NoError(t, mount.Unlink(fname)) }()
+
+	assert.NoError(t, mount.Access(fname, AccessExists))
+	assert.NoError(t, mount.Access(fname, AccessRead|AccessWrite))
+	assert.Equal(t, errNoEntry, mount.Access("/no-such-file", AccessExists))
+	// nobody may execute the file, not even root
+	assert.Equal(t, errAccess, mount.Access(fname, AccessExec))
+	require.NoError(t, mount.Chmod(fname, 0750))
+	assert.NoError(t, mount.Access(fname, AccessRead|AccessExec))
+
+	uid, gids, err := mount.credentials()
+	require.NoError(t, err)
+	if uid != 0 {
+		require.NoError(t, mount.Chmod(fname, 0400))
+		assert.Equal(t, errAccess, mount.Access(fname, AccessWrite))
+	}
+	assert.NotEmpty(t, gids)
+	_, gidSet, err := mount.credentialOption("client_mount_gid", 0)
+	require.NoError(t, err)
+	if mount.perms == nil && !gidSet {
+		// the supplementary groups of the process are included
+		groups, err := os.Getgroups()
+		require.NoError(t, err)
+		for _, g := range groups {
+			assert.Contains(t, gids, uint32(g))
+		}
+	}
+}
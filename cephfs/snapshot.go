@@ -0,0 +1,331 @@
+package cephfs
+
+import (
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	// defaultSnapDir is the name of the snapshot directory if the
+	// client_snapdir option is not set.
+	defaultSnapDir = ".snap"
+
+	snapBtimeXattr  = "ceph.snap.btime"
+	dirRctimeXattr  = "ceph.dir.rctime"
+	snapNamePrivate = "_"
+)
+
+// SnapDir returns the name of the virtual directory through which the
+// snapshots of a directory are managed, as configured with the
+// client_snapdir option. The default name is ".snap".
+func (mount *MountInfo) SnapDir() (string, error) {
+	name, err := mount.GetConfigOption("client_snapdir")
+	if err != nil {
+		return "", err
+	}
+	if name == "" {
+		name = defaultSnapDir
+	}
+	return name, nil
+}
+
+// SnapshotPath returns the path through which the snapshot name of the
+// directory dir can be accessed.
+func (mount *MountInfo) SnapshotPath(dir, name string) (string, error) {
+	snapDir, err := mount.SnapDir()
+	if err != nil {
+		return "", err
+	}
+	return path.Join(dir, snapDir, name), nil
+}
+
+// MakeSnapshot creates a snapshot with the given name of the directory dir
+// and everything below it. The snapshot is created by making a directory in
+// the snapshot directory of dir, mode is used for that directory.
+// Snapshots must be enabled for the file system.
+func (mount *MountInfo) MakeSnapshot(dir, name string, mode uint32) error {
+	p, err := mount.SnapshotPath(dir, name)
+	if err != nil {
+		return err
+	}
+	return mount.MakeDir(p, mode)
+}
+
+// RemoveSnapshot removes the snapshot with the given name of the directory
+// dir.
+func (mount *MountInfo) RemoveSnapshot(dir, name string) error {
+	p, err := mount.SnapshotPath(dir, name)
+	if err != nil {
+		return err
+	}
+	return mount.RemoveDir(p)
+}
+
+// ListSnapshots returns the names of the snapshots of the directory dir,
+// sorted by name. The snapshot directory also lists the snapshots of the
+// parent directories of dir, with names starting with an underscore, these
+// are not returned.
+func (mount *MountInfo) ListSnapshots(dir string) ([]string, error) {
+	snapDir, err := mount.SnapDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := mount.readDirPlusAll(path.Join(dir, snapDir))
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), snapNamePrivate) {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+// SnapshotInfo holds information about a snapshot of a directory.
+type SnapshotInfo struct {
+	// Name of the snapshot.
+	Name string
+	// Path through which the snapshot can be accessed.
+	Path string
+	// Created is the time the snapshot was taken. It is only set if the
+	// file system provides the ceph.snap.btime attribute of snapshots.
+	Created Timespec
+	// Statx holds the stat information of the directory as captured by
+	// the snapshot.
+	Statx *CephStatx
+}
+
+// GetSnapshotInfo returns information about the snapshot with the given
+// name of the directory dir.
+func (mount *MountInfo) GetSnapshotInfo(dir, name string) (*SnapshotInfo, error) {
+	p, err := mount.SnapshotPath(dir, name)
+	if err != nil {
+		return nil, err
+	}
+	stx, err := mount.Statx(p, StatxBasicStats|StatxBtime, 0)
+	if err != nil {
+		return nil, err
+	}
+	info := &SnapshotInfo{
+		Name:  name,
+		Path:  p,
+		Statx: stx,
+	}
+	value, err := mount.GetXattr(p, snapBtimeXattr)
+	switch {
+	case err == nil:
+		info.Created, err = parseXattrTime(value)
+		if err != nil {
+			return nil, err
+		}
+	case err != errNoData:
+		return nil, err
+	}
+	return info, nil
+}
+
+// parseXattrTime parses time values of the ceph virtual xattrs, which are
+// formatted as seconds and nanoseconds separated by a dot.
+func parseXattrTime(value []byte) (Timespec, error) {
+	var t Timespec
+	s := strings.TrimRight(string(value), "\x00")
+	parts := strings.SplitN(s, ".", 2)
+	sec, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return t, errInvalid
+	}
+	t.Sec = sec
+	if len(parts) == 2 {
+		nsec, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return t, errInvalid
+		}
+		t.Nsec = nsec
+	}
+	return t, nil
+}
+
+// SnapDiffType indicates how an entry differs between two snapshots.
+type SnapDiffType int
+
+const (
+	// SnapDiffAdded indicates the entry only exists in the later snapshot.
+	SnapDiffAdded = SnapDiffType(iota)
+	// SnapDiffRemoved indicates the entry only exists in the earlier
+	// snapshot.
+	SnapDiffRemoved
+	// SnapDiffModified indicates the entry exists in both snapshots but its
+	// content or attributes differ.
+	SnapDiffModified
+)
+
+// String returns a readable representation of the SnapDiffType.
+func (t SnapDiffType) String() string {
+	switch t {
+	case SnapDiffAdded:
+		return "added"
+	case SnapDiffRemoved:
+		return "removed"
+	case SnapDiffModified:
+		return "modified"
+	}
+	return "unknown"
+}
+
+// SnapDiffEntry describes a file or directory that differs between two
+// snapshots.
+type SnapDiffEntry struct {
+	// Path of the entry, relative to the snapshotted directory.
+	Path string
+	// Type of the difference.
+	Type SnapDiffType
+	// Statx holds the stat information of the entry in the later snapshot,
+	// or in the earlier snapshot for removed entries.
+	Statx *CephStatx
+}
+
+// DiffSnapshots compares the snapshots fromSnap and toSnap of the directory
+// dir and returns the entries that differ between them, in lexical order.
+//
+// The trees of both snapshots are read with ReadDirPlus. Subdirectories are
+// only compared if their recursive ctime (ceph.dir.rctime) differs between
+// the snapshots, so unchanged parts of the tree are skipped. The content of
+// directories that were added is reported too, for removed directories only
+// the directory itself is reported. A directory whose entries changed is
+// reported as modified.
+func (mount *MountInfo) DiffSnapshots(dir, fromSnap, toSnap string) ([]SnapDiffEntry, error) {
+	fromPath, err := mount.SnapshotPath(dir, fromSnap)
+	if err != nil {
+		return nil, err
+	}
+	toPath, err := mount.SnapshotPath(dir, toSnap)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range []string{fromPath, toPath} {
+		if _, err := mount.Statx(p, StatxMode, 0); err != nil {
+			return nil, err
+		}
+	}
+
+	d := &snapDiff{mount: mount, entries: []SnapDiffEntry{}}
+	if err = d.diffDir(fromPath, toPath, ""); err != nil {
+		return nil, err
+	}
+	return d.entries, nil
+}
+
+type snapDiff struct {
+	mount   *MountInfo
+	entries []SnapDiffEntry
+}
+
+func (d *snapDiff) add(rel string, t SnapDiffType, stx *CephStatx) {
+	d.entries = append(d.entries, SnapDiffEntry{Path: rel, Type: t, Statx: stx})
+}
+
+// diffDir compares the directories from and to, rel is the path of the
+// directories relative to the snapshot.
+func (d *snapDiff) diffDir(from, to, rel string) error {
+	fromEntries, err := d.mount.readDirPlusAll(from)
+	if err != nil {
+		return err
+	}
+	toEntries, err := d.mount.readDirPlusAll(to)
+	if err != nil {
+		return err
+	}
+
+	// both lists are sorted by name
+	i, j := 0, 0
+	for i < len(fromEntries) || j < len(toEntries) {
+		switch {
+		case j == len(toEntries) ||
+			(i < len(fromEntries) && fromEntries[i].Name() < toEntries[j].Name()):
+			fe := fromEntries[i]
+			d.add(path.Join(rel, fe.Name()), SnapDiffRemoved, fe.Statx())
+			i++
+		case i == len(fromEntries) || toEntries[j].Name() < fromEntries[i].Name():
+			te := toEntries[j]
+			if err := d.added(to, rel, te); err != nil {
+				return err
+			}
+			j++
+		default:
+			fe, te := fromEntries[i], toEntries[j]
+			if err := d.diffEntry(from, to, rel, fe, te); err != nil {
+				return err
+			}
+			i++
+			j++
+		}
+	}
+	return nil
+}
+
+// added reports the entry te of the directory to, and if it is a directory
+// everything below it, as added.
+func (d *snapDiff) added(to, rel string, te *DirEntryPlus) error {
+	p := path.Join(rel, te.Name())
+	d.add(p, SnapDiffAdded, te.Statx())
+	if !te.Statx().isDir() {
+		return nil
+	}
+	entries, err := d.mount.readDirPlusAll(path.Join(to, te.Name()))
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err = d.added(path.Join(to, te.Name()), p, entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// diffEntry compares the entries fe and te that have the same name.
+func (d *snapDiff) diffEntry(from, to, rel string, fe, te *DirEntryPlus) error {
+	p := path.Join(rel, te.Name())
+	fstx, tstx := fe.Statx(), te.Statx()
+	fromPath, toPath := path.Join(from, fe.Name()), path.Join(to, te.Name())
+
+	if fstx.isDir() != tstx.isDir() || fstx.Inode != tstx.Inode {
+		// the entry was replaced
+		d.add(p, SnapDiffRemoved, fstx)
+		return d.added(to, rel, te)
+	}
+	if statxChanged(fstx, tstx) {
+		d.add(p, SnapDiffModified, tstx)
+	}
+	if !tstx.isDir() {
+		return nil
+	}
+
+	fromRctime, err := d.mount.GetXattr(fromPath, dirRctimeXattr)
+	if err != nil {
+		return err
+	}
+	toRctime, err := d.mount.GetXattr(toPath, dirRctimeXattr)
+	if err != nil {
+		return err
+	}
+	if string(fromRctime) == string(toRctime) {
+		// nothing below the directory changed
+		return nil
+	}
+	return d.diffDir(fromPath, toPath, p)
+}
+
+// statxChanged returns true if the content or the attributes of a file
+// differ between the stat information a and b.
+func statxChanged(a, b *CephStatx) bool {
+	return a.Mode != b.Mode ||
+		a.Uid != b.Uid ||
+		a.Gid != b.Gid ||
+		a.Size != b.Size ||
+		a.Mtime != b.Mtime ||
+		a.Ctime != b.Ctime
+}
@@ -0,0 +1,106 @@
+// +build !luminous,!mimic,!nautilus,!octopus
+
+package cephfs
+
+/*
+#cgo LDFLAGS: -lcephfs
+#cgo CPPFLAGS: -D_FILE_OFFSET_BITS=64
+#include <stdlib.h>
+#include <cephfs/libcephfs.h>
+*/
+import "C"
+
+import (
+	"unsafe"
+)
+
+// SnapshotMetadata holds the id of a snapshot and the metadata that was
+// attached to it when it was created.
+type SnapshotMetadata struct {
+	ID       uint64
+	Metadata map[string]string
+}
+
+// MakeSnapshotWithMetadata creates a snapshot with the given name of the
+// directory dir, like MakeSnapshot, and attaches the key/value pairs of
+// metadata to it.
+//
+// Implements:
+//  int ceph_mksnap(struct ceph_mount_info *cmount, const char *path, const char *name,
+//                  mode_t mode, struct snap_metadata *snap_metadata, size_t nr_snap_metadata);
+func (mount *MountInfo) MakeSnapshotWithMetadata(
+	dir, name string, mode uint32, metadata map[string]string) error {
+
+	if err := mount.validate(); err != nil {
+		return err
+	}
+	cDir := C.CString(dir)
+	defer C.free(unsafe.Pointer(cDir))
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+
+	var cMeta *C.struct_snap_metadata
+	if len(metadata) > 0 {
+		size := C.sizeof_struct_snap_metadata
+		cMeta = (*C.struct_snap_metadata)(
+			C.malloc(C.size_t(len(metadata)) * C.size_t(size)))
+		defer C.free(unsafe.Pointer(cMeta))
+		i := 0
+		for k, v := range metadata {
+			m := (*C.struct_snap_metadata)(unsafe.Pointer(
+				uintptr(unsafe.Pointer(cMeta)) + uintptr(i*size)))
+			m.key = C.CString(k)
+			defer C.free(unsafe.Pointer(m.key))
+			m.value = C.CString(v)
+			defer C.free(unsafe.Pointer(m.value))
+			i++
+		}
+	}
+
+	ret := C.ceph_mksnap(
+		mount.mount,
+		cDir,
+		cName,
+		C.mode_t(mode),
+		cMeta,
+		C.size_t(len(metadata)))
+	return getError(ret)
+}
+
+// GetSnapshotMetadata returns the id and the metadata of the snapshot with
+// the given name of the directory dir.
+//
+// Implements:
+//  int ceph_get_snap_info(struct ceph_mount_info *cmount, const char *path,
+//                         struct snap_info *snap_info);
+//  void ceph_free_snap_info_buffer(struct snap_info *snap_info);
+func (mount *MountInfo) GetSnapshotMetadata(dir, name string) (*SnapshotMetadata, error) {
+	if err := mount.validate(); err != nil {
+		return nil, err
+	}
+	p, err := mount.SnapshotPath(dir, name)
+	if err != nil {
+		return nil, err
+	}
+	cPath := C.CString(p)
+	defer C.free(unsafe.Pointer(cPath))
+
+	var info C.struct_snap_info
+	ret := C.ceph_get_snap_info(mount.mount, cPath, &info)
+	if err := getError(ret); err != nil {
+		return nil, err
+	}
+	defer C.ceph_free_snap_info_buffer(&info)
+
+	sm := &SnapshotMetadata{
+		ID:       uint64(info.id),
+		Metadata: make(map[string]string, int(info.nr_snap_metadata)),
+	}
+	size := unsafe.Sizeof(*info.snap_metadata)
+	for i := 0; i < int(info.nr_snap_metadata); i++ {
+		m := (*C.struct_snap_metadata)(unsafe.Pointer(
+			uintptr(unsafe.Pointer(info.snap_metadata)) + uintptr(i)*size))
+		sm.Metadata[C.GoString(m.key)] = C.GoString(m.value)
+	}
+	return sm, nil
+}
@@ -0,0 +1,39 @@
+// +build !luminous,!mimic,!nautilus,!octopus
+
+package cephfs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotMetadata(t *testing.T) {
+	mount := fsConnect(t)
+	defer fsDisconnect(t, mount)
+
+	dir := "/snapmeta"
+	require.NoError(t, mount.MakeDir(dir, 0755))
+	defer func() { assert.NoError(t, mount.RemoveDir(dir)) }()
+
+	meta := map[string]string{"owner": "project-a", "reason": "nightly"}
+	err := mount.MakeSnapshotWithMetadata(dir, "snap1", 0755, meta)
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, mount.RemoveSnapshot(dir, "snap1")) }()
+
+	sm, err := mount.GetSnapshotMetadata(dir, "snap1")
+	require.NoError(t, err)
+	assert.NotZero(t, sm.ID)
+	assert.Equal(t, meta, sm.Metadata)
+
+	err = mount.MakeSnapshotWithMetadata(dir, "snap2", 0755, nil)
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, mount.RemoveSnapshot(dir, "snap2")) }()
+	sm, err = mount.GetSnapshotMetadata(dir, "snap2")
+	require.NoError(t, err)
+	assert.Len(t, sm.Metadata, 0)
+
+	_, err = mount.GetSnapshotMetadata(dir, "missing")
+	assert.Error(t, err)
+}
@@ -0,0 +1,120 @@
+package cephfs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseXattrTime(t *testing.T) {
+	ts, err := parseXattrTime([]byte("1610000000.000012345"))
+	assert.NoError(t, err)
+	assert.Equal(t, Timespec{Sec: 1610000000, Nsec: 12345}, ts)
+
+	ts, err = parseXattrTime([]byte("42\x00"))
+	assert.NoError(t, err)
+	assert.Equal(t, Timespec{Sec: 42}, ts)
+
+	_, err = parseXattrTime([]byte("soon"))
+	assert.Equal(t, errInvalid, err)
+}
+
+func TestSnapshots(t *testing.T) {
+	mount := fsConnect(t)
+	defer fsDisconnect(t, mount)
+
+	dir := "/snapshots"
+	require.NoError(t, mount.MkdirAll(dir+"/sub", 0755))
+	defer func() { assert.NoError(t, mount.RemoveAll(dir)) }()
+
+	snapDir, err := mount.SnapDir()
+	assert.NoError(t, err)
+	assert.Equal(t, ".snap", snapDir)
+
+	snaps, err := mount.ListSnapshots(dir)
+	assert.NoError(t, err)
+	assert.Len(t, snaps, 0)
+
+	err = mount.MakeSnapshot(dir, "snap1", 0755)
+	require.NoError(t, err)
+	err = mount.MakeSnapshot(dir, "snap2", 0755)
+	require.NoError(t, err)
+	err = mount.MakeSnapshot(dir, "snap2", 0755)
+	assert.Equal(t, errExist, err)
+
+	snaps, err = mount.ListSnapshots(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"snap1", "snap2"}, snaps)
+
+	// snapshots of parent directories are not listed
+	snaps, err = mount.ListSnapshots(dir + "/sub")
+	assert.NoError(t, err)
+	assert.Len(t, snaps, 0)
+
+	info, err := mount.GetSnapshotInfo(dir, "snap1")
+	require.NoError(t, err)
+	assert.Equal(t, "snap1", info.Name)
+	assert.Equal(t, dir+"/.snap/snap1", info.Path)
+	assert.True(t, info.Statx.isDir())
+
+	_, err = mount.GetSnapshotInfo(dir, "missing")
+	assert.Equal(t, errNoEntry, err)
+
+	assert.NoError(t, mount.RemoveSnapshot(dir, "snap1"))
+	assert.NoError(t, mount.RemoveSnapshot(dir, "snap2"))
+	assert.Equal(t, errNoEntry, mount.RemoveSnapshot(dir, "snap2"))
+	snaps, err = mount.ListSnapshots(dir)
+	assert.NoError(t, err)
+	assert.Len(t, snaps, 0)
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	mount := fsConnect(t)
+	defer fsDisconnect(t, mount)
+
+	dir := "/snapdiff"
+	require.NoError(t, mount.MkdirAll(dir+"/same/deep", 0755))
+	require.NoError(t, mount.MkdirAll(dir+"/changed", 0755))
+	require.NoError(t, mount.MkdirAll(dir+"/gone", 0755))
+	defer func() { assert.NoError(t, mount.RemoveAll(dir)) }()
+	writeTestFile(t, mount, dir+"/same/deep/file", "unchanged", 0644)
+	writeTestFile(t, mount, dir+"/changed/file", "before", 0644)
+	writeTestFile(t, mount, dir+"/changed/old", "old", 0644)
+
+	require.NoError(t, mount.MakeSnapshot(dir, "from", 0755))
+	defer mount.RemoveSnapshot(dir, "from")
+
+	writeTestFile(t, mount, dir+"/changed/file", "after!", 0644)
+	require.NoError(t, mount.Unlink(dir+"/changed/old"))
+	require.NoError(t, mount.RemoveDir(dir+"/gone"))
+	require.NoError(t, mount.MkdirAll(dir+"/new", 0755))
+	writeTestFile(t, mount, dir+"/new/file", "new", 0644)
+
+	require.NoError(t, mount.MakeSnapshot(dir, "to", 0755))
+	defer mount.RemoveSnapshot(dir, "to")
+
+	diff, err := mount.DiffSnapshots(dir, "from", "to")
+	require.NoError(t, err)
+	changes := map[string]SnapDiffType{}
+	for _, e := range diff {
+		assert.NotNil(t, e.Statx)
+		changes[e.Path] = e.Type
+	}
+	assert.Equal(t, map[string]SnapDiffType{
+		"changed":      SnapDiffModified,
+		"changed/file": SnapDiffModified,
+		"changed/old":  SnapDiffRemoved,
+		"gone":         SnapDiffRemoved,
+		"new":          SnapDiffAdded,
+		"new/file":     SnapDiffAdded,
+	}, changes)
+	assert.Equal(t, "modified", SnapDiffModified.String())
+
+	diff, err = mount.DiffSnapshots(dir, "to", "to")
+	assert.NoError(t, err)
+	assert.Len(t, diff, 0)
+
+	_, err = mount.DiffSnapshots(dir, "from", "missing")
+	assert.Equal(t, errNoEntry, err)
+}
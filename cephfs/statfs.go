@@ -57,7 +57,11 @@ func (mount *MountInfo) StatFS(path string) (*CephStatVFS, error) {
 	if ret != 0 {
 		return nil, getError(ret)
 	}
-	csfs := &CephStatVFS{
+	return cStructToCephStatVFS(statvfs), nil
+}
+
+func cStructToCephStatVFS(statvfs C.struct_statvfs) *CephStatVFS {
+	return &CephStatVFS{
 		Bsize:   int64(statvfs.f_bsize),
 		Frsize:  int64(statvfs.f_frsize),
 		Blocks:  uint64(statvfs.f_blocks),
@@ -70,5 +74,4 @@ func (mount *MountInfo) StatFS(path string) (*CephStatVFS, error) {
 		Flag:    int64(statvfs.f_flag),
 		Namemax: int64(statvfs.f_namemax),
 	}
-	return csfs, nil
 }
@@ -25,6 +25,8 @@ const (
 	StatxNlink = StatxMask(C.CEPH_STATX_NLINK)
 	// StatxUid requests the uid value be filled in.
 	StatxUid = StatxMask(C.CEPH_STATX_UID)
+	// StatxGid requests the gid value be filled in.
+	StatxGid = StatxMask(C.CEPH_STATX_GID)
 	// StatxRdev requests the rdev value be filled in.
 	StatxRdev = StatxMask(C.CEPH_STATX_RDEV)
 	// StatxAtime requests the access-time value be filled in.
@@ -124,10 +126,7 @@ func cStructToCephStatx(s C.struct_ceph_statx) *CephStatx {
 	}
 }
 
-/* TODO:
-   - enable later when we can test round -trips
-   - add time fields
-
+// toCStruct converts the CephStatx to a C struct ceph_statx.
 func (c *CephStatx) toCStruct() C.struct_ceph_statx {
 	var s C.struct_ceph_statx
 	s.stx_mask = C.uint32_t(c.Mask)
@@ -141,7 +140,10 @@ func (c *CephStatx) toCStruct() C.struct_ceph_statx {
 	s.stx_blocks = C.uint64_t(c.Blocks)
 	s.stx_dev = C.uint64_t(c.Dev)
 	s.stx_rdev = C.uint64_t(c.Rdev)
+	ts.CopyToCStruct(ts.Timespec(c.Atime), ts.CTimespecPtr(&s.stx_atime))
+	ts.CopyToCStruct(ts.Timespec(c.Ctime), ts.CTimespecPtr(&s.stx_ctime))
+	ts.CopyToCStruct(ts.Timespec(c.Mtime), ts.CTimespecPtr(&s.stx_mtime))
+	ts.CopyToCStruct(ts.Timespec(c.Btime), ts.CTimespecPtr(&s.stx_btime))
 	s.stx_version = C.uint64_t(c.Version)
 	return s
 }
-*/
@@ -28,3 +28,25 @@ func TestStatxFieldsRootDir(t *testing.T) {
 	assert.Equal(t, uint64(0), st.Rdev)
 	assert.Greater(t, st.Ctime.Sec, int64(1588711788))
 }
+
+func TestStatxToCStruct(t *testing.T) {
+	st := &CephStatx{
+		Mask:    StatxBasicStats | StatxBtime,
+		Blksize: 4096,
+		Nlink:   1,
+		Uid:     1000,
+		Gid:     1001,
+		Mode:    0100644,
+		Inode:   Inode(1099511627776),
+		Size:    12345,
+		Blocks:  8,
+		Dev:     3,
+		Rdev:    0,
+		Atime:   Timespec{Sec: 1600000001, Nsec: 1},
+		Ctime:   Timespec{Sec: 1600000002, Nsec: 2},
+		Mtime:   Timespec{Sec: 1600000003, Nsec: 3},
+		Btime:   Timespec{Sec: 1600000004, Nsec: 4},
+		Version: 7,
+	}
+	assert.Equal(t, st, cStructToCephStatx(st.toCStruct()))
+}
@@ -0,0 +1,463 @@
+package cephfs
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"syscall"
+)
+
+// SkipDir can be returned by a WalkFunc to skip the remaining entries of a
+// directory. If returned for a directory the directory is not walked, if
+// returned for any other entry the remaining entries of its parent directory
+// are skipped.
+var SkipDir = errors.New("skip this directory")
+
+// WalkFunc is the type of the function called by Walk for every file and
+// directory of the tree.
+//
+// The path argument is the path of the entry, starting with the root passed
+// to Walk. stx holds the stat information of the entry itself, symbolic links
+// are not followed. If the entry can not be stat'ed, or a directory can not
+// be read, the function is called with a non-nil err. If it returns nil for
+// an unreadable directory, the walk continues with the next entry.
+// Returning any error other than SkipDir stops the walk, and Walk returns
+// that error.
+type WalkFunc func(path string, stx *CephStatx, err error) error
+
+// isDir returns true if the mode of stx is the one of a directory.
+func (c *CephStatx) isDir() bool {
+	return uint32(c.Mode)&syscall.S_IFMT == syscall.S_IFDIR
+}
+
+// isSymlink returns true if the mode of stx is the one of a symbolic link.
+func (c *CephStatx) isSymlink() bool {
+	return uint32(c.Mode)&syscall.S_IFMT == syscall.S_IFLNK
+}
+
+// isRegular returns true if the mode of stx is the one of a regular file.
+func (c *CephStatx) isRegular() bool {
+	return uint32(c.Mode)&syscall.S_IFMT == syscall.S_IFREG
+}
+
+// readDirPlusAll returns the entries of the directory dir, without the "."
+// and ".." entries, sorted by name.
+func (mount *MountInfo) readDirPlusAll(dir string) ([]*DirEntryPlus, error) {
+	d, err := mount.OpenDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer d.Close()
+
+	var entries []*DirEntryPlus
+	for {
+		entry, err := d.ReadDirPlus(StatxBasicStats, AtSymlinkNofollow)
+		if err != nil {
+			return entries, err
+		}
+		if entry == nil {
+			break
+		}
+		if entry.Name() == "." || entry.Name() == ".." {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return entries, nil
+}
+
+// Walk walks the file tree rooted at root, calling fn for each file and
+// directory in the tree, including root, in lexical order. The stat
+// information of the entries is fetched together with the directory
+// listing, using ReadDirPlus, so no additional Statx call is made per entry.
+// Walk does not follow symbolic links.
+func (mount *MountInfo) Walk(root string, fn WalkFunc) error {
+	stx, err := mount.Statx(root, StatxBasicStats, AtSymlinkNofollow)
+	if err != nil {
+		err = fn(root, nil, err)
+	} else {
+		err = mount.walk(root, stx, fn)
+	}
+	if err == SkipDir {
+		return nil
+	}
+	return err
+}
+
+func (mount *MountInfo) walk(p string, stx *CephStatx, fn WalkFunc) error {
+	if !stx.isDir() {
+		return fn(p, stx, nil)
+	}
+
+	entries, err := mount.readDirPlusAll(p)
+	err1 := fn(p, stx, err)
+	if err != nil || err1 != nil {
+		// fn is called a single time for a directory, after it has been
+		// read, so a read error is reported by that call. Unlike
+		// path/filepath.Walk there is no second call for the error.
+		return err1
+	}
+
+	for _, entry := range entries {
+		err = mount.walk(path.Join(p, entry.Name()), entry.Statx(), fn)
+		if err != nil {
+			if !entry.Statx().isDir() || err != SkipDir {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WalkDirFunc is the type of the function called by WalkDir for every file
+// and directory of the tree.
+//
+// The path argument is the path of the entry, starting with the root passed
+// to WalkDir. d is the directory entry of the path, its file type is always
+// known. If the root can not be stat'ed the function is called with a nil d
+// and the error. If a directory can not be read the function is called a
+// second time for it, with the error, like io/fs.WalkDir does. Returning
+// SkipDir or any other error behaves as for a WalkFunc.
+type WalkDirFunc func(path string, d *DirEntry, err error) error
+
+// WalkDir walks the file tree rooted at root, calling fn for each file and
+// directory in the tree, including root, in lexical order. Unlike Walk no
+// stat information is fetched for the entries, only their file type, which
+// makes WalkDir cheaper if fn does not need more than the names.
+// WalkDir does not follow symbolic links.
+func (mount *MountInfo) WalkDir(root string, fn WalkDirFunc) error {
+	stx, err := mount.Statx(root, StatxBasicStats, AtSymlinkNofollow)
+	if err != nil {
+		err = fn(root, nil, err)
+	} else {
+		d := &DirEntry{
+			inode: stx.Inode,
+			name:  path.Base(root),
+			dtype: modeToDType(uint32(stx.Mode)),
+		}
+		err = mount.walkDir(root, d, fn)
+	}
+	if err == SkipDir {
+		return nil
+	}
+	return err
+}
+
+func (mount *MountInfo) walkDir(p string, d *DirEntry, fn WalkDirFunc) error {
+	if err := fn(p, d, nil); err != nil || d.DType() != DTypeDir {
+		if err == SkipDir && d.DType() == DTypeDir {
+			err = nil
+		}
+		return err
+	}
+
+	entries, err := mount.readDirAll(p)
+	if err != nil {
+		err = fn(p, d, err)
+		if err != nil {
+			if err == SkipDir {
+				err = nil
+			}
+			return err
+		}
+	}
+
+	for _, entry := range entries {
+		err = mount.walkDir(path.Join(p, entry.Name()), entry, fn)
+		if err != nil {
+			if err == SkipDir {
+				break
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// readDirAll returns the entries of the directory dir, without the "." and
+// ".." entries, sorted by name. Entries of an unknown file type are
+// stat'ed to find out their type.
+func (mount *MountInfo) readDirAll(dir string) ([]*DirEntry, error) {
+	d, err := mount.OpenDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer d.Close()
+
+	var entries []*DirEntry
+	for {
+		entry, err := d.ReadDir()
+		if err != nil {
+			return nil, err
+		}
+		if entry == nil {
+			break
+		}
+		if entry.Name() == "." || entry.Name() == ".." {
+			continue
+		}
+		if entry.dtype == DTypeUnknown {
+			stx, err := mount.Statx(
+				path.Join(dir, entry.Name()), StatxMode, AtSymlinkNofollow)
+			if err != nil {
+				return nil, err
+			}
+			entry.dtype = modeToDType(uint32(stx.Mode))
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return entries, nil
+}
+
+// modeToDType returns the directory entry type matching the file type bits
+// of mode.
+func modeToDType(mode uint32) DType {
+	switch mode & syscall.S_IFMT {
+	case syscall.S_IFBLK:
+		return DTypeBlk
+	case syscall.S_IFCHR:
+		return DTypeChr
+	case syscall.S_IFDIR:
+		return DTypeDir
+	case syscall.S_IFIFO:
+		return DTypeFIFO
+	case syscall.S_IFLNK:
+		return DTypeLnk
+	case syscall.S_IFREG:
+		return DTypeReg
+	case syscall.S_IFSOCK:
+		return DTypeSock
+	}
+	return DTypeUnknown
+}
+
+// MkdirAll creates the directory path, along with any missing parent
+// directories. The mode is used for all the directories that are created.
+// If path already is a directory MkdirAll does nothing.
+func (mount *MountInfo) MkdirAll(path string, mode uint32) error {
+	stx, err := mount.Statx(path, StatxMode, 0)
+	if err == nil {
+		if stx.isDir() {
+			return nil
+		}
+		return errNotDir
+	}
+
+	p := strings.TrimRight(path, "/")
+	if parent := parentDir(p); parent != "" {
+		if err = mount.MkdirAll(parent, mode); err != nil {
+			return err
+		}
+	}
+
+	err = mount.MakeDir(p, mode)
+	if err != nil {
+		// the directory may have been created concurrently
+		if stx, serr := mount.Statx(p, StatxMode, 0); serr == nil && stx.isDir() {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// parentDir returns the parent directory of the slash separated path p or
+// an empty string if p has no parent to create.
+func parentDir(p string) string {
+	i := strings.LastIndex(p, "/")
+	if i < 0 {
+		return ""
+	}
+	parent := strings.TrimRight(p[:i], "/")
+	if parent == "" {
+		return ""
+	}
+	return parent
+}
+
+// RemoveAll removes path and anything it contains. Symbolic links are
+// removed but not followed. If path does not exist RemoveAll returns nil.
+func (mount *MountInfo) RemoveAll(path string) error {
+	err := mount.Unlink(path)
+	if err == nil || err == errNoEntry {
+		return nil
+	}
+
+	stx, serr := mount.Statx(path, StatxMode, AtSymlinkNofollow)
+	if serr != nil {
+		if serr == errNoEntry {
+			return nil
+		}
+		return serr
+	}
+	if !stx.isDir() {
+		return err
+	}
+	return mount.removeTree(path)
+}
+
+func (mount *MountInfo) removeTree(dir string) error {
+	entries, err := mount.readDirPlusAll(dir)
+	if err != nil && err != errNoEntry {
+		return err
+	}
+	for _, entry := range entries {
+		p := path.Join(dir, entry.Name())
+		if entry.Statx().isDir() {
+			err = mount.removeTree(p)
+		} else {
+			err = mount.Unlink(p)
+		}
+		if err != nil && err != errNoEntry {
+			return err
+		}
+	}
+	err = mount.RemoveDir(dir)
+	if err != nil && err != errNoEntry {
+		return err
+	}
+	return nil
+}
+
+// CopyTree copies the file tree rooted at src to dst. If dstMount is nil
+// the tree is copied within mount, otherwise it is copied to the file system
+// of dstMount. The mode, ownership and extended attributes of the files and
+// directories are preserved. Symbolic links are copied as symbolic links and
+// not followed. Hard links are copied as separate files. Special files, like
+// devices and named pipes, are skipped. Extended attributes in the "ceph."
+// namespace are virtual and are not copied.
+//
+// Copying the ownership of files requires the permission to change it on the
+// destination. dst must not exist yet.
+func (mount *MountInfo) CopyTree(src string, dstMount *MountInfo, dst string) error {
+	if dstMount == nil {
+		dstMount = mount
+	}
+	stx, err := mount.Statx(src, StatxBasicStats, AtSymlinkNofollow)
+	if err != nil {
+		return err
+	}
+	return copyEntry(mount, src, stx, dstMount, dst)
+}
+
+func copyEntry(srcMount *MountInfo, src string, stx *CephStatx,
+	dstMount *MountInfo, dst string) error {
+
+	switch {
+	case stx.isDir():
+		return copyDir(srcMount, src, stx, dstMount, dst)
+	case stx.isSymlink():
+		target, err := srcMount.Readlink(src)
+		if err != nil {
+			return err
+		}
+		if err = dstMount.Symlink(target, dst); err != nil {
+			return err
+		}
+		if err = copyXattrs(srcMount, src, dstMount, dst, true); err != nil {
+			return err
+		}
+		return dstMount.Lchown(dst, stx.Uid, stx.Gid)
+	case stx.isRegular():
+		if err := copyFile(srcMount, src, stx, dstMount, dst); err != nil {
+			return err
+		}
+		return copyAttrs(srcMount, src, stx, dstMount, dst)
+	}
+	return nil
+}
+
+func copyDir(srcMount *MountInfo, src string, stx *CephStatx,
+	dstMount *MountInfo, dst string) error {
+
+	// the directory gets its final mode after its content has been copied,
+	// the mode of the source may not allow adding entries to it
+	if err := dstMount.MakeDir(dst, 0700); err != nil {
+		return err
+	}
+	entries, err := srcMount.readDirPlusAll(src)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		err = copyEntry(
+			srcMount, path.Join(src, entry.Name()), entry.Statx(),
+			dstMount, path.Join(dst, entry.Name()))
+		if err != nil {
+			return err
+		}
+	}
+	return copyAttrs(srcMount, src, stx, dstMount, dst)
+}
+
+func copyFile(srcMount *MountInfo, src string, stx *CephStatx,
+	dstMount *MountInfo, dst string) error {
+
+	in, err := srcMount.Open(src, os.O_RDONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := dstMount.Open(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if stx.Size > 0 {
+		_, err = io.CopyBuffer(out, in, make([]byte, 4*1024*1024))
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// copyAttrs copies the extended attributes, the ownership and the mode of a
+// file or directory. The mode is set last so that setuid and setgid bits are
+// not cleared by changing the ownership.
+func copyAttrs(srcMount *MountInfo, src string, stx *CephStatx,
+	dstMount *MountInfo, dst string) error {
+
+	if err := copyXattrs(srcMount, src, dstMount, dst, false); err != nil {
+		return err
+	}
+	if err := dstMount.Chown(dst, stx.Uid, stx.Gid); err != nil {
+		return err
+	}
+	return dstMount.Chmod(dst, uint32(stx.Mode)&^syscall.S_IFMT)
+}
+
+func copyXattrs(srcMount *MountInfo, src string,
+	dstMount *MountInfo, dst string, noFollow bool) error {
+
+	list, get, set := srcMount.ListXattr, srcMount.GetXattr, dstMount.SetXattr
+	if noFollow {
+		list, get, set = srcMount.LlistXattr, srcMount.LgetXattr, dstMount.LsetXattr
+	}
+	names, err := list(src)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if strings.HasPrefix(name, "ceph.") {
+			continue
+		}
+		value, err := get(src, name)
+		if err != nil {
+			return err
+		}
+		if err = set(dst, name, value, XattrDefault); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,274 @@
+package cephfs
+
+import (
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParentDir(t *testing.T) {
+	assert.Equal(t, "", parentDir("a"))
+	assert.Equal(t, "", parentDir("/a"))
+	assert.Equal(t, "a", parentDir("a/b"))
+	assert.Equal(t, "/a/b", parentDir("/a/b//c"))
+}
+
+func writeTestFile(t *testing.T, mount *MountInfo, name, content string, mode uint32) {
+	f, err := mount.Open(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	require.NoError(t, err)
+	_, err = f.Write([]byte(content))
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+}
+
+func readTestFile(t *testing.T, mount *MountInfo, name string) string {
+	f, err := mount.Open(name, os.O_RDONLY, 0)
+	require.NoError(t, err)
+	defer f.Close()
+	buf := make([]byte, 1024)
+	n, err := f.Read(buf)
+	assert.NoError(t, err)
+	return string(buf[:n])
+}
+
+func TestMkdirAllRemoveAll(t *testing.T) {
+	mount := fsConnect(t)
+	defer fsDisconnect(t, mount)
+
+	err := mount.MkdirAll("/tree1/a/b/c", 0755)
+	assert.NoError(t, err)
+	// existing directories are fine
+	err = mount.MkdirAll("/tree1/a/b/c/", 0755)
+	assert.NoError(t, err)
+	err = mount.MkdirAll("/tree1/a/d", 0700)
+	assert.NoError(t, err)
+
+	stx, err := mount.Statx("/tree1/a/d", StatxMode, 0)
+	require.NoError(t, err)
+	assert.True(t, stx.isDir())
+	assert.Equal(t, uint16(0700), stx.Mode&0777)
+
+	writeTestFile(t, mount, "/tree1/a/file", "data", 0644)
+	err = mount.MkdirAll("/tree1/a/file/x", 0755)
+	assert.Equal(t, errNotDir, err)
+	err = mount.Symlink("/tree1/a/d", "/tree1/a/b/link")
+	assert.NoError(t, err)
+
+	err = mount.RemoveAll("/tree1")
+	assert.NoError(t, err)
+	_, err = mount.Statx("/tree1", StatxMode, 0)
+	assert.Equal(t, errNoEntry, err)
+
+	// a missing path is not an error
+	err = mount.RemoveAll("/tree1")
+	assert.NoError(t, err)
+}
+
+func TestWalk(t *testing.T) {
+	mount := fsConnect(t)
+	defer fsDisconnect(t, mount)
+
+	require.NoError(t, mount.MkdirAll("/walk/a/aa", 0755))
+	require.NoError(t, mount.MkdirAll("/walk/b", 0755))
+	defer func() { assert.NoError(t, mount.RemoveAll("/walk")) }()
+	writeTestFile(t, mount, "/walk/a/f1", "1", 0644)
+	writeTestFile(t, mount, "/walk/b/f2", "22", 0644)
+	writeTestFile(t, mount, "/walk/b/f3", "333", 0644)
+	require.NoError(t, mount.Symlink("a", "/walk/link"))
+
+	t.Run("all", func(t *testing.T) {
+		var paths []string
+		err := mount.Walk("/walk", func(p string, stx *CephStatx, err error) error {
+			assert.NoError(t, err)
+			assert.NotNil(t, stx)
+			paths = append(paths, p)
+			if p == "/walk/b/f3" {
+				assert.Equal(t, uint64(3), stx.Size)
+			}
+			if p == "/walk/link" {
+				assert.True(t, stx.isSymlink())
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"/walk",
+			"/walk/a",
+			"/walk/a/aa",
+			"/walk/a/f1",
+			"/walk/b",
+			"/walk/b/f2",
+			"/walk/b/f3",
+			"/walk/link",
+		}, paths)
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		var paths []string
+		err := mount.Walk("/walk", func(p string, stx *CephStatx, err error) error {
+			paths = append(paths, p)
+			if p == "/walk/a" || p == "/walk/b/f2" {
+				return SkipDir
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"/walk",
+			"/walk/a",
+			"/walk/b",
+			"/walk/b/f2",
+			"/walk/link",
+		}, paths)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		called := false
+		err := mount.Walk("/walk/missing", func(p string, stx *CephStatx, err error) error {
+			called = true
+			assert.Nil(t, stx)
+			return err
+		})
+		assert.True(t, called)
+		assert.Equal(t, errNoEntry, err)
+	})
+}
+
+func TestWalkDir(t *testing.T) {
+	mount := fsConnect(t)
+	defer fsDisconnect(t, mount)
+
+	require.NoError(t, mount.MkdirAll("/walkdir/a/aa", 0755))
+	require.NoError(t, mount.MkdirAll("/walkdir/b", 0755))
+	defer func() { assert.NoError(t, mount.RemoveAll("/walkdir")) }()
+	writeTestFile(t, mount, "/walkdir/a/f1", "1", 0644)
+	writeTestFile(t, mount, "/walkdir/b/f2", "22", 0644)
+	writeTestFile(t, mount, "/walkdir/b/f3", "333", 0644)
+	require.NoError(t, mount.Symlink("a", "/walkdir/link"))
+
+	t.Run("all", func(t *testing.T) {
+		var paths []string
+		err := mount.WalkDir("/walkdir", func(p string, d *DirEntry, err error) error {
+			assert.NoError(t, err)
+			paths = append(paths, p)
+			switch p {
+			case "/walkdir":
+				assert.Equal(t, "walkdir", d.Name())
+				assert.Equal(t, DTypeDir, d.DType())
+			case "/walkdir/b/f3":
+				assert.Equal(t, DTypeReg, d.DType())
+			case "/walkdir/link":
+				assert.Equal(t, DTypeLnk, d.DType())
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"/walkdir",
+			"/walkdir/a",
+			"/walkdir/a/aa",
+			"/walkdir/a/f1",
+			"/walkdir/b",
+			"/walkdir/b/f2",
+			"/walkdir/b/f3",
+			"/walkdir/link",
+		}, paths)
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		var paths []string
+		err := mount.WalkDir("/walkdir", func(p string, d *DirEntry, err error) error {
+			paths = append(paths, p)
+			if p == "/walkdir/a" || p == "/walkdir/b/f2" {
+				return SkipDir
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"/walkdir",
+			"/walkdir/a",
+			"/walkdir/b",
+			"/walkdir/b/f2",
+			"/walkdir/link",
+		}, paths)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		called := false
+		err := mount.WalkDir("/walkdir/missing", func(p string, d *DirEntry, err error) error {
+			called = true
+			assert.Nil(t, d)
+			return err
+		})
+		assert.True(t, called)
+		assert.Equal(t, errNoEntry, err)
+	})
+}
+
+func TestModeToDType(t *testing.T) {
+	assert.Equal(t, DTypeDir, modeToDType(syscall.S_IFDIR|0755))
+	assert.Equal(t, DTypeReg, modeToDType(syscall.S_IFREG|0644))
+	assert.Equal(t, DTypeLnk, modeToDType(syscall.S_IFLNK|0777))
+	assert.Equal(t, DTypeFIFO, modeToDType(syscall.S_IFIFO))
+	assert.Equal(t, DTypeUnknown, modeToDType(0))
+}
+
+func TestCopyTree(t *testing.T) {
+	mount := fsConnect(t)
+	defer fsDisconnect(t, mount)
+
+	require.NoError(t, mount.MkdirAll("/copysrc/sub", 0750))
+	defer func() {
+		assert.NoError(t, mount.Chmod("/copysrc/sub", 0750))
+		assert.NoError(t, mount.RemoveAll("/copysrc"))
+	}()
+	writeTestFile(t, mount, "/copysrc/sub/file", "copied data", 0640)
+	require.NoError(t, mount.Chmod("/copysrc/sub", 0550))
+	require.NoError(t, mount.SetXattr(
+		"/copysrc/sub/file", "user.origin", []byte("src"), XattrDefault))
+	require.NoError(t, mount.Symlink("sub/file", "/copysrc/link"))
+
+	err := mount.CopyTree("/copysrc", nil, "/copydst")
+	assert.NoError(t, err)
+	defer func() {
+		assert.NoError(t, mount.Chmod("/copydst/sub", 0750))
+		assert.NoError(t, mount.RemoveAll("/copydst"))
+	}()
+
+	// the destination must not exist
+	err = mount.CopyTree("/copysrc", nil, "/copydst")
+	assert.Equal(t, errExist, err)
+
+	assert.Equal(t, "copied data", readTestFile(t, mount, "/copydst/sub/file"))
+	stx, err := mount.Statx("/copydst/sub", StatxMode, 0)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(0550), stx.Mode&0777)
+	stx, err = mount.Statx("/copydst/sub/file", StatxBasicStats, 0)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(0640), stx.Mode&0777)
+
+	value, err := mount.GetXattr("/copydst/sub/file", "user.origin")
+	assert.NoError(t, err)
+	assert.Equal(t, "src", string(value))
+
+	target, err := mount.Readlink("/copydst/link")
+	assert.NoError(t, err)
+	assert.Equal(t, "sub/file", target)
+
+	t.Run("otherMount", func(t *testing.T) {
+		other := fsConnect(t)
+		defer fsDisconnect(t, other)
+
+		err := mount.CopyTree("/copysrc/sub", other, "/copyother")
+		assert.NoError(t, err)
+		defer func() {
+			assert.NoError(t, other.Chmod("/copyother", 0750))
+			assert.NoError(t, other.RemoveAll("/copyother"))
+		}()
+		assert.Equal(t, "copied data", readTestFile(t, other, "/copyother/file"))
+	})
+}
@@ -0,0 +1,72 @@
+package admin
+
+import (
+	"encoding/json"
+
+	"github.com/ceph/go-ceph/internal/commands"
+	"github.com/ceph/go-ceph/rados"
+)
+
+// RadosCommander provides an interface to execute JSON-formatted commands that
+// allow the administrative functions to interact with the Ceph cluster.
+type RadosCommander = commands.RadosCommander
+
+// ClusterAdmin is used to administrate a ceph cluster.
+type ClusterAdmin struct {
+	conn RadosCommander
+}
+
+// New creates a ClusterAdmin automatically based on the default ceph
+// configuration file. If more customization is needed, create a
+// *rados.Conn as you see fit and use NewFromConn to use that
+// connection with these administrative functions.
+func New() (*ClusterAdmin, error) {
+	conn, err := rados.NewConn()
+	if err != nil {
+		return nil, err
+	}
+	err = conn.ReadDefaultConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	err = conn.Connect()
+	if err != nil {
+		return nil, err
+	}
+	return NewFromConn(conn), nil
+}
+
+// NewFromConn creates a ClusterAdmin management object from a preexisting
+// rados connection. The existing connection can be rados.Conn or any
+// type implementing the RadosCommander interface. This may be useful
+// if the calling layer needs to inject additional logging, error handling,
+// fault injection, etc.
+func NewFromConn(conn RadosCommander) *ClusterAdmin {
+	return &ClusterAdmin{conn}
+}
+
+func (ca *ClusterAdmin) validate() error {
+	if ca.conn == nil {
+		return rados.ErrNotConnected
+	}
+	return nil
+}
+
+// rawMonCommand takes a byte buffer and sends it to the MON as a command.
+// The buffer is expected to contain preformatted JSON.
+func (ca *ClusterAdmin) rawMonCommand(buf []byte) commands.Response {
+	if err := ca.validate(); err != nil {
+		return commands.NewResponse(nil, "", err)
+	}
+	return commands.NewResponse(ca.conn.MonCommand(buf))
+}
+
+// marshalMonCommand takes an generic interface{} value, converts it to JSON and
+// sends the json to the MON as a command.
+func (ca *ClusterAdmin) marshalMonCommand(v interface{}) commands.Response {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return commands.NewResponse(nil, "", err)
+	}
+	return ca.rawMonCommand(b)
+}
@@ -0,0 +1,67 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ceph/go-ceph/rados"
+)
+
+// fakeCommander replays a recorded response and keeps the commands it
+// receives so that the tests can check them.
+type fakeCommander struct {
+	body   string
+	status string
+	err    error
+
+	cmds []map[string]interface{}
+}
+
+func (f *fakeCommander) MgrCommand(buf [][]byte) ([]byte, string, error) {
+	return nil, "", errors.New("unexpected mgr command")
+}
+
+func (f *fakeCommander) MonCommand(buf []byte) ([]byte, string, error) {
+	var m map[string]interface{}
+	if err := json.Unmarshal(buf, &m); err != nil {
+		return nil, "", err
+	}
+	f.cmds = append(f.cmds, m)
+	return []byte(f.body), f.status, f.err
+}
+
+func (f *fakeCommander) lastCmd() map[string]interface{} {
+	if len(f.cmds) == 0 {
+		return nil
+	}
+	return f.cmds[len(f.cmds)-1]
+}
+
+func TestInvalidClusterAdmin(t *testing.T) {
+	ca := &ClusterAdmin{}
+	_, err := ca.Status()
+	assert.Equal(t, rados.ErrNotConnected, errors.Unwrap(err))
+}
+
+func TestCommandError(t *testing.T) {
+	e := errors.New("boom")
+	fc := &fakeCommander{status: "something failed", err: e}
+	ca := NewFromConn(fc)
+
+	_, err := ca.Df()
+	require.Error(t, err)
+	assert.Equal(t, e, errors.Unwrap(err))
+	assert.Contains(t, err.Error(), "something failed")
+}
+
+func TestUnexpectedStatus(t *testing.T) {
+	fc := &fakeCommander{body: "{}", status: "unexpected"}
+	ca := NewFromConn(fc)
+
+	_, err := ca.OsdTree()
+	assert.Error(t, err)
+}
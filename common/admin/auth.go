@@ -0,0 +1,84 @@
+package admin
+
+import (
+	"errors"
+	"sort"
+)
+
+// ErrEntityNotFound may be returned if the response to an auth query does
+// not contain the requested entity.
+var ErrEntityNotFound = errors.New("auth entity not found")
+
+// AuthEntity is an entity (e.g. "client.admin") of the cluster's
+// authentication database along with its key and capabilities.
+type AuthEntity struct {
+	Entity string `json:"entity"`
+	Key    string `json:"key"`
+	// Caps maps a daemon type (e.g. "mon", "osd") to the capability
+	// string granted for it.
+	Caps map[string]string `json:"caps"`
+}
+
+// AuthGet returns the key and capabilities of the named entity.
+//
+// Similar To:
+//  ceph auth get <entity>
+func (ca *ClusterAdmin) AuthGet(entity string) (*AuthEntity, error) {
+	var r []AuthEntity
+	// the status reports the exported keyring, it is not an error
+	err := ca.marshalMonCommand(map[string]string{
+		"prefix": "auth get",
+		"entity": entity,
+		"format": "json",
+	}).Unmarshal(&r).End()
+	if err != nil {
+		return nil, err
+	}
+	if len(r) == 0 {
+		return nil, ErrEntityNotFound
+	}
+	return &r[0], nil
+}
+
+// AuthAdd adds the named entity to the authentication database with a newly
+// generated key and the given capabilities.
+//
+// Similar To:
+//  ceph auth add <entity> <daemon-type> <caps>...
+func (ca *ClusterAdmin) AuthAdd(entity string, caps map[string]string) error {
+	m := map[string]interface{}{
+		"prefix": "auth add",
+		"entity": entity,
+		"caps":   flattenCaps(caps),
+	}
+	// the status reports the added key, it is not an error
+	return ca.marshalMonCommand(m).NoBody().End()
+}
+
+// AuthRm removes the named entity from the authentication database.
+//
+// Similar To:
+//  ceph auth rm <entity>
+func (ca *ClusterAdmin) AuthRm(entity string) error {
+	m := map[string]string{
+		"prefix": "auth rm",
+		"entity": entity,
+	}
+	return ca.marshalMonCommand(m).NoBody().End()
+}
+
+// flattenCaps converts a map of capabilities to the flat list of daemon
+// type and capability pairs expected by the auth commands. The daemon types
+// are sorted to give a stable command.
+func flattenCaps(caps map[string]string) []string {
+	types := make([]string, 0, len(caps))
+	for t := range caps {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	flat := make([]string, 0, 2*len(caps))
+	for _, t := range types {
+		flat = append(flat, t, caps[t])
+	}
+	return flat
+}
@@ -0,0 +1,62 @@
+package admin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthGet(t *testing.T) {
+	fc := &fakeCommander{
+		body: `[{"entity": "client.admin",
+		         "key": "AQBvXhdfAAAAABAAx1ib6o5S+Uq5Mvb1K0tCvQ==",
+		         "caps": {"mds": "allow *", "mgr": "allow *",
+		                  "mon": "allow *", "osd": "allow *"}}]`,
+		status: "exported keyring for client.admin",
+	}
+	ca := NewFromConn(fc)
+
+	e, err := ca.AuthGet("client.admin")
+	require.NoError(t, err)
+	assert.Equal(t, "auth get", fc.lastCmd()["prefix"])
+	assert.Equal(t, "client.admin", fc.lastCmd()["entity"])
+	assert.Equal(t, "client.admin", e.Entity)
+	assert.Equal(t, "AQBvXhdfAAAAABAAx1ib6o5S+Uq5Mvb1K0tCvQ==", e.Key)
+	assert.Equal(t, "allow *", e.Caps["mon"])
+	assert.Len(t, e.Caps, 4)
+
+	fc.body = "[]"
+	_, err = ca.AuthGet("client.admin")
+	assert.Equal(t, ErrEntityNotFound, err)
+}
+
+func TestAuthAdd(t *testing.T) {
+	fc := &fakeCommander{status: "added key for client.foo"}
+	ca := NewFromConn(fc)
+
+	err := ca.AuthAdd("client.foo", map[string]string{
+		"osd": "allow rw pool=foo",
+		"mon": "allow r",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "auth add", fc.lastCmd()["prefix"])
+	assert.Equal(t, "client.foo", fc.lastCmd()["entity"])
+	assert.Equal(t,
+		[]interface{}{"mon", "allow r", "osd", "allow rw pool=foo"},
+		fc.lastCmd()["caps"])
+}
+
+func TestAuthRm(t *testing.T) {
+	fc := &fakeCommander{status: "updated"}
+	ca := NewFromConn(fc)
+
+	err := ca.AuthRm("client.foo")
+	require.NoError(t, err)
+	assert.Equal(t, "auth rm", fc.lastCmd()["prefix"])
+	assert.Equal(t, "client.foo", fc.lastCmd()["entity"])
+
+	fc.body = "unexpected"
+	err = ca.AuthRm("client.foo")
+	assert.Error(t, err)
+}
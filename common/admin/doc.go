@@ -0,0 +1,10 @@
+/*
+Package admin is a convenience layer to support the administration of a
+ceph cluster through typed wrappers of the commonly used mon commands, such
+as "status", "df", "osd tree" or "auth get".
+
+Unlike the rados package this API does not map to APIs provided by
+ceph libraries themselves. This API is not yet stable and is subject
+to change.
+*/
+package admin
@@ -0,0 +1,144 @@
+package admin
+
+// OsdTreeNode is a node of the CRUSH hierarchy as reported by "osd tree".
+// Buckets (hosts, racks, the root, etc.) have a negative ID and list their
+// children, OSDs have a non-negative ID and report their state.
+type OsdTreeNode struct {
+	ID              int64   `json:"id"`
+	Name            string  `json:"name"`
+	Type            string  `json:"type"`
+	TypeID          int     `json:"type_id"`
+	Children        []int64 `json:"children"`
+	DeviceClass     string  `json:"device_class"`
+	CrushWeight     float64 `json:"crush_weight"`
+	Depth           int     `json:"depth"`
+	Exists          int     `json:"exists"`
+	Status          string  `json:"status"`
+	Reweight        float64 `json:"reweight"`
+	PrimaryAffinity float64 `json:"primary_affinity"`
+}
+
+// OsdTree is the CRUSH hierarchy of the cluster as reported by "osd tree".
+type OsdTree struct {
+	Nodes []OsdTreeNode `json:"nodes"`
+	// Stray lists the OSDs that are not part of the CRUSH hierarchy.
+	Stray []OsdTreeNode `json:"stray"`
+}
+
+// OsdTree returns the CRUSH hierarchy of the cluster.
+//
+// Similar To:
+//  ceph osd tree
+func (ca *ClusterAdmin) OsdTree() (*OsdTree, error) {
+	var t OsdTree
+	err := ca.marshalMonCommand(map[string]string{
+		"prefix": "osd tree",
+		"format": "json",
+	}).NoStatus().Unmarshal(&t).End()
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// OsdDfNode holds the utilization of an OSD or of a bucket of the CRUSH
+// hierarchy as reported by "osd df". Sizes are in KiB.
+type OsdDfNode struct {
+	ID          int64   `json:"id"`
+	Name        string  `json:"name"`
+	Type        string  `json:"type"`
+	DeviceClass string  `json:"device_class"`
+	CrushWeight float64 `json:"crush_weight"`
+	Reweight    float64 `json:"reweight"`
+	KB          uint64  `json:"kb"`
+	KBUsed      uint64  `json:"kb_used"`
+	KBUsedData  uint64  `json:"kb_used_data"`
+	KBUsedOmap  uint64  `json:"kb_used_omap"`
+	KBUsedMeta  uint64  `json:"kb_used_meta"`
+	KBAvail     uint64  `json:"kb_avail"`
+	Utilization float64 `json:"utilization"`
+	Var         float64 `json:"var"`
+	PGs         int     `json:"pgs"`
+	Status      string  `json:"status"`
+}
+
+// OsdDfSummary holds the utilization totals of "osd df". Sizes are in KiB.
+type OsdDfSummary struct {
+	TotalKB            uint64  `json:"total_kb"`
+	TotalKBUsed        uint64  `json:"total_kb_used"`
+	TotalKBUsedData    uint64  `json:"total_kb_used_data"`
+	TotalKBUsedOmap    uint64  `json:"total_kb_used_omap"`
+	TotalKBUsedMeta    uint64  `json:"total_kb_used_meta"`
+	TotalKBAvail       uint64  `json:"total_kb_avail"`
+	AverageUtilization float64 `json:"average_utilization"`
+	MinVar             float64 `json:"min_var"`
+	MaxVar             float64 `json:"max_var"`
+	Dev                float64 `json:"dev"`
+}
+
+// OsdDf is the utilization of the OSDs of the cluster as reported by
+// "osd df".
+type OsdDf struct {
+	Nodes   []OsdDfNode  `json:"nodes"`
+	Stray   []OsdDfNode  `json:"stray"`
+	Summary OsdDfSummary `json:"summary"`
+}
+
+// OsdDf returns the utilization of the OSDs of the cluster.
+//
+// Similar To:
+//  ceph osd df
+func (ca *ClusterAdmin) OsdDf() (*OsdDf, error) {
+	var d OsdDf
+	err := ca.marshalMonCommand(map[string]string{
+		"prefix": "osd df",
+		"format": "json",
+	}).NoStatus().Unmarshal(&d).End()
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// PoolDetail describes a pool as reported by "osd pool ls detail".
+type PoolDetail struct {
+	ID                  int64                        `json:"pool_id"`
+	Name                string                       `json:"pool_name"`
+	Flags               uint64                       `json:"flags"`
+	FlagsNames          string                       `json:"flags_names"`
+	Type                int                          `json:"type"`
+	Size                int                          `json:"size"`
+	MinSize             int                          `json:"min_size"`
+	CrushRule           int                          `json:"crush_rule"`
+	PGAutoscaleMode     string                       `json:"pg_autoscale_mode"`
+	PGNum               int                          `json:"pg_num"`
+	PGPlacementNum      int                          `json:"pg_placement_num"`
+	SnapSeq             uint64                       `json:"snap_seq"`
+	QuotaMaxBytes       uint64                       `json:"quota_max_bytes"`
+	QuotaMaxObjects     uint64                       `json:"quota_max_objects"`
+	Tiers               []int64                      `json:"tiers"`
+	TierOf              int64                        `json:"tier_of"`
+	ReadTier            int64                        `json:"read_tier"`
+	WriteTier           int64                        `json:"write_tier"`
+	CacheMode           string                       `json:"cache_mode"`
+	ErasureCodeProfile  string                       `json:"erasure_code_profile"`
+	ApplicationMetadata map[string]map[string]string `json:"application_metadata"`
+}
+
+// ListPoolsDetail returns a detailed description of all the pools of the
+// cluster.
+//
+// Similar To:
+//  ceph osd pool ls detail
+func (ca *ClusterAdmin) ListPoolsDetail() ([]PoolDetail, error) {
+	var p []PoolDetail
+	err := ca.marshalMonCommand(map[string]string{
+		"prefix": "osd pool ls",
+		"detail": "detail",
+		"format": "json",
+	}).NoStatus().Unmarshal(&p).End()
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
@@ -0,0 +1,129 @@
+package admin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var sampleOsdTree = `{
+  "nodes": [
+    {"id": -1, "name": "default", "type": "root", "type_id": 11,
+     "children": [-3]},
+    {"id": -3, "name": "node1", "type": "host", "type_id": 1,
+     "pool_weights": {}, "children": [1, 0]},
+    {"id": 0, "device_class": "hdd", "name": "osd.0", "type": "osd",
+     "type_id": 0, "crush_weight": 0.0099945068359375, "depth": 2,
+     "pool_weights": {}, "exists": 1, "status": "up", "reweight": 1,
+     "primary_affinity": 1},
+    {"id": 1, "device_class": "hdd", "name": "osd.1", "type": "osd",
+     "type_id": 0, "crush_weight": 0.0099945068359375, "depth": 2,
+     "pool_weights": {}, "exists": 1, "status": "down", "reweight": 0,
+     "primary_affinity": 1}
+  ],
+  "stray": []
+}`
+
+func TestOsdTree(t *testing.T) {
+	fc := &fakeCommander{body: sampleOsdTree}
+	ca := NewFromConn(fc)
+
+	tree, err := ca.OsdTree()
+	require.NoError(t, err)
+	assert.Equal(t, "osd tree", fc.lastCmd()["prefix"])
+	assert.Equal(t, "json", fc.lastCmd()["format"])
+
+	require.Len(t, tree.Nodes, 4)
+	assert.Equal(t, "default", tree.Nodes[0].Name)
+	assert.Equal(t, []int64{-3}, tree.Nodes[0].Children)
+	assert.Equal(t, "host", tree.Nodes[1].Type)
+	assert.Equal(t, []int64{1, 0}, tree.Nodes[1].Children)
+	assert.Equal(t, int64(1), tree.Nodes[3].ID)
+	assert.Equal(t, "hdd", tree.Nodes[3].DeviceClass)
+	assert.Equal(t, "down", tree.Nodes[3].Status)
+	assert.Equal(t, 0.0, tree.Nodes[3].Reweight)
+	assert.Len(t, tree.Stray, 0)
+}
+
+var sampleOsdDf = `{
+  "nodes": [
+    {"id": 0, "device_class": "hdd", "name": "osd.0", "type": "osd",
+     "type_id": 0, "crush_weight": 0.0099945068359375, "depth": 2,
+     "pool_weights": {}, "reweight": 1, "kb": 10485760,
+     "kb_used": 1064028, "kb_used_data": 2748, "kb_used_omap": 0,
+     "kb_used_meta": 1048576, "kb_avail": 9421732,
+     "utilization": 10.147361755371094, "var": 1, "pgs": 24,
+     "status": "up"}
+  ],
+  "stray": [],
+  "summary": {
+    "total_kb": 10485760, "total_kb_used": 1064028,
+    "total_kb_used_data": 2748, "total_kb_used_omap": 0,
+    "total_kb_used_meta": 1048576, "total_kb_avail": 9421732,
+    "average_utilization": 10.147361755371094, "min_var": 1,
+    "max_var": 1, "dev": 0
+  }
+}`
+
+func TestOsdDf(t *testing.T) {
+	fc := &fakeCommander{body: sampleOsdDf}
+	ca := NewFromConn(fc)
+
+	df, err := ca.OsdDf()
+	require.NoError(t, err)
+	assert.Equal(t, "osd df", fc.lastCmd()["prefix"])
+
+	require.Len(t, df.Nodes, 1)
+	assert.Equal(t, "osd.0", df.Nodes[0].Name)
+	assert.Equal(t, uint64(10485760), df.Nodes[0].KB)
+	assert.Equal(t, uint64(9421732), df.Nodes[0].KBAvail)
+	assert.Equal(t, 24, df.Nodes[0].PGs)
+	assert.Equal(t, uint64(1064028), df.Summary.TotalKBUsed)
+	assert.InDelta(t, 10.147, df.Summary.AverageUtilization, 0.001)
+}
+
+var samplePoolLsDetail = `[
+  {"pool_id": 1, "pool_name": "device_health_metrics",
+   "create_time": "2020-07-21T15:11:54.134853+0000", "flags": 1,
+   "flags_names": "hashpspool", "type": 1, "size": 1, "min_size": 1,
+   "crush_rule": 0, "object_hash": 2, "pg_autoscale_mode": "on",
+   "pg_num": 1, "pg_placement_num": 1, "last_change": "15",
+   "snap_seq": 0, "snap_epoch": 0, "quota_max_bytes": 0,
+   "quota_max_objects": 0, "tiers": [], "tier_of": -1,
+   "read_tier": -1, "write_tier": -1, "cache_mode": "none",
+   "erasure_code_profile": "",
+   "application_metadata": {"mgr_devicehealth": {}}},
+  {"pool_id": 2, "pool_name": "rbd",
+   "create_time": "2020-07-21T15:12:10.405712+0000", "flags": 8193,
+   "flags_names": "hashpspool,selfmanaged_snaps", "type": 1, "size": 3,
+   "min_size": 2, "crush_rule": 0, "object_hash": 2,
+   "pg_autoscale_mode": "warn", "pg_num": 32, "pg_placement_num": 32,
+   "last_change": "40", "snap_seq": 3, "snap_epoch": 40,
+   "quota_max_bytes": 1073741824, "quota_max_objects": 0, "tiers": [],
+   "tier_of": -1, "read_tier": -1, "write_tier": -1,
+   "cache_mode": "none", "erasure_code_profile": "",
+   "application_metadata": {"rbd": {"foo": "bar"}}}
+]`
+
+func TestListPoolsDetail(t *testing.T) {
+	fc := &fakeCommander{body: samplePoolLsDetail}
+	ca := NewFromConn(fc)
+
+	pools, err := ca.ListPoolsDetail()
+	require.NoError(t, err)
+	assert.Equal(t, "osd pool ls", fc.lastCmd()["prefix"])
+	assert.Equal(t, "detail", fc.lastCmd()["detail"])
+
+	require.Len(t, pools, 2)
+	assert.Equal(t, int64(1), pools[0].ID)
+	assert.Equal(t, "device_health_metrics", pools[0].Name)
+	assert.Contains(t, pools[0].ApplicationMetadata, "mgr_devicehealth")
+	assert.Equal(t, "rbd", pools[1].Name)
+	assert.Equal(t, 3, pools[1].Size)
+	assert.Equal(t, 2, pools[1].MinSize)
+	assert.Equal(t, 32, pools[1].PGNum)
+	assert.Equal(t, int64(-1), pools[1].TierOf)
+	assert.Equal(t, uint64(1073741824), pools[1].QuotaMaxBytes)
+	assert.Equal(t, "bar", pools[1].ApplicationMetadata["rbd"]["foo"])
+}
@@ -0,0 +1,221 @@
+package admin
+
+import (
+	"encoding/json"
+)
+
+// HealthStatus is the overall health of the cluster, such as "HEALTH_OK",
+// "HEALTH_WARN" or "HEALTH_ERR".
+type HealthStatus string
+
+const (
+	// HealthOK indicates that the cluster is healthy.
+	HealthOK = HealthStatus("HEALTH_OK")
+	// HealthWarn indicates that the cluster is in a warning state.
+	HealthWarn = HealthStatus("HEALTH_WARN")
+	// HealthErr indicates that the cluster is in an error state.
+	HealthErr = HealthStatus("HEALTH_ERR")
+)
+
+// HealthCheckMessage is a message of a health check.
+type HealthCheckMessage struct {
+	Message string `json:"message"`
+	Count   int    `json:"count"`
+}
+
+// HealthCheck is a health check raised by the cluster.
+type HealthCheck struct {
+	Severity HealthStatus         `json:"severity"`
+	Summary  HealthCheckMessage   `json:"summary"`
+	Detail   []HealthCheckMessage `json:"detail"`
+	Muted    bool                 `json:"muted"`
+}
+
+// Health is the health of the cluster along with the active health checks,
+// keyed by the check code (e.g. "OSD_DOWN").
+type Health struct {
+	Status HealthStatus           `json:"status"`
+	Checks map[string]HealthCheck `json:"checks"`
+}
+
+// HealthDetail returns the health of the cluster including the details of
+// every active health check.
+//
+// Similar To:
+//  ceph health detail
+func (ca *ClusterAdmin) HealthDetail() (*Health, error) {
+	var h Health
+	err := ca.marshalMonCommand(map[string]string{
+		"prefix": "health",
+		"detail": "detail",
+		"format": "json",
+	}).NoStatus().Unmarshal(&h).End()
+	if err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+// DfStats holds the raw storage totals of the cluster, or of one device
+// class of the cluster, as reported by "df".
+type DfStats struct {
+	TotalBytes        uint64  `json:"total_bytes"`
+	TotalAvailBytes   uint64  `json:"total_avail_bytes"`
+	TotalUsedBytes    uint64  `json:"total_used_bytes"`
+	TotalUsedRawBytes uint64  `json:"total_used_raw_bytes"`
+	TotalUsedRawRatio float64 `json:"total_used_raw_ratio"`
+}
+
+// DfPoolStats holds the usage of a pool as reported by "df".
+type DfPoolStats struct {
+	Stored      uint64  `json:"stored"`
+	Objects     uint64  `json:"objects"`
+	KBUsed      uint64  `json:"kb_used"`
+	BytesUsed   uint64  `json:"bytes_used"`
+	PercentUsed float64 `json:"percent_used"`
+	MaxAvail    uint64  `json:"max_avail"`
+}
+
+// DfPool is a pool as reported by "df".
+type DfPool struct {
+	Name  string      `json:"name"`
+	ID    int64       `json:"id"`
+	Stats DfPoolStats `json:"stats"`
+}
+
+// Df is the storage usage of the cluster as reported by "df".
+type Df struct {
+	Stats        DfStats            `json:"stats"`
+	StatsByClass map[string]DfStats `json:"stats_by_class"`
+	Pools        []DfPool           `json:"pools"`
+}
+
+// Df returns the storage usage of the cluster and of its pools.
+//
+// Similar To:
+//  ceph df
+func (ca *ClusterAdmin) Df() (*Df, error) {
+	var d Df
+	err := ca.marshalMonCommand(map[string]string{
+		"prefix": "df",
+		"format": "json",
+	}).NoStatus().Unmarshal(&d).End()
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// PGStateCount is the number of placement groups in a given state.
+type PGStateCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"num"`
+}
+
+// PGStat is the summary of the placement groups of the cluster as reported
+// by "pg stat".
+type PGStat struct {
+	NumPGByState  []PGStateCount `json:"num_pg_by_state"`
+	NumPGs        int            `json:"num_pgs"`
+	NumBytes      uint64         `json:"num_bytes"`
+	RawBytesUsed  uint64         `json:"raw_bytes_used"`
+	RawBytesAvail uint64         `json:"raw_bytes_avail"`
+	RawBytes      uint64         `json:"raw_bytes"`
+}
+
+// PGStat returns the summary of the placement groups of the cluster.
+//
+// Similar To:
+//  ceph pg stat
+func (ca *ClusterAdmin) PGStat() (*PGStat, error) {
+	// octopus nests the summary in a pg_summary object, older versions
+	// return it directly
+	var r struct {
+		PGStat
+		Summary *PGStat `json:"pg_summary"`
+	}
+	err := ca.marshalMonCommand(map[string]string{
+		"prefix": "pg stat",
+		"format": "json",
+	}).NoStatus().Unmarshal(&r).End()
+	if err != nil {
+		return nil, err
+	}
+	if r.Summary != nil {
+		return r.Summary, nil
+	}
+	return &r.PGStat, nil
+}
+
+// OsdMapStatus is the summary of the OSD map in the cluster status.
+type OsdMapStatus struct {
+	Epoch          uint64 `json:"epoch"`
+	NumOsds        int    `json:"num_osds"`
+	NumUpOsds      int    `json:"num_up_osds"`
+	NumInOsds      int    `json:"num_in_osds"`
+	NumRemappedPGs int    `json:"num_remapped_pgs"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It accepts the
+// osdmap object nested in another osdmap object, as returned by nautilus.
+func (o *OsdMapStatus) UnmarshalJSON(b []byte) error {
+	type osdMapStatus OsdMapStatus
+	var r struct {
+		osdMapStatus
+		Nested *osdMapStatus `json:"osdmap"`
+	}
+	if err := json.Unmarshal(b, &r); err != nil {
+		return err
+	}
+	if r.Nested != nil {
+		*o = OsdMapStatus(*r.Nested)
+	} else {
+		*o = OsdMapStatus(r.osdMapStatus)
+	}
+	return nil
+}
+
+// PGStateStatus is the number of placement groups in a given state in the
+// cluster status.
+type PGStateStatus struct {
+	StateName string `json:"state_name"`
+	Count     int    `json:"count"`
+}
+
+// PGMapStatus is the summary of the placement groups in the cluster status.
+type PGMapStatus struct {
+	PGsByState []PGStateStatus `json:"pgs_by_state"`
+	NumPGs     int             `json:"num_pgs"`
+	NumPools   int             `json:"num_pools"`
+	NumObjects uint64          `json:"num_objects"`
+	DataBytes  uint64          `json:"data_bytes"`
+	BytesUsed  uint64          `json:"bytes_used"`
+	BytesAvail uint64          `json:"bytes_avail"`
+	BytesTotal uint64          `json:"bytes_total"`
+}
+
+// Status is the status of the cluster as reported by "status".
+type Status struct {
+	FSID        string       `json:"fsid"`
+	Health      Health       `json:"health"`
+	Quorum      []int        `json:"quorum"`
+	QuorumNames []string     `json:"quorum_names"`
+	OsdMap      OsdMapStatus `json:"osdmap"`
+	PGMap       PGMapStatus  `json:"pgmap"`
+}
+
+// Status returns the status of the cluster.
+//
+// Similar To:
+//  ceph status
+func (ca *ClusterAdmin) Status() (*Status, error) {
+	var s Status
+	err := ca.marshalMonCommand(map[string]string{
+		"prefix": "status",
+		"format": "json",
+	}).NoStatus().Unmarshal(&s).End()
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
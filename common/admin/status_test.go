@@ -0,0 +1,175 @@
+package admin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var sampleHealthDetail = `{
+  "status": "HEALTH_WARN",
+  "checks": {
+    "POOL_NO_REDUNDANCY": {
+      "severity": "HEALTH_WARN",
+      "summary": {"message": "1 pool(s) have no replicas configured",
+                  "count": 1},
+      "detail": [{"message": "pool 'rbd' has no replicas configured"}],
+      "muted": false
+    }
+  },
+  "mutes": []
+}`
+
+func TestHealthDetail(t *testing.T) {
+	fc := &fakeCommander{body: sampleHealthDetail}
+	ca := NewFromConn(fc)
+
+	h, err := ca.HealthDetail()
+	require.NoError(t, err)
+	assert.Equal(t, "health", fc.lastCmd()["prefix"])
+	assert.Equal(t, "detail", fc.lastCmd()["detail"])
+
+	assert.Equal(t, HealthWarn, h.Status)
+	require.Contains(t, h.Checks, "POOL_NO_REDUNDANCY")
+	c := h.Checks["POOL_NO_REDUNDANCY"]
+	assert.Equal(t, HealthWarn, c.Severity)
+	assert.Equal(t, 1, c.Summary.Count)
+	require.Len(t, c.Detail, 1)
+	assert.Equal(t, "pool 'rbd' has no replicas configured", c.Detail[0].Message)
+}
+
+var sampleDf = `{
+  "stats": {
+    "total_bytes": 10737418240, "total_avail_bytes": 9647853568,
+    "total_used_bytes": 15822848, "total_used_raw_bytes": 1089564672,
+    "total_used_raw_ratio": 0.10147361457347870, "num_osds": 1,
+    "num_per_pool_osds": 1, "num_per_pool_omap_osds": 1
+  },
+  "stats_by_class": {
+    "hdd": {
+      "total_bytes": 10737418240, "total_avail_bytes": 9647853568,
+      "total_used_bytes": 15822848, "total_used_raw_bytes": 1089564672,
+      "total_used_raw_ratio": 0.10147361457347870
+    }
+  },
+  "pools": [
+    {"name": "device_health_metrics", "id": 1,
+     "stats": {"stored": 0, "objects": 0, "kb_used": 0, "bytes_used": 0,
+               "percent_used": 0, "max_avail": 9149956096}},
+    {"name": "rbd", "id": 2,
+     "stats": {"stored": 4194304, "objects": 3, "kb_used": 4096,
+               "bytes_used": 4194304, "percent_used": 0.00045821,
+               "max_avail": 9149956096}}
+  ]
+}`
+
+func TestDf(t *testing.T) {
+	fc := &fakeCommander{body: sampleDf}
+	ca := NewFromConn(fc)
+
+	df, err := ca.Df()
+	require.NoError(t, err)
+	assert.Equal(t, "df", fc.lastCmd()["prefix"])
+
+	assert.Equal(t, uint64(10737418240), df.Stats.TotalBytes)
+	assert.Equal(t, uint64(9647853568), df.Stats.TotalAvailBytes)
+	assert.Contains(t, df.StatsByClass, "hdd")
+	require.Len(t, df.Pools, 2)
+	assert.Equal(t, "rbd", df.Pools[1].Name)
+	assert.Equal(t, int64(2), df.Pools[1].ID)
+	assert.Equal(t, uint64(3), df.Pools[1].Stats.Objects)
+	assert.Equal(t, uint64(4194304), df.Pools[1].Stats.Stored)
+}
+
+func TestPGStat(t *testing.T) {
+	t.Run("nautilus", func(t *testing.T) {
+		fc := &fakeCommander{body: `{
+  "num_pg_by_state": [{"name": "active+clean", "num": 33}],
+  "num_pgs": 33, "num_bytes": 4194304, "raw_bytes_used": 1089564672,
+  "raw_bytes_avail": 9647853568, "raw_bytes": 10737418240
+}`}
+		ca := NewFromConn(fc)
+
+		s, err := ca.PGStat()
+		require.NoError(t, err)
+		assert.Equal(t, "pg stat", fc.lastCmd()["prefix"])
+		assert.Equal(t, 33, s.NumPGs)
+		assert.Equal(t, []PGStateCount{{"active+clean", 33}}, s.NumPGByState)
+		assert.Equal(t, uint64(10737418240), s.RawBytes)
+	})
+
+	t.Run("octopus", func(t *testing.T) {
+		fc := &fakeCommander{body: `{
+  "pg_ready": true,
+  "pg_summary": {
+    "num_pg_by_state": [{"name": "active+clean", "num": 32},
+                        {"name": "active+undersized", "num": 1}],
+    "num_pgs": 33, "num_bytes": 4194304, "total_bytes": 10737418240,
+    "total_avail_bytes": 9647853568, "total_used_bytes": 15822848,
+    "total_used_raw_bytes": 1089564672, "raw_bytes_used": 1089564672,
+    "raw_bytes_avail": 9647853568, "raw_bytes": 10737418240
+  }
+}`}
+		ca := NewFromConn(fc)
+
+		s, err := ca.PGStat()
+		require.NoError(t, err)
+		assert.Equal(t, 33, s.NumPGs)
+		assert.Len(t, s.NumPGByState, 2)
+		assert.Equal(t, uint64(1089564672), s.RawBytesUsed)
+	})
+}
+
+var sampleStatus = `{
+  "fsid": "a6c4e9c7-7a88-4e8c-a4c5-d5a1b4a1d0c2",
+  "health": {"status": "HEALTH_OK", "checks": {}, "mutes": []},
+  "election_epoch": 3,
+  "quorum": [0],
+  "quorum_names": ["a"],
+  "quorum_age": 1234,
+  "osdmap": {
+    "epoch": 42, "num_osds": 3, "num_up_osds": 3,
+    "osd_up_since": 1595344314, "num_in_osds": 3,
+    "osd_in_since": 1595344314, "num_remapped_pgs": 0
+  },
+  "pgmap": {
+    "pgs_by_state": [{"state_name": "active+clean", "count": 33}],
+    "num_pgs": 33, "num_pools": 2, "num_objects": 3,
+    "data_bytes": 4194304, "bytes_used": 1089564672,
+    "bytes_avail": 9647853568, "bytes_total": 10737418240
+  }
+}`
+
+func TestStatus(t *testing.T) {
+	t.Run("octopus", func(t *testing.T) {
+		fc := &fakeCommander{body: sampleStatus}
+		ca := NewFromConn(fc)
+
+		s, err := ca.Status()
+		require.NoError(t, err)
+		assert.Equal(t, "status", fc.lastCmd()["prefix"])
+		assert.Equal(t, "a6c4e9c7-7a88-4e8c-a4c5-d5a1b4a1d0c2", s.FSID)
+		assert.Equal(t, HealthOK, s.Health.Status)
+		assert.Equal(t, []string{"a"}, s.QuorumNames)
+		assert.Equal(t, uint64(42), s.OsdMap.Epoch)
+		assert.Equal(t, 3, s.OsdMap.NumUpOsds)
+		assert.Equal(t, 33, s.PGMap.NumPGs)
+		assert.Equal(t, "active+clean", s.PGMap.PGsByState[0].StateName)
+	})
+
+	t.Run("nautilus", func(t *testing.T) {
+		fc := &fakeCommander{body: `{
+  "fsid": "a6c4e9c7-7a88-4e8c-a4c5-d5a1b4a1d0c2",
+  "health": {"status": "HEALTH_OK", "checks": {}},
+  "osdmap": {"osdmap": {"epoch": 12, "num_osds": 1, "num_up_osds": 1,
+                        "num_in_osds": 1, "num_remapped_pgs": 0}}
+}`}
+		ca := NewFromConn(fc)
+
+		s, err := ca.Status()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(12), s.OsdMap.Epoch)
+		assert.Equal(t, 1, s.OsdMap.NumOsds)
+	})
+}
@@ -0,0 +1,110 @@
+// +build !luminous
+
+package main
+
+// The "cephfs-fuse" tool mounts a CephFS file system on a local directory
+// using the go-ceph cephfs and cephfs/fuse packages. It serves the mount
+// point until it is unmounted, or the tool is interrupted.
+//
+// Examples:
+//   # mount the file system using the default ceph configuration
+//   ./cephfs-fuse /mnt/cephfs
+//
+//   # mount the /projects directory as client.fuse with a custom config
+//   CEPH_CONF=/etc/ceph/other.conf ./cephfs-fuse --id fuse --root /projects /mnt/projects
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/ceph/go-ceph/cephfs"
+	"github.com/ceph/go-ceph/cephfs/fuse"
+)
+
+var (
+	clientID   string
+	root       string
+	allowOther bool
+	readOnly   bool
+	debug      bool
+)
+
+func abort(msg string) {
+	log.Fatalf("error: %v\n", msg)
+}
+
+func init() {
+	flag.StringVar(&clientID, "id", "", "ceph client id (without the client. prefix)")
+	flag.StringVar(&root, "root", "/", "directory of the file system to mount")
+	flag.BoolVar(&allowOther, "allow-other", false, "allow other users to access the mount point")
+	flag.BoolVar(&readOnly, "read-only", false, "mount the file system read-only")
+	flag.BoolVar(&debug, "debug", false, "log all the FUSE requests")
+}
+
+func connect() (*cephfs.MountInfo, error) {
+	var (
+		mount *cephfs.MountInfo
+		err   error
+	)
+	if clientID != "" {
+		mount, err = cephfs.CreateMountWithId(clientID)
+	} else {
+		mount, err = cephfs.CreateMount()
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = mount.ReadDefaultConfigFile()
+	if err == nil {
+		err = mount.Mount()
+	}
+	if err != nil {
+		mount.Release()
+		return nil, err
+	}
+	return mount, nil
+}
+
+func main() {
+	flag.Parse()
+	args := flag.Args()
+	if len(args) != 1 {
+		abort("missing mount point")
+	}
+	mountPoint := args[0]
+
+	mount, err := connect()
+	if err != nil {
+		abort(fmt.Sprintf("failed to mount cephfs: %v", err))
+	}
+	defer func() {
+		mount.Unmount()
+		mount.Release()
+	}()
+
+	opts := &fuse.MountOptions{
+		AllowOther:         allowOther,
+		DefaultPermissions: true,
+		ReadOnly:           readOnly,
+		Debug:              debug,
+	}
+	srv, err := fuse.Mount(fuse.NewFS(mount, root), mountPoint, opts)
+	if err != nil {
+		abort(fmt.Sprintf("failed to mount %s: %v", mountPoint, err))
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigs
+		if err := srv.Unmount(); err != nil {
+			log.Printf("failed to unmount %s: %v", mountPoint, err)
+		}
+	}()
+
+	srv.Serve()
+}
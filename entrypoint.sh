@@ -185,10 +185,15 @@ test_go_ceph() {
     pkgs=(\
         "cephfs" \
         "cephfs/admin" \
+        "cephfs/fuse" \
+        "common/admin" \
         "internal/callbacks" \
+        "internal/commands" \
         "internal/cutil" \
         "internal/errutil" \
+        "internal/filemode" \
         "internal/retry" \
+        "nfs/admin" \
         "rados" \
         "rbd" \
         )
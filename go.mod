@@ -4,6 +4,7 @@ go 1.12
 
 require (
 	github.com/gofrs/uuid v3.2.0+incompatible
+	github.com/hanwen/go-fuse/v2 v2.2.0
 	github.com/stretchr/testify v1.4.0
 	golang.org/x/sys v0.0.0-20200501145240-bc7a7d42d5c3
 )
@@ -0,0 +1,9 @@
+package commands
+
+// RadosCommander provides an interface to execute JSON-formatted commands that
+// allow the administrative packages to interact with the Ceph cluster.
+// The rados.Conn type implements this interface.
+type RadosCommander interface {
+	MgrCommand(buf [][]byte) ([]byte, string, error)
+	MonCommand(buf []byte) ([]byte, string, error)
+}
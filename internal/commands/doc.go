@@ -0,0 +1,6 @@
+/*
+Package commands provides helpers for processing the responses of the
+JSON-formatted commands sent to the ceph cluster. It is internal to go-ceph
+and shared by the administrative packages.
+*/
+package commands
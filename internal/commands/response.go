@@ -1,6 +1,4 @@
-// +build !luminous,!mimic
-
-package admin
+package commands
 
 import (
 	"encoding/json"
@@ -32,7 +30,7 @@ type cephError interface {
 // call is not available in the version of Ceph that is running in the target
 // cluster.
 type NotImplementedError struct {
-	response
+	Response
 }
 
 // Error implements the error interface.
@@ -40,21 +38,26 @@ func (e NotImplementedError) Error() string {
 	return fmt.Sprintf("API call not implemented server-side: %s", e.status)
 }
 
-// response encapsulates the data returned by ceph and supports easy processing
-// pipelines.
-type response struct {
+// Response encapsulates the data returned by ceph and supports easy
+// processing pipelines.
+type Response struct {
 	body   []byte
 	status string
 	err    error
 }
 
+// NewResponse returns a response.
+func NewResponse(b []byte, s string, e error) Response {
+	return Response{b, s, e}
+}
+
 // Ok returns true if the response contains no error.
-func (r response) Ok() bool {
+func (r Response) Ok() bool {
 	return r.err == nil
 }
 
 // Error implements the error interface.
-func (r response) Error() string {
+func (r Response) Error() string {
 	if r.status == "" {
 		return r.err.Error()
 	}
@@ -62,22 +65,27 @@ func (r response) Error() string {
 }
 
 // Unwrap returns the error this response contains.
-func (r response) Unwrap() error {
+func (r Response) Unwrap() error {
 	return r.err
 }
 
+// Body returns the response body.
+func (r Response) Body() []byte {
+	return r.body
+}
+
 // Status returns the status string value.
-func (r response) Status() string {
+func (r Response) Status() string {
 	return r.status
 }
 
 // End returns an error if the response contains an error or nil, indicating
 // that response is no longer needed for processing.
-func (r response) End() error {
+func (r Response) End() error {
 	if !r.Ok() {
 		if ce, ok := r.err.(cephError); ok {
 			if ce.ErrorCode() == einval && strings.HasPrefix(r.status, missingPrefix) {
-				return NotImplementedError{response: r}
+				return NotImplementedError{Response: r}
 			}
 		}
 		return r
@@ -85,58 +93,53 @@ func (r response) End() error {
 	return nil
 }
 
-// noStatus asserts that the input response has no status value.
-func (r response) noStatus() response {
+// NoStatus asserts that the input response has no status value.
+func (r Response) NoStatus() Response {
 	if !r.Ok() {
 		return r
 	}
 	if r.status != "" {
-		return response{r.body, r.status, ErrStatusNotEmpty}
+		return Response{r.body, r.status, ErrStatusNotEmpty}
 	}
 	return r
 }
 
-// noBody asserts that the input response has no body value.
-func (r response) noBody() response {
+// NoBody asserts that the input response has no body value.
+func (r Response) NoBody() Response {
 	if !r.Ok() {
 		return r
 	}
 	if len(r.body) != 0 {
-		return response{r.body, r.status, ErrBodyNotEmpty}
+		return Response{r.body, r.status, ErrBodyNotEmpty}
 	}
 	return r
 }
 
-// noData asserts that the input response has no status or body values.
-func (r response) noData() response {
-	return r.noStatus().noBody()
+// NoData asserts that the input response has no status or body values.
+func (r Response) NoData() Response {
+	return r.NoStatus().NoBody()
 }
 
-// filterDeprecated removes deprecation warnings from the response status.
+// FilterDeprecated removes deprecation warnings from the response status.
 // Use it when checking the response from calls that may be deprecated in ceph
 // if you want those calls to continue working if the warning is present.
-func (r response) filterDeprecated() response {
+func (r Response) FilterDeprecated() Response {
 	if !r.Ok() {
 		return r
 	}
 	if strings.HasSuffix(r.status, deprecatedSuffix) {
-		return response{r.body, "", r.err}
+		return Response{r.body, "", r.err}
 	}
 	return r
 }
 
-// unmarshal data from the response body into v.
-func (r response) unmarshal(v interface{}) response {
+// Unmarshal data from the response body into v.
+func (r Response) Unmarshal(v interface{}) Response {
 	if !r.Ok() {
 		return r
 	}
 	if err := json.Unmarshal(r.body, v); err != nil {
-		return response{body: r.body, err: err}
+		return Response{body: r.body, err: err}
 	}
 	return r
 }
-
-// newResponse returns a response.
-func newResponse(b []byte, s string, e error) response {
-	return response{b, s, e}
-}
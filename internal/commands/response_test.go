@@ -1,6 +1,4 @@
-// +build !luminous,!mimic
-
-package admin
+package commands
 
 import (
 	"errors"
@@ -12,18 +10,18 @@ import (
 func TestResponse(t *testing.T) {
 	e1 := errors.New("error one")
 	e2 := errors.New("error two")
-	r1 := response{
+	r1 := Response{
 		body: []byte(`{"foo": "bar", "baz": 1}`),
 	}
-	r2 := response{
+	r2 := Response{
 		status: "System notice: disabled for maintenance",
 		err:    e1,
 	}
-	r3 := response{
+	r3 := Response{
 		body:   []byte(`{"oof": "RAB", "baz": 8}`),
 		status: "reversed polarity detected",
 	}
-	r4 := response{
+	r4 := Response{
 		body:   []byte(`{"whoops": true, "state": "total protonic reversal"}`),
 		status: "",
 		err:    e2,
@@ -55,79 +53,84 @@ func TestResponse(t *testing.T) {
 		assert.Equal(t, "reversed polarity detected", r3.Status())
 	})
 
+	t.Run("body", func(t *testing.T) {
+		assert.Equal(t, []byte(`{"foo": "bar", "baz": 1}`), r1.Body())
+		assert.Nil(t, r2.Body())
+	})
+
 	t.Run("end", func(t *testing.T) {
 		assert.Nil(t, r1.End())
 		assert.NotNil(t, r2.End())
 		assert.EqualValues(t, r2, r2.End())
 	})
 
-	t.Run("noStatus", func(t *testing.T) {
-		assert.EqualValues(t, r1, r1.noStatus())
-		assert.EqualValues(t, r2, r2.noStatus())
+	t.Run("NoStatus", func(t *testing.T) {
+		assert.EqualValues(t, r1, r1.NoStatus())
+		assert.EqualValues(t, r2, r2.NoStatus())
 
-		x := r3.noStatus()
+		x := r3.NoStatus()
 		assert.EqualValues(t, ErrStatusNotEmpty, x.Unwrap())
 		assert.EqualValues(t, r3.Status(), x.Status())
 	})
 
-	t.Run("noBody", func(t *testing.T) {
-		x := r1.noBody()
+	t.Run("NoBody", func(t *testing.T) {
+		x := r1.NoBody()
 		assert.EqualValues(t, ErrBodyNotEmpty, x.Unwrap())
 		assert.EqualValues(t, r1.Status(), x.Status())
 
-		assert.EqualValues(t, r2, r2.noBody())
+		assert.EqualValues(t, r2, r2.NoBody())
 
-		rtemp := response{}
-		assert.EqualValues(t, rtemp, rtemp.noBody())
+		rtemp := Response{}
+		assert.EqualValues(t, rtemp, rtemp.NoBody())
 	})
 
-	t.Run("noData", func(t *testing.T) {
-		x := r1.noData()
+	t.Run("NoData", func(t *testing.T) {
+		x := r1.NoData()
 		assert.EqualValues(t, ErrBodyNotEmpty, x.Unwrap())
 		assert.EqualValues(t, r1.Status(), x.Status())
 
-		x = r3.noStatus()
+		x = r3.NoStatus()
 		assert.EqualValues(t, ErrStatusNotEmpty, x.Unwrap())
 		assert.EqualValues(t, r3.Status(), x.Status())
 
-		rtemp := response{}
-		assert.EqualValues(t, rtemp, rtemp.noData())
+		rtemp := Response{}
+		assert.EqualValues(t, rtemp, rtemp.NoData())
 	})
 
-	t.Run("filterDeprecated", func(t *testing.T) {
-		assert.EqualValues(t, r1, r1.filterDeprecated())
-		assert.EqualValues(t, r2, r2.filterDeprecated())
+	t.Run("FilterDeprecated", func(t *testing.T) {
+		assert.EqualValues(t, r1, r1.FilterDeprecated())
+		assert.EqualValues(t, r2, r2.FilterDeprecated())
 
-		rtemp := response{
+		rtemp := Response{
 			status: "blorple call is deprecated and will be removed in a future release",
 		}
-		x := rtemp.filterDeprecated()
+		x := rtemp.FilterDeprecated()
 		assert.True(t, x.Ok())
 		assert.Nil(t, x.End())
 		assert.Equal(t, "", x.Status())
 	})
 
-	t.Run("unmarshal", func(t *testing.T) {
+	t.Run("Unmarshal", func(t *testing.T) {
 		var v map[string]interface{}
-		assert.EqualValues(t, r1, r1.unmarshal(&v))
+		assert.EqualValues(t, r1, r1.Unmarshal(&v))
 		assert.EqualValues(t, "bar", v["foo"])
 
-		assert.EqualValues(t, r2, r2.unmarshal(&v))
+		assert.EqualValues(t, r2, r2.Unmarshal(&v))
 
-		rtemp := response{body: []byte("foo!")}
-		x := rtemp.unmarshal(&v)
+		rtemp := Response{body: []byte("foo!")}
+		x := rtemp.Unmarshal(&v)
 		assert.False(t, x.Ok())
 		assert.Contains(t, x.Error(), "invalid character")
 	})
 
-	t.Run("newResponse", func(t *testing.T) {
-		rtemp := newResponse(nil, "x", e2)
+	t.Run("NewResponse", func(t *testing.T) {
+		rtemp := NewResponse(nil, "x", e2)
 		assert.False(t, rtemp.Ok())
 		assert.Equal(t, "x", rtemp.Status())
 	})
 
 	t.Run("notImplemented", func(t *testing.T) {
-		rtemp := response{
+		rtemp := Response{
 			status: "No handler found for this function",
 			err:    myCephError(-22),
 		}
@@ -0,0 +1,57 @@
+/*
+Package filemode converts between the unix file modes used by the ceph file
+system APIs and the os.FileMode type of the Go standard library. It is
+internal to go-ceph and shared by the cephfs packages.
+*/
+package filemode
+
+import (
+	"os"
+	"syscall"
+)
+
+// FromUnix converts a unix file type and mode, as returned in the Mode field
+// of a stat result, to an os.FileMode.
+func FromUnix(mode uint16) os.FileMode {
+	m := os.FileMode(mode & 0777)
+	switch uint32(mode) & syscall.S_IFMT {
+	case syscall.S_IFDIR:
+		m |= os.ModeDir
+	case syscall.S_IFLNK:
+		m |= os.ModeSymlink
+	case syscall.S_IFIFO:
+		m |= os.ModeNamedPipe
+	case syscall.S_IFSOCK:
+		m |= os.ModeSocket
+	case syscall.S_IFCHR:
+		m |= os.ModeDevice | os.ModeCharDevice
+	case syscall.S_IFBLK:
+		m |= os.ModeDevice
+	}
+	if mode&syscall.S_ISUID != 0 {
+		m |= os.ModeSetuid
+	}
+	if mode&syscall.S_ISGID != 0 {
+		m |= os.ModeSetgid
+	}
+	if mode&syscall.S_ISVTX != 0 {
+		m |= os.ModeSticky
+	}
+	return m
+}
+
+// ToUnix converts the permission bits of an os.FileMode to a unix mode. The
+// file type bits are not converted.
+func ToUnix(mode os.FileMode) uint32 {
+	m := uint32(mode.Perm())
+	if mode&os.ModeSetuid != 0 {
+		m |= syscall.S_ISUID
+	}
+	if mode&os.ModeSetgid != 0 {
+		m |= syscall.S_ISGID
+	}
+	if mode&os.ModeSticky != 0 {
+		m |= syscall.S_ISVTX
+	}
+	return m
+}
@@ -0,0 +1,34 @@
+package filemode
+
+import (
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromUnix(t *testing.T) {
+	assert.Equal(t, os.FileMode(0644), FromUnix(syscall.S_IFREG|0644))
+	assert.Equal(t, os.ModeDir|0755, FromUnix(syscall.S_IFDIR|0755))
+	assert.Equal(t, os.ModeSymlink|0777, FromUnix(syscall.S_IFLNK|0777))
+	assert.Equal(t, os.ModeNamedPipe|0600, FromUnix(syscall.S_IFIFO|0600))
+	assert.Equal(t, os.ModeSocket|0600, FromUnix(syscall.S_IFSOCK|0600))
+	assert.Equal(t, os.ModeDevice|os.ModeCharDevice|0600,
+		FromUnix(syscall.S_IFCHR|0600))
+	assert.Equal(t, os.ModeDevice|0600, FromUnix(syscall.S_IFBLK|0600))
+	assert.Equal(t, os.ModeDir|os.ModeSticky|0777,
+		FromUnix(syscall.S_IFDIR|syscall.S_ISVTX|0777))
+	assert.Equal(t, os.ModeDir|os.ModeSetgid|0750,
+		FromUnix(syscall.S_IFDIR|syscall.S_ISGID|0750))
+	assert.Equal(t, os.ModeSetuid|0755, FromUnix(syscall.S_IFREG|syscall.S_ISUID|0755))
+}
+
+func TestToUnix(t *testing.T) {
+	assert.Equal(t, uint32(0640), ToUnix(0640))
+	assert.Equal(t, uint32(syscall.S_ISUID|0755), ToUnix(os.ModeSetuid|0755))
+	assert.Equal(t, uint32(syscall.S_ISGID|0750), ToUnix(os.ModeSetgid|0750))
+	assert.Equal(t, uint32(syscall.S_ISVTX|0777),
+		ToUnix(os.ModeDir|os.ModeSticky|0777))
+	assert.Equal(t, uint32(0755), ToUnix(os.ModeDir|0755))
+}
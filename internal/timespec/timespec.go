@@ -28,3 +28,11 @@ func CStructToTimespec(cts CTimespecPtr) Timespec {
 		Nsec: int64(t.tv_nsec),
 	}
 }
+
+// CopyToCStruct copies the time values from a Timespec to a previously
+// allocated C `struct timespec`.
+func CopyToCStruct(t Timespec, cts CTimespecPtr) {
+	ctp := (*C.struct_timespec)(cts)
+	ctp.tv_sec = C.time_t(t.Sec)
+	ctp.tv_nsec = C.long(t.Nsec)
+}
@@ -0,0 +1,100 @@
+// +build !luminous,!mimic,!nautilus,!octopus
+
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/ceph/go-ceph/internal/commands"
+	"github.com/ceph/go-ceph/rados"
+)
+
+// RadosCommander provides an interface to execute JSON-formatted commands that
+// allow the nfs administrative functions to interact with the Ceph cluster.
+type RadosCommander = commands.RadosCommander
+
+// MgrBufferCommander provides an interface to execute JSON-formatted commands
+// that take an input buffer, such as the JSON description of an export, along
+// with the command. The rados.Conn type implements this interface. It is only
+// needed by ApplyExport.
+type MgrBufferCommander interface {
+	MgrCommandWithInputBuffer(buf [][]byte, inputBuffer []byte) ([]byte, string, error)
+}
+
+// ErrNoInputBuffer is returned by ApplyExport if the RadosCommander of the
+// NFSAdmin does not implement MgrBufferCommander.
+var ErrNoInputBuffer = errors.New("commander can not send an input buffer")
+
+// NFSAdmin is used to administrate NFS clusters and exports within a ceph
+// cluster.
+type NFSAdmin struct {
+	conn RadosCommander
+}
+
+// New creates an NFSAdmin automatically based on the default ceph
+// configuration file. If more customization is needed, create a
+// *rados.Conn as you see fit and use NewFromConn to use that
+// connection with these administrative functions.
+func New() (*NFSAdmin, error) {
+	conn, err := rados.NewConn()
+	if err != nil {
+		return nil, err
+	}
+	err = conn.ReadDefaultConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	err = conn.Connect()
+	if err != nil {
+		return nil, err
+	}
+	return NewFromConn(conn), nil
+}
+
+// NewFromConn creates an NFSAdmin management object from a preexisting
+// rados connection. The existing connection can be rados.Conn or any
+// type implementing the RadosCommander interface. This may be useful
+// if the calling layer needs to inject additional logging, error handling,
+// fault injection, etc.
+func NewFromConn(conn RadosCommander) *NFSAdmin {
+	return &NFSAdmin{conn}
+}
+
+func (nfsa *NFSAdmin) validate() error {
+	if nfsa.conn == nil {
+		return rados.ErrNotConnected
+	}
+	return nil
+}
+
+// marshalMgrCommand takes an generic interface{} value, converts it to JSON and
+// sends the json to the MGR as a command.
+func (nfsa *NFSAdmin) marshalMgrCommand(v interface{}) commands.Response {
+	if err := nfsa.validate(); err != nil {
+		return commands.NewResponse(nil, "", err)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return commands.NewResponse(nil, "", err)
+	}
+	return commands.NewResponse(nfsa.conn.MgrCommand([][]byte{b}))
+}
+
+// marshalMgrCommandWithInput takes an generic interface{} value, converts it
+// to JSON and sends the json to the MGR as a command, along with the input
+// buffer.
+func (nfsa *NFSAdmin) marshalMgrCommandWithInput(v interface{}, input []byte) commands.Response {
+	if err := nfsa.validate(); err != nil {
+		return commands.NewResponse(nil, "", err)
+	}
+	bc, ok := nfsa.conn.(MgrBufferCommander)
+	if !ok {
+		return commands.NewResponse(nil, "", ErrNoInputBuffer)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return commands.NewResponse(nil, "", err)
+	}
+	return commands.NewResponse(bc.MgrCommandWithInputBuffer([][]byte{b}, input))
+}
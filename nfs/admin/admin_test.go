@@ -0,0 +1,96 @@
+// +build !luminous,!mimic,!nautilus,!octopus
+
+package admin
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ceph/go-ceph/rados"
+)
+
+var (
+	_ RadosCommander     = &rados.Conn{}
+	_ MgrBufferCommander = &rados.Conn{}
+)
+
+var cachedNFSAdmin *NFSAdmin
+
+// recordingCommander records the last command, and input buffer, it is asked
+// to execute and replies with the preset response.
+type recordingCommander struct {
+	cmd    string
+	input  []byte
+	body   []byte
+	status string
+	err    error
+}
+
+func (r *recordingCommander) MgrCommand(buf [][]byte) ([]byte, string, error) {
+	r.cmd = string(buf[0])
+	r.input = nil
+	return r.body, r.status, r.err
+}
+
+func (r *recordingCommander) MgrCommandWithInputBuffer(
+	buf [][]byte, inputBuffer []byte) ([]byte, string, error) {
+
+	r.cmd = string(buf[0])
+	r.input = inputBuffer
+	return r.body, r.status, r.err
+}
+
+func (r *recordingCommander) MonCommand(buf []byte) ([]byte, string, error) {
+	r.cmd = string(buf)
+	r.input = nil
+	return r.body, r.status, r.err
+}
+
+func getNFSAdmin(t *testing.T) *NFSAdmin {
+	if cachedNFSAdmin != nil {
+		return cachedNFSAdmin
+	}
+	nfsa, err := New()
+	require.NoError(t, err)
+	require.NotNil(t, nfsa)
+	cachedNFSAdmin = nfsa
+	return cachedNFSAdmin
+}
+
+// getNFSCluster returns the ID of an NFS cluster to test the exports with.
+// The NFS daemons are deployed by the orchestrator, which the test
+// environment may lack, so the tests do not create the cluster themselves.
+func getNFSCluster(t *testing.T, nfsa *NFSAdmin) string {
+	clusters, err := nfsa.ListClusters()
+	if err != nil {
+		t.Skipf("mgr nfs module is not available: %v", err)
+	}
+	if len(clusters) == 0 {
+		t.Skipf("no nfs cluster is deployed")
+	}
+	return clusters[0]
+}
+
+func TestInvalidNFSAdmin(t *testing.T) {
+	nfsa := &NFSAdmin{}
+	res := nfsa.marshalMgrCommand(map[string]string{"prefix": "nfs cluster ls"})
+	assert.Equal(t, rados.ErrNotConnected, res.Unwrap())
+}
+
+func TestApplyExportNeedsInputBuffer(t *testing.T) {
+	rc := &recordingCommander{}
+	// hide the MgrCommandWithInputBuffer method of the commander
+	nfsa := NewFromConn(struct{ RadosCommander }{rc})
+	err := nfsa.ApplyExport("foo", ExportInfo{PseudoPath: "/cephfs"})
+	assert.True(t, errors.Is(err, ErrNoInputBuffer))
+	assert.Equal(t, "", rc.cmd)
+
+	// the other calls only need a RadosCommander
+	rc.body = []byte(`["foo"]`)
+	ids, err := nfsa.ListClusters()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"foo"}, ids)
+}
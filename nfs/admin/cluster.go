@@ -0,0 +1,145 @@
+// +build !luminous,!mimic,!nautilus,!octopus
+
+package admin
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+
+	"github.com/ceph/go-ceph/internal/commands"
+)
+
+// ErrClusterNotFound may be returned by ClusterInfo if ceph does not report
+// on the requested NFS cluster.
+var ErrClusterNotFound = errors.New("nfs cluster not found")
+
+// ClusterOptions are used to specify optional, non-identifying, values to be
+// used when creating a new NFS cluster.
+type ClusterOptions struct {
+	// Placement is an orchestrator placement specification for the NFS
+	// daemons, such as "3" or "host1,host2".
+	Placement string
+	// Ingress deploys a load balancer in front of the NFS daemons, reachable
+	// at VirtualIP.
+	Ingress   bool
+	VirtualIP string
+	Port      int
+}
+
+type clusterCreateFields struct {
+	Prefix    string `json:"prefix"`
+	Format    string `json:"format"`
+	ClusterID string `json:"cluster_id"`
+	Placement string `json:"placement,omitempty"`
+	Ingress   bool   `json:"ingress,omitempty"`
+	VirtualIP string `json:"virtual_ip,omitempty"`
+	Port      int    `json:"port,omitempty"`
+}
+
+// CreateCluster creates a new NFS cluster, the NFS daemons are deployed by
+// the orchestrator.
+//
+// Similar To:
+//  ceph nfs cluster create <cluster_id> [<placement>] [--ingress --virtual_ip <ip>] [--port <port>]
+func (nfsa *NFSAdmin) CreateCluster(clusterID string, o *ClusterOptions) error {
+	f := &clusterCreateFields{
+		Prefix:    "nfs cluster create",
+		Format:    "json",
+		ClusterID: clusterID,
+	}
+	if o != nil {
+		f.Placement = o.Placement
+		f.Ingress = o.Ingress
+		f.VirtualIP = o.VirtualIP
+		f.Port = o.Port
+	}
+	// depending on the version, ceph reports success in the body or the
+	// status, neither is of interest
+	return nfsa.marshalMgrCommand(f).End()
+}
+
+// RemoveCluster removes an NFS cluster and all of its exports.
+//
+// Similar To:
+//  ceph nfs cluster rm <cluster_id>
+func (nfsa *NFSAdmin) RemoveCluster(clusterID string) error {
+	m := map[string]string{
+		"prefix":     "nfs cluster rm",
+		"cluster_id": clusterID,
+		"format":     "json",
+	}
+	return nfsa.marshalMgrCommand(m).End()
+}
+
+func parseClusterList(res commands.Response) ([]string, error) {
+	if err := res.NoStatus().End(); err != nil {
+		return nil, err
+	}
+	b := bytes.TrimSpace(res.Body())
+	if len(b) == 0 {
+		return []string{}, nil
+	}
+	if b[0] == '[' {
+		var ids []string
+		if err := res.Unmarshal(&ids).End(); err != nil {
+			return nil, err
+		}
+		return ids, nil
+	}
+	// older versions reply with one cluster id per line, even if json was
+	// requested
+	return strings.Split(string(b), "\n"), nil
+}
+
+// ListClusters returns the IDs of the NFS clusters.
+//
+// Similar To:
+//  ceph nfs cluster ls
+func (nfsa *NFSAdmin) ListClusters() ([]string, error) {
+	m := map[string]string{
+		"prefix": "nfs cluster ls",
+		"format": "json",
+	}
+	return parseClusterList(nfsa.marshalMgrCommand(m))
+}
+
+// ClusterBackend reports the address of an NFS daemon of a cluster.
+type ClusterBackend struct {
+	Hostname string `json:"hostname"`
+	IP       string `json:"ip"`
+	Port     int    `json:"port"`
+}
+
+// ClusterInfo reports the addresses at which an NFS cluster is reachable.
+type ClusterInfo struct {
+	VirtualIP   string           `json:"virtual_ip"`
+	Port        int              `json:"port"`
+	MonitorPort int              `json:"monitor_port"`
+	Backend     []ClusterBackend `json:"backend"`
+}
+
+func parseClusterInfo(res commands.Response, clusterID string) (*ClusterInfo, error) {
+	var infos map[string]ClusterInfo
+	if err := res.NoStatus().Unmarshal(&infos).End(); err != nil {
+		return nil, err
+	}
+	info, ok := infos[clusterID]
+	if !ok {
+		return nil, ErrClusterNotFound
+	}
+	return &info, nil
+}
+
+// ClusterInfo returns the addresses at which the NFS cluster is reachable.
+//
+// Similar To:
+//  ceph nfs cluster info <cluster_id>
+func (nfsa *NFSAdmin) ClusterInfo(clusterID string) (*ClusterInfo, error) {
+	m := map[string]string{
+		"prefix":     "nfs cluster info",
+		"cluster_id": clusterID,
+		"format":     "json",
+	}
+	return parseClusterInfo(nfsa.marshalMgrCommand(m), clusterID)
+}
@@ -0,0 +1,156 @@
+// +build !luminous,!mimic,!nautilus,!octopus
+
+package admin
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ceph/go-ceph/internal/commands"
+)
+
+func TestParseClusterList(t *testing.T) {
+	R := commands.NewResponse
+	t.Run("error", func(t *testing.T) {
+		_, err := parseClusterList(R(nil, "", errors.New("boom")))
+		assert.Error(t, err)
+		assert.Equal(t, "boom", err.Error())
+	})
+	t.Run("statusSet", func(t *testing.T) {
+		_, err := parseClusterList(R([]byte(`[]`), "unexpected!", nil))
+		assert.Error(t, err)
+	})
+	t.Run("json", func(t *testing.T) {
+		ids, err := parseClusterList(R([]byte(`["foo", "bar"]`), "", nil))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"foo", "bar"}, ids)
+	})
+	t.Run("lines", func(t *testing.T) {
+		ids, err := parseClusterList(R([]byte("foo\nbar\n"), "", nil))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"foo", "bar"}, ids)
+	})
+	t.Run("empty", func(t *testing.T) {
+		ids, err := parseClusterList(R(nil, "", nil))
+		assert.NoError(t, err)
+		assert.Len(t, ids, 0)
+	})
+	t.Run("badJSON", func(t *testing.T) {
+		_, err := parseClusterList(R([]byte(`["foo",`), "", nil))
+		assert.Error(t, err)
+	})
+}
+
+var sampleClusterInfo = []byte(`
+{
+  "foo": {
+    "virtual_ip": "10.0.0.10",
+    "port": 2049,
+    "monitor_port": 9049,
+    "backend": [
+      {
+        "hostname": "node1",
+        "ip": "10.0.0.1",
+        "port": 12049
+      },
+      {
+        "hostname": "node2",
+        "ip": "10.0.0.2",
+        "port": 12049
+      }
+    ]
+  }
+}
+`)
+
+func TestParseClusterInfo(t *testing.T) {
+	R := commands.NewResponse
+	t.Run("error", func(t *testing.T) {
+		_, err := parseClusterInfo(R(nil, "", errors.New("boom")), "foo")
+		assert.Error(t, err)
+	})
+	t.Run("ok", func(t *testing.T) {
+		info, err := parseClusterInfo(R(sampleClusterInfo, "", nil), "foo")
+		assert.NoError(t, err)
+		require.NotNil(t, info)
+		assert.Equal(t, "10.0.0.10", info.VirtualIP)
+		assert.Equal(t, 2049, info.Port)
+		assert.Equal(t, 9049, info.MonitorPort)
+		if assert.Len(t, info.Backend, 2) {
+			assert.Equal(t, "node2", info.Backend[1].Hostname)
+			assert.Equal(t, "10.0.0.2", info.Backend[1].IP)
+			assert.Equal(t, 12049, info.Backend[1].Port)
+		}
+	})
+	t.Run("noVirtualIP", func(t *testing.T) {
+		info, err := parseClusterInfo(
+			R([]byte(`{"foo": {"virtual_ip": null, "backend": []}}`), "", nil), "foo")
+		assert.NoError(t, err)
+		require.NotNil(t, info)
+		assert.Equal(t, "", info.VirtualIP)
+	})
+	t.Run("notFound", func(t *testing.T) {
+		_, err := parseClusterInfo(R([]byte(`{}`), "", nil), "foo")
+		assert.Equal(t, ErrClusterNotFound, err)
+	})
+}
+
+func TestClusterCommands(t *testing.T) {
+	rc := &recordingCommander{}
+	nfsa := NewFromConn(rc)
+
+	err := nfsa.CreateCluster("foo", nil)
+	assert.NoError(t, err)
+	assert.JSONEq(t,
+		`{"prefix": "nfs cluster create", "cluster_id": "foo", "format": "json"}`,
+		rc.cmd)
+
+	err = nfsa.CreateCluster("foo", &ClusterOptions{
+		Placement: "2 node1,node2",
+		Ingress:   true,
+		VirtualIP: "10.0.0.10/24",
+		Port:      2049,
+	})
+	assert.NoError(t, err)
+	assert.JSONEq(t,
+		`{"prefix": "nfs cluster create", "cluster_id": "foo",
+		"placement": "2 node1,node2", "ingress": true,
+		"virtual_ip": "10.0.0.10/24", "port": 2049, "format": "json"}`,
+		rc.cmd)
+
+	err = nfsa.RemoveCluster("foo")
+	assert.NoError(t, err)
+	assert.JSONEq(t,
+		`{"prefix": "nfs cluster rm", "cluster_id": "foo", "format": "json"}`,
+		rc.cmd)
+
+	rc.err = errors.New("EINVAL")
+	assert.Error(t, nfsa.CreateCluster("foo", nil))
+	assert.Error(t, nfsa.RemoveCluster("foo"))
+}
+
+func TestListClusters(t *testing.T) {
+	nfsa := getNFSAdmin(t)
+	clusterID := getNFSCluster(t, nfsa)
+
+	ids, err := nfsa.ListClusters()
+	assert.NoError(t, err)
+	assert.Contains(t, ids, clusterID)
+}
+
+func TestClusterInfo(t *testing.T) {
+	nfsa := getNFSAdmin(t)
+	clusterID := getNFSCluster(t, nfsa)
+
+	info, err := nfsa.ClusterInfo(clusterID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, info) {
+		assert.NotEmpty(t, info.Backend)
+	}
+
+	_, err = nfsa.ClusterInfo("oops")
+	assert.Error(t, err)
+}
@@ -0,0 +1,15 @@
+/*
+Package admin is a convenience layer to support the administration of NFS
+clusters and exports through the ceph mgr nfs module. Exports may publish a
+CephFS file system, such as a CephFS subvolume, or an RGW bucket.
+
+The mgr nfs module is only available in ceph pacific and later versions. The
+arguments of the commands follow the module of pacific 16.2.7. Earlier pacific
+point releases name the arguments of the export commands differently, for
+example "clusterid" and "binding", and are not supported.
+
+Unlike the rados package this API does not map to APIs provided by
+ceph libraries themselves. This API is not yet stable and is subject
+to change.
+*/
+package admin
@@ -0,0 +1,293 @@
+// +build !luminous,!mimic,!nautilus,!octopus
+
+package admin
+
+import (
+	"encoding/json"
+
+	"github.com/ceph/go-ceph/internal/commands"
+)
+
+// AccessType is the level of access granted by an export.
+type AccessType string
+
+const (
+	// ReadWriteAccess grants read-write access.
+	ReadWriteAccess = AccessType("RW")
+	// ReadOnlyAccess grants read-only access.
+	ReadOnlyAccess = AccessType("RO")
+	// NoAccess denies access.
+	NoAccess = AccessType("NONE")
+)
+
+// SquashMode controls how the user IDs of NFS clients are mapped.
+type SquashMode string
+
+const (
+	// NoneSquash keeps the user IDs of all clients.
+	NoneSquash = SquashMode("none")
+	// RootSquash maps the root user of clients to the anonymous user.
+	RootSquash = SquashMode("root")
+	// AllSquash maps all the users of clients to the anonymous user.
+	AllSquash = SquashMode("all")
+	// RootIDSquash maps the root user of clients to the anonymous user, but
+	// keeps the root group.
+	RootIDSquash = SquashMode("rootid")
+)
+
+// FSAL names the file system abstraction layer used by the NFS daemons to
+// serve an export.
+type FSAL string
+
+const (
+	// CephFSFSAL serves a path of a CephFS file system.
+	CephFSFSAL = FSAL("CEPH")
+	// RGWFSAL serves an RGW bucket, or all the buckets of a user.
+	RGWFSAL = FSAL("RGW")
+)
+
+// FSALInfo describes the backend of an export. The fields used depend on
+// the FSAL.
+type FSALInfo struct {
+	Name            FSAL   `json:"name"`
+	UserID          string `json:"user_id,omitempty"`
+	FileSystemName  string `json:"fs_name,omitempty"`
+	AccessKeyID     string `json:"access_key_id,omitempty"`
+	SecretAccessKey string `json:"secret_access_key,omitempty"`
+}
+
+// ClientInfo overrides the access settings of an export for a set of
+// clients.
+type ClientInfo struct {
+	Addresses  []string   `json:"addresses"`
+	AccessType AccessType `json:"access_type"`
+	Squash     SquashMode `json:"squash"`
+}
+
+// ExportInfo describes an export. It is reported by ExportInfo and may be
+// passed to ApplyExport to create or update an export.
+type ExportInfo struct {
+	ExportID      int64        `json:"export_id,omitempty"`
+	Path          string       `json:"path"`
+	ClusterID     string       `json:"cluster_id"`
+	PseudoPath    string       `json:"pseudo"`
+	AccessType    AccessType   `json:"access_type"`
+	Squash        SquashMode   `json:"squash"`
+	SecurityLabel bool         `json:"security_label"`
+	Protocols     []int        `json:"protocols,omitempty"`
+	Transports    []string     `json:"transports,omitempty"`
+	FSAL          FSALInfo     `json:"fsal"`
+	Clients       []ClientInfo `json:"clients,omitempty"`
+}
+
+// ExportResult reports on an export created by CreateCephFSExport or
+// CreateRGWExport.
+type ExportResult struct {
+	Bind           string `json:"bind"`
+	FileSystemName string `json:"fs"`
+	Path           string `json:"path"`
+	Cluster        string `json:"cluster"`
+	Mode           string `json:"mode"`
+}
+
+// CephFSExportSpec specifies an export of a CephFS file system. Path is the
+// path to export within the file system, such as the path of a subvolume;
+// the root of the file system is exported if it is empty.
+type CephFSExportSpec struct {
+	FileSystemName string
+	ClusterID      string
+	PseudoPath     string
+	Path           string
+	ReadOnly       bool
+	ClientAddr     []string
+	Squash         SquashMode
+}
+
+type cephFSExportFields struct {
+	Prefix         string     `json:"prefix"`
+	Format         string     `json:"format"`
+	FileSystemName string     `json:"fsname"`
+	ClusterID      string     `json:"cluster_id"`
+	PseudoPath     string     `json:"pseudo_path"`
+	Path           string     `json:"path,omitempty"`
+	ReadOnly       bool       `json:"readonly,omitempty"`
+	ClientAddr     []string   `json:"client_addr,omitempty"`
+	Squash         SquashMode `json:"squash,omitempty"`
+}
+
+// RGWExportSpec specifies an export of an RGW bucket. If no bucket is given
+// all the buckets of the user are exported, pacific servers do not support
+// this and require a bucket.
+type RGWExportSpec struct {
+	ClusterID  string
+	PseudoPath string
+	Bucket     string
+	UserID     string
+	ReadOnly   bool
+	ClientAddr []string
+	Squash     SquashMode
+}
+
+type rgwExportFields struct {
+	Prefix     string     `json:"prefix"`
+	Format     string     `json:"format"`
+	ClusterID  string     `json:"cluster_id"`
+	PseudoPath string     `json:"pseudo_path"`
+	Bucket     string     `json:"bucket,omitempty"`
+	UserID     string     `json:"user_id,omitempty"`
+	ReadOnly   bool       `json:"readonly,omitempty"`
+	ClientAddr []string   `json:"client_addr,omitempty"`
+	Squash     SquashMode `json:"squash,omitempty"`
+}
+
+func parseExportResult(res commands.Response) (*ExportResult, error) {
+	var r ExportResult
+	if err := res.NoStatus().Unmarshal(&r).End(); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// CreateCephFSExport creates an export of a CephFS file system.
+//
+// Similar To:
+//  ceph nfs export create cephfs --fsname <fsname> --cluster-id <cluster_id> --pseudo-path <pseudo_path> [...]
+func (nfsa *NFSAdmin) CreateCephFSExport(spec CephFSExportSpec) (*ExportResult, error) {
+	f := &cephFSExportFields{
+		Prefix:         "nfs export create cephfs",
+		Format:         "json",
+		FileSystemName: spec.FileSystemName,
+		ClusterID:      spec.ClusterID,
+		PseudoPath:     spec.PseudoPath,
+		Path:           spec.Path,
+		ReadOnly:       spec.ReadOnly,
+		ClientAddr:     spec.ClientAddr,
+		Squash:         spec.Squash,
+	}
+	return parseExportResult(nfsa.marshalMgrCommand(f))
+}
+
+// CreateRGWExport creates an export of an RGW bucket.
+//
+// Similar To:
+//  ceph nfs export create rgw --cluster-id <cluster_id> --pseudo-path <pseudo_path> [--bucket <bucket>] [...]
+func (nfsa *NFSAdmin) CreateRGWExport(spec RGWExportSpec) (*ExportResult, error) {
+	f := &rgwExportFields{
+		Prefix:     "nfs export create rgw",
+		Format:     "json",
+		ClusterID:  spec.ClusterID,
+		PseudoPath: spec.PseudoPath,
+		Bucket:     spec.Bucket,
+		UserID:     spec.UserID,
+		ReadOnly:   spec.ReadOnly,
+		ClientAddr: spec.ClientAddr,
+		Squash:     spec.Squash,
+	}
+	return parseExportResult(nfsa.marshalMgrCommand(f))
+}
+
+// RemoveExport removes the export of the NFS cluster with the given pseudo
+// path.
+//
+// Similar To:
+//  ceph nfs export rm <cluster_id> <pseudo_path>
+func (nfsa *NFSAdmin) RemoveExport(clusterID, pseudoPath string) error {
+	m := map[string]string{
+		"prefix":      "nfs export rm",
+		"cluster_id":  clusterID,
+		"pseudo_path": pseudoPath,
+		"format":      "json",
+	}
+	// depending on the version, ceph reports success in the body or the
+	// status, neither is of interest
+	return nfsa.marshalMgrCommand(m).End()
+}
+
+func parseExportList(res commands.Response) ([]string, error) {
+	var l []string
+	if err := res.NoStatus().Unmarshal(&l).End(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// ListExports returns the pseudo paths of the exports of the NFS cluster.
+//
+// Similar To:
+//  ceph nfs export ls <cluster_id>
+func (nfsa *NFSAdmin) ListExports(clusterID string) ([]string, error) {
+	m := map[string]string{
+		"prefix":     "nfs export ls",
+		"cluster_id": clusterID,
+		"format":     "json",
+	}
+	return parseExportList(nfsa.marshalMgrCommand(m))
+}
+
+func parseDetailedExportList(res commands.Response) ([]ExportInfo, error) {
+	var l []ExportInfo
+	if err := res.NoStatus().Unmarshal(&l).End(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// ListDetailedExports returns the description of all the exports of the NFS
+// cluster.
+//
+// Similar To:
+//  ceph nfs export ls <cluster_id> --detailed
+func (nfsa *NFSAdmin) ListDetailedExports(clusterID string) ([]ExportInfo, error) {
+	m := map[string]interface{}{
+		"prefix":     "nfs export ls",
+		"cluster_id": clusterID,
+		"detailed":   true,
+		"format":     "json",
+	}
+	return parseDetailedExportList(nfsa.marshalMgrCommand(m))
+}
+
+func parseExportInfo(res commands.Response) (*ExportInfo, error) {
+	var i ExportInfo
+	if err := res.NoStatus().Unmarshal(&i).End(); err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+// ExportInfo returns the description of the export of the NFS cluster with
+// the given pseudo path.
+//
+// Similar To:
+//  ceph nfs export info <cluster_id> <pseudo_path>
+func (nfsa *NFSAdmin) ExportInfo(clusterID, pseudoPath string) (*ExportInfo, error) {
+	m := map[string]string{
+		"prefix":      "nfs export info",
+		"cluster_id":  clusterID,
+		"pseudo_path": pseudoPath,
+		"format":      "json",
+	}
+	return parseExportInfo(nfsa.marshalMgrCommand(m))
+}
+
+// ApplyExport creates the described export, or updates it if an export with
+// the same pseudo path already exists in the NFS cluster. The export is sent
+// as an input buffer, so the connection of the NFSAdmin must implement
+// MgrBufferCommander.
+//
+// Similar To:
+//  ceph nfs export apply <cluster_id> -i <export.json>
+func (nfsa *NFSAdmin) ApplyExport(clusterID string, export ExportInfo) error {
+	b, err := json.Marshal(export)
+	if err != nil {
+		return err
+	}
+	m := map[string]string{
+		"prefix":     "nfs export apply",
+		"cluster_id": clusterID,
+		"format":     "json",
+	}
+	// ceph describes the outcome in the body or the status, depending on
+	// the version
+	return nfsa.marshalMgrCommandWithInput(m, b).End()
+}
@@ -0,0 +1,307 @@
+// +build !luminous,!mimic,!nautilus,!octopus
+
+package admin
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ceph/go-ceph/internal/commands"
+)
+
+var sampleExportResult = []byte(`
+{
+  "bind": "/cephfs/sv1",
+  "fs": "cephfs",
+  "path": "/volumes/_nogroup/sv1/e5a5c3c3-1f9d-4a5e-9b6a-b6b4b2c0d7a1",
+  "cluster": "foo",
+  "mode": "RW"
+}
+`)
+
+func TestParseExportResult(t *testing.T) {
+	R := commands.NewResponse
+	t.Run("error", func(t *testing.T) {
+		_, err := parseExportResult(R(nil, "", errors.New("boom")))
+		assert.Error(t, err)
+		assert.Equal(t, "boom", err.Error())
+	})
+	t.Run("statusSet", func(t *testing.T) {
+		_, err := parseExportResult(R(sampleExportResult, "unexpected!", nil))
+		assert.Error(t, err)
+	})
+	t.Run("badJSON", func(t *testing.T) {
+		_, err := parseExportResult(R([]byte("oops"), "", nil))
+		assert.Error(t, err)
+	})
+	t.Run("ok", func(t *testing.T) {
+		r, err := parseExportResult(R(sampleExportResult, "", nil))
+		assert.NoError(t, err)
+		if assert.NotNil(t, r) {
+			assert.Equal(t, "/cephfs/sv1", r.Bind)
+			assert.Equal(t, "cephfs", r.FileSystemName)
+			assert.Equal(t, "foo", r.Cluster)
+			assert.Equal(t, "RW", r.Mode)
+		}
+	})
+}
+
+func TestParseExportList(t *testing.T) {
+	R := commands.NewResponse
+	t.Run("error", func(t *testing.T) {
+		_, err := parseExportList(R(nil, "", errors.New("boom")))
+		assert.Error(t, err)
+	})
+	t.Run("ok", func(t *testing.T) {
+		l, err := parseExportList(R([]byte(`["/cephfs/sv1", "/bucket1"]`), "", nil))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"/cephfs/sv1", "/bucket1"}, l)
+	})
+}
+
+var sampleExportInfo = []byte(`
+{
+  "export_id": 1,
+  "path": "/volumes/_nogroup/sv1/e5a5c3c3-1f9d-4a5e-9b6a-b6b4b2c0d7a1",
+  "cluster_id": "foo",
+  "pseudo": "/cephfs/sv1",
+  "access_type": "RW",
+  "squash": "none",
+  "security_label": true,
+  "protocols": [
+    4
+  ],
+  "transports": [
+    "TCP"
+  ],
+  "fsal": {
+    "name": "CEPH",
+    "user_id": "nfs.foo.1",
+    "fs_name": "cephfs"
+  },
+  "clients": [
+    {
+      "addresses": [
+        "10.0.0.0/8"
+      ],
+      "access_type": "RO",
+      "squash": "root"
+    }
+  ]
+}
+`)
+
+func checkSampleExportInfo(t *testing.T, info *ExportInfo) {
+	require.NotNil(t, info)
+	assert.EqualValues(t, 1, info.ExportID)
+	assert.Equal(t, "/cephfs/sv1", info.PseudoPath)
+	assert.Equal(t, "foo", info.ClusterID)
+	assert.Equal(t, ReadWriteAccess, info.AccessType)
+	assert.Equal(t, NoneSquash, info.Squash)
+	assert.True(t, info.SecurityLabel)
+	assert.Equal(t, []int{4}, info.Protocols)
+	assert.Equal(t, CephFSFSAL, info.FSAL.Name)
+	assert.Equal(t, "nfs.foo.1", info.FSAL.UserID)
+	assert.Equal(t, "cephfs", info.FSAL.FileSystemName)
+	if assert.Len(t, info.Clients, 1) {
+		assert.Equal(t, []string{"10.0.0.0/8"}, info.Clients[0].Addresses)
+		assert.Equal(t, ReadOnlyAccess, info.Clients[0].AccessType)
+		assert.Equal(t, RootSquash, info.Clients[0].Squash)
+	}
+}
+
+func TestParseExportInfo(t *testing.T) {
+	R := commands.NewResponse
+	t.Run("error", func(t *testing.T) {
+		_, err := parseExportInfo(R(nil, "", errors.New("boom")))
+		assert.Error(t, err)
+	})
+	t.Run("statusSet", func(t *testing.T) {
+		_, err := parseExportInfo(R(sampleExportInfo, "unexpected!", nil))
+		assert.Error(t, err)
+	})
+	t.Run("ok", func(t *testing.T) {
+		info, err := parseExportInfo(R(sampleExportInfo, "", nil))
+		assert.NoError(t, err)
+		checkSampleExportInfo(t, info)
+	})
+}
+
+func TestParseDetailedExportList(t *testing.T) {
+	R := commands.NewResponse
+	t.Run("error", func(t *testing.T) {
+		_, err := parseDetailedExportList(R(nil, "", errors.New("boom")))
+		assert.Error(t, err)
+	})
+	t.Run("ok", func(t *testing.T) {
+		body := append(append([]byte("["), sampleExportInfo...), ']')
+		l, err := parseDetailedExportList(R(body, "", nil))
+		assert.NoError(t, err)
+		if assert.Len(t, l, 1) {
+			checkSampleExportInfo(t, &l[0])
+		}
+	})
+}
+
+func TestExportCommands(t *testing.T) {
+	rc := &recordingCommander{body: sampleExportResult}
+	nfsa := NewFromConn(rc)
+
+	_, err := nfsa.CreateCephFSExport(CephFSExportSpec{
+		FileSystemName: "cephfs",
+		ClusterID:      "foo",
+		PseudoPath:     "/cephfs/sv1",
+	})
+	assert.NoError(t, err)
+	assert.JSONEq(t,
+		`{"prefix": "nfs export create cephfs", "fsname": "cephfs",
+		"cluster_id": "foo", "pseudo_path": "/cephfs/sv1", "format": "json"}`,
+		rc.cmd)
+
+	_, err = nfsa.CreateCephFSExport(CephFSExportSpec{
+		FileSystemName: "cephfs",
+		ClusterID:      "foo",
+		PseudoPath:     "/cephfs/sv1",
+		Path:           "/volumes/_nogroup/sv1",
+		ReadOnly:       true,
+		ClientAddr:     []string{"10.0.0.0/8", "192.168.1.1"},
+		Squash:         RootSquash,
+	})
+	assert.NoError(t, err)
+	assert.JSONEq(t,
+		`{"prefix": "nfs export create cephfs", "fsname": "cephfs",
+		"cluster_id": "foo", "pseudo_path": "/cephfs/sv1",
+		"path": "/volumes/_nogroup/sv1", "readonly": true,
+		"client_addr": ["10.0.0.0/8", "192.168.1.1"], "squash": "root",
+		"format": "json"}`,
+		rc.cmd)
+
+	_, err = nfsa.CreateRGWExport(RGWExportSpec{
+		ClusterID:  "foo",
+		PseudoPath: "/bucket1",
+		Bucket:     "bucket1",
+		ReadOnly:   true,
+		ClientAddr: []string{"10.0.0.0/8"},
+		Squash:     AllSquash,
+	})
+	assert.NoError(t, err)
+	assert.JSONEq(t,
+		`{"prefix": "nfs export create rgw", "cluster_id": "foo",
+		"pseudo_path": "/bucket1", "bucket": "bucket1", "readonly": true,
+		"client_addr": ["10.0.0.0/8"], "squash": "all", "format": "json"}`,
+		rc.cmd)
+
+	_, err = nfsa.CreateRGWExport(RGWExportSpec{
+		ClusterID:  "foo",
+		PseudoPath: "/user1",
+		UserID:     "user1",
+	})
+	assert.NoError(t, err)
+	assert.JSONEq(t,
+		`{"prefix": "nfs export create rgw", "cluster_id": "foo",
+		"pseudo_path": "/user1", "user_id": "user1", "format": "json"}`,
+		rc.cmd)
+
+	rc.body = nil
+	err = nfsa.RemoveExport("foo", "/cephfs/sv1")
+	assert.NoError(t, err)
+	assert.JSONEq(t,
+		`{"prefix": "nfs export rm", "cluster_id": "foo",
+		"pseudo_path": "/cephfs/sv1", "format": "json"}`,
+		rc.cmd)
+
+	err = nfsa.ApplyExport("foo", ExportInfo{
+		ClusterID:  "foo",
+		PseudoPath: "/cephfs/sv1",
+		Path:       "/volumes/_nogroup/sv1",
+		AccessType: ReadOnlyAccess,
+		Squash:     NoneSquash,
+		FSAL:       FSALInfo{Name: CephFSFSAL, FileSystemName: "cephfs"},
+	})
+	assert.NoError(t, err)
+	assert.JSONEq(t,
+		`{"prefix": "nfs export apply", "cluster_id": "foo", "format": "json"}`,
+		rc.cmd)
+	assert.JSONEq(t,
+		`{"path": "/volumes/_nogroup/sv1", "cluster_id": "foo",
+		"pseudo": "/cephfs/sv1", "access_type": "RO", "squash": "none",
+		"security_label": false, "fsal": {"name": "CEPH", "fs_name": "cephfs"}}`,
+		string(rc.input))
+
+	rc.err = errors.New("EINVAL")
+	_, err = nfsa.CreateCephFSExport(CephFSExportSpec{ClusterID: "foo"})
+	assert.Error(t, err)
+	_, err = nfsa.CreateRGWExport(RGWExportSpec{ClusterID: "foo"})
+	assert.Error(t, err)
+	assert.Error(t, nfsa.RemoveExport("foo", "/cephfs/sv1"))
+	assert.Error(t, nfsa.ApplyExport("foo", ExportInfo{}))
+}
+
+func TestCephFSExports(t *testing.T) {
+	nfsa := getNFSAdmin(t)
+	clusterID := getNFSCluster(t, nfsa)
+	pseudoPath := "/goceph-export"
+
+	r, err := nfsa.CreateCephFSExport(CephFSExportSpec{
+		FileSystemName: "cephfs",
+		ClusterID:      clusterID,
+		PseudoPath:     pseudoPath,
+		Squash:         RootSquash,
+	})
+	require.NoError(t, err)
+	removed := false
+	defer func() {
+		if !removed {
+			assert.NoError(t, nfsa.RemoveExport(clusterID, pseudoPath))
+		}
+	}()
+	if assert.NotNil(t, r) {
+		assert.Equal(t, pseudoPath, r.Bind)
+		assert.Equal(t, "cephfs", r.FileSystemName)
+		assert.Equal(t, clusterID, r.Cluster)
+	}
+
+	l, err := nfsa.ListExports(clusterID)
+	assert.NoError(t, err)
+	assert.Contains(t, l, pseudoPath)
+
+	info, err := nfsa.ExportInfo(clusterID, pseudoPath)
+	assert.NoError(t, err)
+	require.NotNil(t, info)
+	assert.Equal(t, pseudoPath, info.PseudoPath)
+	assert.Equal(t, clusterID, info.ClusterID)
+	assert.Equal(t, RootSquash, info.Squash)
+	assert.Equal(t, CephFSFSAL, info.FSAL.Name)
+	assert.Equal(t, "cephfs", info.FSAL.FileSystemName)
+
+	detailed, err := nfsa.ListDetailedExports(clusterID)
+	assert.NoError(t, err)
+	found := false
+	for _, e := range detailed {
+		if e.PseudoPath == pseudoPath {
+			found = true
+			assert.Equal(t, info.ExportID, e.ExportID)
+		}
+	}
+	assert.True(t, found)
+
+	// update the export through its json description
+	info.AccessType = ReadOnlyAccess
+	err = nfsa.ApplyExport(clusterID, *info)
+	assert.NoError(t, err)
+	info, err = nfsa.ExportInfo(clusterID, pseudoPath)
+	assert.NoError(t, err)
+	if assert.NotNil(t, info) {
+		assert.Equal(t, ReadOnlyAccess, info.AccessType)
+	}
+
+	err = nfsa.RemoveExport(clusterID, pseudoPath)
+	assert.NoError(t, err)
+	removed = err == nil
+	l, err = nfsa.ListExports(clusterID)
+	assert.NoError(t, err)
+	assert.NotContains(t, l, pseudoPath)
+}
@@ -0,0 +1,305 @@
+package rados
+
+/*
+#cgo LDFLAGS: -lrados
+#include <stdlib.h>
+#include <rados/librados.h>
+
+extern void aioCompleteCallback(rados_completion_t, uintptr_t);
+
+// inline wrapper to cast uintptr_t to void*
+static inline int wrap_rados_aio_create_completion(uintptr_t arg,
+	rados_completion_t *pc) {
+		return rados_aio_create_completion((void*)arg,
+			(rados_callback_t)aioCompleteCallback, NULL, pc);
+};
+*/
+import "C"
+
+import (
+	"unsafe"
+
+	"github.com/ceph/go-ceph/internal/callbacks"
+)
+
+// aioCallbacks tracks the asynchronous operations that have not yet
+// completed.
+var aioCallbacks = callbacks.New()
+
+// AioCompletion tracks an asynchronous operation started by one of the Aio
+// functions of IOContext. Completion is signalled by librados through a
+// callback so waiting on the Done channel does not block an OS thread.
+// Release must be called once the completion is no longer needed.
+type AioCompletion struct {
+	c       C.rados_completion_t
+	cbIndex uintptr
+	done    chan struct{}
+	ret     C.int
+
+	// buf and cBuf are set for reads: the data is read into the C buffer and
+	// copied into buf once the operation completes.
+	buf  []byte
+	cBuf unsafe.Pointer
+}
+
+// newAioCompletion creates a completion that is registered for the librados
+// completion callback.
+//
+// Implements:
+//  int rados_aio_create_completion(void *cb_arg,
+//                                  rados_callback_t cb_complete,
+//                                  rados_callback_t cb_safe,
+//                                  rados_completion_t *pc);
+func newAioCompletion() (*AioCompletion, error) {
+	ac := &AioCompletion{
+		done: make(chan struct{}),
+	}
+	ac.cbIndex = aioCallbacks.Add(ac)
+	ret := C.wrap_rados_aio_create_completion(C.uintptr_t(ac.cbIndex), &ac.c)
+	if ret != 0 {
+		aioCallbacks.Remove(ac.cbIndex)
+		return nil, getError(ret)
+	}
+	return ac, nil
+}
+
+// started checks the return code of the function that started the
+// asynchronous operation, cleaning up the completion if it failed to start.
+func (ac *AioCompletion) started(ret C.int) (*AioCompletion, error) {
+	if ret < 0 {
+		aioCallbacks.Remove(ac.cbIndex)
+		C.rados_aio_release(ac.c)
+		ac.c = nil
+		ac.freeBuf()
+		return nil, getError(ret)
+	}
+	return ac, nil
+}
+
+func (ac *AioCompletion) freeBuf() {
+	if ac.cBuf != nil {
+		C.free(ac.cBuf)
+		ac.cBuf = nil
+	}
+}
+
+// Done returns a channel that is closed when the asynchronous operation has
+// completed.
+func (ac *AioCompletion) Done() <-chan struct{} {
+	return ac.done
+}
+
+// IsComplete returns true if the asynchronous operation has completed.
+func (ac *AioCompletion) IsComplete() bool {
+	select {
+	case <-ac.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Wait blocks until the asynchronous operation has completed and returns
+// its error, if any.
+func (ac *AioCompletion) Wait() error {
+	<-ac.done
+	return getErrorIfNegative(ac.ret)
+}
+
+// ReturnValue blocks until the asynchronous operation has completed and
+// returns its return value. For reads this is the number of bytes read. A
+// negative value is an error code.
+func (ac *AioCompletion) ReturnValue() int {
+	<-ac.done
+	return int(ac.ret)
+}
+
+// Release the resources associated with the completion. Release waits for
+// the asynchronous operation to complete before releasing it.
+//
+// Implements:
+//  void rados_aio_release(rados_completion_t c);
+func (ac *AioCompletion) Release() {
+	<-ac.done
+	if ac.c != nil {
+		C.rados_aio_release(ac.c)
+		ac.c = nil
+	}
+	ac.freeBuf()
+}
+
+//export aioCompleteCallback
+func aioCompleteCallback(c C.rados_completion_t, index uintptr) {
+	ac := aioCallbacks.Lookup(index).(*AioCompletion)
+	aioCallbacks.Remove(index)
+	ac.ret = C.int(C.rados_aio_get_return_value(c))
+	if ac.ret > 0 && ac.cBuf != nil {
+		copy(ac.buf, C.GoBytes(ac.cBuf, ac.ret))
+	}
+	close(ac.done)
+}
+
+// AioWrite asynchronously writes len(data) bytes to the object with key oid
+// starting at byte offset offset.
+//
+// Implements:
+//  int rados_aio_write(rados_ioctx_t io, const char * oid,
+//                      rados_completion_t completion,
+//                      const char * buf, size_t len, uint64_t off);
+func (ioctx *IOContext) AioWrite(oid string, data []byte, offset uint64) (*AioCompletion, error) {
+	if err := ioctx.validate(); err != nil {
+		return nil, err
+	}
+	ac, err := newAioCompletion()
+	if err != nil {
+		return nil, err
+	}
+
+	cOid := C.CString(oid)
+	defer C.free(unsafe.Pointer(cOid))
+
+	var dataPointer unsafe.Pointer
+	if len(data) > 0 {
+		dataPointer = unsafe.Pointer(&data[0])
+	}
+	ret := C.rados_aio_write(
+		ioctx.ioctx,
+		cOid,
+		ac.c,
+		(*C.char)(dataPointer),
+		C.size_t(len(data)),
+		C.uint64_t(offset))
+	return ac.started(ret)
+}
+
+// AioWriteFull asynchronously replaces the content of the object with key
+// oid with data.
+//
+// Implements:
+//  int rados_aio_write_full(rados_ioctx_t io, const char * oid,
+//                           rados_completion_t completion,
+//                           const char * buf, size_t len);
+func (ioctx *IOContext) AioWriteFull(oid string, data []byte) (*AioCompletion, error) {
+	if err := ioctx.validate(); err != nil {
+		return nil, err
+	}
+	ac, err := newAioCompletion()
+	if err != nil {
+		return nil, err
+	}
+
+	cOid := C.CString(oid)
+	defer C.free(unsafe.Pointer(cOid))
+
+	var dataPointer unsafe.Pointer
+	if len(data) > 0 {
+		dataPointer = unsafe.Pointer(&data[0])
+	}
+	ret := C.rados_aio_write_full(
+		ioctx.ioctx,
+		cOid,
+		ac.c,
+		(*C.char)(dataPointer),
+		C.size_t(len(data)))
+	return ac.started(ret)
+}
+
+// AioAppend asynchronously appends len(data) bytes to the object with key
+// oid.
+//
+// Implements:
+//  int rados_aio_append(rados_ioctx_t io, const char * oid,
+//                       rados_completion_t completion,
+//                       const char * buf, size_t len);
+func (ioctx *IOContext) AioAppend(oid string, data []byte) (*AioCompletion, error) {
+	if err := ioctx.validate(); err != nil {
+		return nil, err
+	}
+	ac, err := newAioCompletion()
+	if err != nil {
+		return nil, err
+	}
+
+	cOid := C.CString(oid)
+	defer C.free(unsafe.Pointer(cOid))
+
+	var dataPointer unsafe.Pointer
+	if len(data) > 0 {
+		dataPointer = unsafe.Pointer(&data[0])
+	}
+	ret := C.rados_aio_append(
+		ioctx.ioctx,
+		cOid,
+		ac.c,
+		(*C.char)(dataPointer),
+		C.size_t(len(data)))
+	return ac.started(ret)
+}
+
+// AioRead asynchronously reads up to len(data) bytes from the object with
+// key oid starting at byte offset offset. The data slice is filled in once
+// the operation completes and must not be used until then. The number of
+// bytes read is available from the ReturnValue of the completion.
+//
+// Implements:
+//  int rados_aio_read(rados_ioctx_t io, const char * oid,
+//                     rados_completion_t completion,
+//                     char * buf, size_t len, uint64_t off);
+func (ioctx *IOContext) AioRead(oid string, data []byte, offset uint64) (*AioCompletion, error) {
+	if err := ioctx.validate(); err != nil {
+		return nil, err
+	}
+	ac, err := newAioCompletion()
+	if err != nil {
+		return nil, err
+	}
+	ac.buf = data
+	if len(data) > 0 {
+		ac.cBuf = C.malloc(C.size_t(len(data)))
+	}
+
+	cOid := C.CString(oid)
+	defer C.free(unsafe.Pointer(cOid))
+
+	ret := C.rados_aio_read(
+		ioctx.ioctx,
+		cOid,
+		ac.c,
+		(*C.char)(ac.cBuf),
+		C.size_t(len(data)),
+		C.uint64_t(offset))
+	return ac.started(ret)
+}
+
+// AioRemove asynchronously deletes the object with key oid.
+//
+// Implements:
+//  int rados_aio_remove(rados_ioctx_t io, const char * oid,
+//                       rados_completion_t completion);
+func (ioctx *IOContext) AioRemove(oid string) (*AioCompletion, error) {
+	if err := ioctx.validate(); err != nil {
+		return nil, err
+	}
+	ac, err := newAioCompletion()
+	if err != nil {
+		return nil, err
+	}
+
+	cOid := C.CString(oid)
+	defer C.free(unsafe.Pointer(cOid))
+
+	ret := C.rados_aio_remove(ioctx.ioctx, cOid, ac.c)
+	return ac.started(ret)
+}
+
+// AioFlush blocks until all the pending asynchronous writes on the I/O
+// context are safe on disk.
+//
+// Implements:
+//  int rados_aio_flush(rados_ioctx_t io);
+func (ioctx *IOContext) AioFlush() error {
+	if err := ioctx.validate(); err != nil {
+		return err
+	}
+	return getError(C.rados_aio_flush(ioctx.ioctx))
+}
@@ -0,0 +1,80 @@
+package rados
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (suite *RadosTestSuite) TestAioInvalidIOContext() {
+	ioctx := &IOContext{}
+	_, err := ioctx.AioWrite("foo", []byte("bar"), 0)
+	assert.Equal(suite.T(), ErrInvalidIOContext, err)
+	_, err = ioctx.AioRead("foo", make([]byte, 3), 0)
+	assert.Equal(suite.T(), ErrInvalidIOContext, err)
+	err = ioctx.AioFlush()
+	assert.Equal(suite.T(), ErrInvalidIOContext, err)
+}
+
+func (suite *RadosTestSuite) TestAioWriteRead() {
+	suite.SetupConnection()
+	oid := suite.GenObjectName()
+
+	ac, err := suite.ioctx.AioWriteFull(oid, []byte("async "))
+	require.NoError(suite.T(), err)
+	assert.NoError(suite.T(), ac.Wait())
+	assert.True(suite.T(), ac.IsComplete())
+	ac.Release()
+
+	ac, err = suite.ioctx.AioAppend(oid, []byte("world"))
+	require.NoError(suite.T(), err)
+	select {
+	case <-ac.Done():
+	case <-time.After(10 * time.Second):
+		suite.T().Fatal("timed out waiting for append")
+	}
+	assert.Equal(suite.T(), 0, ac.ReturnValue())
+	ac.Release()
+
+	ac, err = suite.ioctx.AioWrite(oid, []byte("W"), 6)
+	require.NoError(suite.T(), err)
+	assert.NoError(suite.T(), ac.Wait())
+	ac.Release()
+
+	data := make([]byte, 32)
+	ac, err = suite.ioctx.AioRead(oid, data, 0)
+	require.NoError(suite.T(), err)
+	assert.NoError(suite.T(), ac.Wait())
+	n := ac.ReturnValue()
+	ac.Release()
+	assert.Equal(suite.T(), "async World", string(data[:n]))
+
+	ac, err = suite.ioctx.AioRemove(oid)
+	require.NoError(suite.T(), err)
+	assert.NoError(suite.T(), ac.Wait())
+	ac.Release()
+
+	ac, err = suite.ioctx.AioRead(oid, data, 0)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), ErrNotFound, ac.Wait())
+	ac.Release()
+}
+
+func (suite *RadosTestSuite) TestAioManyInFlight() {
+	suite.SetupConnection()
+
+	completions := make([]*AioCompletion, 100)
+	for i := range completions {
+		oid := fmt.Sprintf("%s_%d", suite.GenObjectName(), i)
+		ac, err := suite.ioctx.AioWriteFull(oid, suite.RandomBytes(128))
+		require.NoError(suite.T(), err)
+		completions[i] = ac
+	}
+	assert.NoError(suite.T(), suite.ioctx.AioFlush())
+	for _, ac := range completions {
+		assert.NoError(suite.T(), ac.Wait())
+		ac.Release()
+	}
+}
@@ -0,0 +1,211 @@
+package rados
+
+// #cgo LDFLAGS: -lrados
+// #include <stdlib.h>
+// #include <rados/librados.h>
+import "C"
+
+import (
+	"unsafe"
+
+	"github.com/ceph/go-ceph/internal/cutil"
+	"github.com/ceph/go-ceph/internal/retry"
+)
+
+// EnableApplication enables the application app on the pool of the I/O
+// context. Unless force is set, enabling a second application on a pool
+// fails.
+//
+// Implements:
+//  int rados_application_enable(rados_ioctx_t io, const char *app_name,
+//                               int force);
+func (ioctx *IOContext) EnableApplication(app string, force bool) error {
+	if err := ioctx.validate(); err != nil {
+		return err
+	}
+	cApp := C.CString(app)
+	defer C.free(unsafe.Pointer(cApp))
+	cForce := C.int(0)
+	if force {
+		cForce = 1
+	}
+	return getError(C.rados_application_enable(ioctx.ioctx, cApp, cForce))
+}
+
+// DisablePoolApplication disables the application app on the named pool.
+func (c *Conn) DisablePoolApplication(pool, app string) error {
+	return c.monCommandJSON(map[string]interface{}{
+		"prefix":               "osd pool application disable",
+		"pool":                 pool,
+		"app":                  app,
+		"yes_i_really_mean_it": true,
+	}, nil)
+}
+
+// ListApplications returns the names of the applications enabled on the
+// pool of the I/O context.
+//
+// Implements:
+//  int rados_application_list(rados_ioctx_t io, char *values,
+//                             size_t *values_len);
+func (ioctx *IOContext) ListApplications() ([]string, error) {
+	if err := ioctx.validate(); err != nil {
+		return nil, err
+	}
+	var (
+		err    error
+		buf    []byte
+		cSize  C.size_t
+		cBufPt *C.char
+	)
+	retry.WithSizes(1024, 262144, func(size int) retry.Hint {
+		cSize = C.size_t(size)
+		buf = make([]byte, size)
+		cBufPt = (*C.char)(unsafe.Pointer(&buf[0]))
+		ret := C.rados_application_list(ioctx.ioctx, cBufPt, &cSize)
+		err = getErrorIfNegative(ret)
+		return retry.Size(int(cSize)).If(err == errRange)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cutil.SplitSparseBuffer(buf[:cSize]), nil
+}
+
+// GetApplicationMetadata returns the value of the metadata key of the
+// application app on the pool of the I/O context.
+//
+// Implements:
+//  int rados_application_metadata_get(rados_ioctx_t io,
+//                                     const char *app_name,
+//                                     const char *key, char *value,
+//                                     size_t *value_len);
+func (ioctx *IOContext) GetApplicationMetadata(app, key string) (string, error) {
+	if err := ioctx.validate(); err != nil {
+		return "", err
+	}
+	cApp := C.CString(app)
+	defer C.free(unsafe.Pointer(cApp))
+	cKey := C.CString(key)
+	defer C.free(unsafe.Pointer(cKey))
+
+	var (
+		err   error
+		buf   []byte
+		cSize C.size_t
+	)
+	retry.WithSizes(64, 65536, func(size int) retry.Hint {
+		cSize = C.size_t(size)
+		buf = make([]byte, size)
+		ret := C.rados_application_metadata_get(
+			ioctx.ioctx,
+			cApp,
+			cKey,
+			(*C.char)(unsafe.Pointer(&buf[0])),
+			&cSize)
+		err = getErrorIfNegative(ret)
+		return retry.Size(int(cSize)).If(err == errRange)
+	})
+	if err != nil {
+		return "", err
+	}
+	return C.GoString((*C.char)(unsafe.Pointer(&buf[0]))), nil
+}
+
+// SetApplicationMetadata sets the metadata key of the application app on
+// the pool of the I/O context to value.
+//
+// Implements:
+//  int rados_application_metadata_set(rados_ioctx_t io,
+//                                     const char *app_name,
+//                                     const char *key,
+//                                     const char *value);
+func (ioctx *IOContext) SetApplicationMetadata(app, key, value string) error {
+	if err := ioctx.validate(); err != nil {
+		return err
+	}
+	cApp := C.CString(app)
+	defer C.free(unsafe.Pointer(cApp))
+	cKey := C.CString(key)
+	defer C.free(unsafe.Pointer(cKey))
+	cValue := C.CString(value)
+	defer C.free(unsafe.Pointer(cValue))
+	ret := C.rados_application_metadata_set(ioctx.ioctx, cApp, cKey, cValue)
+	return getError(ret)
+}
+
+// RemoveApplicationMetadata removes the metadata key of the application app
+// on the pool of the I/O context.
+//
+// Implements:
+//  int rados_application_metadata_remove(rados_ioctx_t io,
+//                                        const char *app_name,
+//                                        const char *key);
+func (ioctx *IOContext) RemoveApplicationMetadata(app, key string) error {
+	if err := ioctx.validate(); err != nil {
+		return err
+	}
+	cApp := C.CString(app)
+	defer C.free(unsafe.Pointer(cApp))
+	cKey := C.CString(key)
+	defer C.free(unsafe.Pointer(cKey))
+	ret := C.rados_application_metadata_remove(ioctx.ioctx, cApp, cKey)
+	return getError(ret)
+}
+
+// ListApplicationMetadata returns all the metadata of the application app
+// on the pool of the I/O context.
+//
+// Implements:
+//  int rados_application_metadata_list(rados_ioctx_t io,
+//                                      const char *app_name,
+//                                      char *keys, size_t *key_len,
+//                                      char *values, size_t *vals_len);
+func (ioctx *IOContext) ListApplicationMetadata(app string) (map[string]string, error) {
+	if err := ioctx.validate(); err != nil {
+		return nil, err
+	}
+	cApp := C.CString(app)
+	defer C.free(unsafe.Pointer(cApp))
+
+	var (
+		err        error
+		keys, vals []byte
+		cKeysSize  C.size_t
+		cValsSize  C.size_t
+	)
+	retry.WithSizes(1024, 262144, func(size int) retry.Hint {
+		// on ERANGE both lengths are updated to the required sizes
+		if int(cKeysSize) < size {
+			cKeysSize = C.size_t(size)
+		}
+		if int(cValsSize) < size {
+			cValsSize = C.size_t(size)
+		}
+		keys = make([]byte, cKeysSize)
+		vals = make([]byte, cValsSize)
+		ret := C.rados_application_metadata_list(
+			ioctx.ioctx,
+			cApp,
+			(*C.char)(unsafe.Pointer(&keys[0])),
+			&cKeysSize,
+			(*C.char)(unsafe.Pointer(&vals[0])),
+			&cValsSize)
+		err = getErrorIfNegative(ret)
+		return retry.DoubleSize.If(err == errRange)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// values may be empty strings, so the buffers must not be split sparsely
+	k := cutil.SplitBuffer(keys[:cKeysSize])
+	v := cutil.SplitBuffer(vals[:cValsSize])
+	m := make(map[string]string, len(k))
+	for i := range k {
+		if i < len(v) {
+			m[k[i]] = v[i]
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,65 @@
+package rados
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The tests in this file rely on the "hello" object class that is shipped
+// with ceph and loaded by default by the OSDs.
+
+func (suite *RadosTestSuite) TestExec() {
+	suite.SetupConnection()
+	oid := suite.GenObjectName()
+
+	_, err := (&IOContext{}).Exec(oid, "hello", "say_hello", nil)
+	assert.Equal(suite.T(), ErrInvalidIOContext, err)
+
+	out, err := suite.ioctx.Exec(oid, "hello", "record_hello", []byte("ceph"))
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), out, 0)
+
+	data := make([]byte, 64)
+	n, err := suite.ioctx.Read(oid, data, 0)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Hello, ceph!", string(data[:n]))
+
+	out, err = suite.ioctx.Exec(oid, "hello", "say_hello", []byte("go"))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Hello, go!", string(out))
+
+	_, err = suite.ioctx.Exec(oid, "hello", "no_such_method", nil)
+	assert.Error(suite.T(), err)
+}
+
+func (suite *RadosTestSuite) TestOpExec() {
+	suite.SetupConnection()
+	oid := suite.GenObjectName()
+
+	wop := CreateWriteOp()
+	defer wop.Release()
+	recordStep := wop.Exec("hello", "record_hello", []byte("world"))
+	wop.SetXattr("greeted", []byte("yes"))
+	err := wop.Operate(suite.ioctx, oid, OperationNoFlag)
+	require.NoError(suite.T(), err)
+	assert.NoError(suite.T(), recordStep.Err)
+
+	rop := CreateReadOp()
+	defer rop.Release()
+	sayStep := rop.Exec("hello", "say_hello", nil)
+	statStep := rop.Stat()
+	err = rop.Operate(suite.ioctx, oid, OperationNoFlag)
+	require.NoError(suite.T(), err)
+	assert.NoError(suite.T(), sayStep.Err)
+	assert.Equal(suite.T(), "Hello, world!", string(sayStep.Output))
+	assert.NoError(suite.T(), statStep.Err)
+	assert.EqualValues(suite.T(), len("Hello, world!"), statStep.Size)
+
+	// record_hello refuses to overwrite an existing object
+	wop2 := CreateWriteOp()
+	defer wop2.Release()
+	recordStep = wop2.Exec("hello", "record_hello", []byte("again"))
+	err = wop2.Operate(suite.ioctx, oid, OperationNoFlag)
+	assert.Error(suite.T(), err)
+	assert.Error(suite.T(), recordStep.Err)
+}
@@ -128,15 +128,10 @@ func (ioctx *IOContext) SetNamespace(namespace string) {
 //  void rados_write_op_create(rados_write_op_t write_op, int exclusive,
 //                             const char* category)
 func (ioctx *IOContext) Create(oid string, exclusive CreateOption) error {
-	c_oid := C.CString(oid)
-	defer C.free(unsafe.Pointer(c_oid))
-
-	op := C.rados_create_write_op()
-	C.rados_write_op_create(op, C.int(exclusive), nil)
-	ret := C.rados_write_op_operate(op, ioctx.ioctx, c_oid, nil, 0)
-	C.rados_release_write_op(op)
-
-	return getError(ret)
+	op := CreateWriteOp()
+	defer op.Release()
+	op.Create(exclusive)
+	return op.Operate(ioctx, oid, OperationNoFlag)
 }
 
 // Write writes len(data) bytes to the object with key oid starting at byte
@@ -663,3 +658,52 @@ func (ioctx *IOContext) GetLastVersion() (uint64, error) {
 	v := C.rados_get_last_version(ioctx.ioctx)
 	return uint64(v), nil
 }
+
+// execOutputSize is the size of the buffer that receives the output of an
+// object class method called by Exec.
+const execOutputSize = 4 * 1024 * 1024
+
+// Exec calls the method of the object class cls on the object with key oid,
+// passing it the input data in, and returns the output of the method.
+// Methods that produce more than 4 MiB of output fail with an error, for
+// methods that only read the object ReadOp.Exec has no such limit.
+//
+// Implements:
+//  int rados_exec(rados_ioctx_t io, const char *oid, const char *cls,
+//                 const char *method, const char *in_buf, size_t in_len,
+//                 char *buf, size_t out_len);
+func (ioctx *IOContext) Exec(oid, cls, method string, in []byte) ([]byte, error) {
+	if err := ioctx.validate(); err != nil {
+		return nil, err
+	}
+
+	cOid := C.CString(oid)
+	defer C.free(unsafe.Pointer(cOid))
+	cCls := C.CString(cls)
+	defer C.free(unsafe.Pointer(cCls))
+	cMethod := C.CString(method)
+	defer C.free(unsafe.Pointer(cMethod))
+
+	var inBuf *C.char
+	if len(in) > 0 {
+		inBuf = (*C.char)(unsafe.Pointer(&in[0]))
+	}
+	// The output buffer is allocated once rather than retried with larger
+	// sizes: methods may modify the object and must not be called twice.
+	outBuf := C.malloc(execOutputSize)
+	defer C.free(outBuf)
+
+	ret := C.rados_exec(
+		ioctx.ioctx,
+		cOid,
+		cCls,
+		cMethod,
+		inBuf,
+		C.size_t(len(in)),
+		(*C.char)(outBuf),
+		execOutputSize)
+	if ret < 0 {
+		return nil, getError(ret)
+	}
+	return C.GoBytes(outBuf, ret), nil
+}
@@ -0,0 +1,155 @@
+package rados
+
+// #cgo LDFLAGS: -lrados
+// #include <stdlib.h>
+// #include <rados/librados.h>
+//
+import "C"
+
+import (
+	"unsafe"
+)
+
+// OperationFlags control the behavior of a compound read or write operation
+// as a whole. They are passed to the Operate functions of ReadOp and WriteOp.
+type OperationFlags int
+
+const (
+	// OperationNoFlag indicates no special behavior is requested.
+	OperationNoFlag = OperationFlags(C.LIBRADOS_OPERATION_NOFLAG)
+	// OperationBalanceReads allows reads to be served by any replica.
+	OperationBalanceReads = OperationFlags(C.LIBRADOS_OPERATION_BALANCE_READS)
+	// OperationLocalizeReads allows reads to be served by the closest
+	// replica.
+	OperationLocalizeReads = OperationFlags(C.LIBRADOS_OPERATION_LOCALIZE_READS)
+	// OperationOrderReadsWrites orders reads with respect to writes.
+	OperationOrderReadsWrites = OperationFlags(C.LIBRADOS_OPERATION_ORDER_READS_WRITES)
+	// OperationIgnoreCache bypasses any cache tier.
+	OperationIgnoreCache = OperationFlags(C.LIBRADOS_OPERATION_IGNORE_CACHE)
+	// OperationSkipRWLocks skips the OSD's read/write object locks.
+	OperationSkipRWLocks = OperationFlags(C.LIBRADOS_OPERATION_SKIPRWLOCKS)
+	// OperationIgnoreOverlay ignores any pool overlay (cache tier).
+	OperationIgnoreOverlay = OperationFlags(C.LIBRADOS_OPERATION_IGNORE_OVERLAY)
+	// OperationFullTry sends the request to a full cluster or pool and
+	// allows operations that free space (such as deletes) to succeed.
+	OperationFullTry = OperationFlags(C.LIBRADOS_OPERATION_FULL_TRY)
+	// OperationFullForce forces the operation to proceed even if the
+	// cluster or pool is full.
+	OperationFullForce = OperationFlags(C.LIBRADOS_OPERATION_FULL_FORCE)
+)
+
+// ComparisonOp selects the kind of comparison performed by the compare
+// steps of a compound operation, such as WriteOp.CmpXattr.
+type ComparisonOp uint8
+
+const (
+	// CompareEqual is satisfied if the stored value equals the given value.
+	CompareEqual = ComparisonOp(C.LIBRADOS_CMPXATTR_OP_EQ)
+	// CompareNotEqual is satisfied if the stored value does not equal the
+	// given value.
+	CompareNotEqual = ComparisonOp(C.LIBRADOS_CMPXATTR_OP_NE)
+	// CompareGreater is satisfied if the stored value is greater than the
+	// given value.
+	CompareGreater = ComparisonOp(C.LIBRADOS_CMPXATTR_OP_GT)
+	// CompareGreaterEqual is satisfied if the stored value is greater than
+	// or equal to the given value.
+	CompareGreaterEqual = ComparisonOp(C.LIBRADOS_CMPXATTR_OP_GTE)
+	// CompareLess is satisfied if the stored value is less than the given
+	// value.
+	CompareLess = ComparisonOp(C.LIBRADOS_CMPXATTR_OP_LT)
+	// CompareLessEqual is satisfied if the stored value is less than or
+	// equal to the given value.
+	CompareLessEqual = ComparisonOp(C.LIBRADOS_CMPXATTR_OP_LTE)
+)
+
+var (
+	cPtrSize  = unsafe.Sizeof((*C.char)(nil))
+	cSizeSize = unsafe.Sizeof(C.size_t(0))
+)
+
+// opStep is implemented by the steps of a compound operation that produce
+// results. update is called once the operation has been performed in order
+// to convert the C results to Go values and free is called when the
+// operation is released.
+type opStep interface {
+	update()
+	free()
+}
+
+// operation tracks the C memory that backs the arguments of the steps
+// added to a compound operation. librados only keeps pointers to step
+// arguments so this memory must remain valid until the operation has been
+// performed and the operation is released.
+type operation struct {
+	cmem  []unsafe.Pointer
+	steps []opStep
+}
+
+// addStep records a step that needs to be updated and freed along with the
+// operation.
+func (o *operation) addStep(s opStep) {
+	o.steps = append(o.steps, s)
+}
+
+// updateSteps converts the results of all the steps of the operation.
+func (o *operation) updateSteps() {
+	for _, s := range o.steps {
+		s.update()
+	}
+}
+
+// cString returns a C copy of s that is freed when the operation is freed.
+func (o *operation) cString(s string) *C.char {
+	cs := C.CString(s)
+	o.cmem = append(o.cmem, unsafe.Pointer(cs))
+	return cs
+}
+
+// cBytes returns a C copy of b that is freed when the operation is freed.
+// A nil pointer is returned for an empty slice.
+func (o *operation) cBytes(b []byte) *C.char {
+	if len(b) == 0 {
+		return nil
+	}
+	cb := C.CBytes(b)
+	o.cmem = append(o.cmem, cb)
+	return (*C.char)(cb)
+}
+
+// cAlloc returns n bytes of zeroed C memory that is freed when the
+// operation is freed.
+func (o *operation) cAlloc(n uintptr) unsafe.Pointer {
+	if n == 0 {
+		n = 1
+	}
+	p := C.calloc(1, C.size_t(n))
+	o.cmem = append(o.cmem, p)
+	return p
+}
+
+// cInt returns a zeroed C int that is freed when the operation is freed.
+func (o *operation) cInt() *C.int {
+	return (*C.int)(o.cAlloc(unsafe.Sizeof(C.int(0))))
+}
+
+// cStringArray returns a C array of C strings copied from strs.
+func (o *operation) cStringArray(strs []string) **C.char {
+	arr := o.cAlloc(uintptr(len(strs)) * cPtrSize)
+	for i, s := range strs {
+		p := (**C.char)(unsafe.Pointer(uintptr(arr) + uintptr(i)*cPtrSize))
+		*p = o.cString(s)
+	}
+	return (**C.char)(arr)
+}
+
+// freeMem releases all the C memory held by the operation and its steps.
+func (o *operation) freeMem() {
+	for _, s := range o.steps {
+		s.free()
+	}
+	o.steps = nil
+	for _, p := range o.cmem {
+		C.free(p)
+	}
+	o.cmem = nil
+}
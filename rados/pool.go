@@ -0,0 +1,242 @@
+package rados
+
+// #cgo LDFLAGS: -lrados
+// #include <stdlib.h>
+// #include <rados/librados.h>
+import "C"
+
+import (
+	"encoding/json"
+	"strconv"
+	"unsafe"
+)
+
+// PoolType is the kind of data protection used by a pool.
+type PoolType string
+
+const (
+	// PoolTypeReplicated pools store multiple copies of every object.
+	PoolTypeReplicated = PoolType("replicated")
+	// PoolTypeErasure pools store objects using erasure coding.
+	PoolTypeErasure = PoolType("erasure")
+)
+
+// PoolOptions are the optional parameters of MakePoolWithOptions. Fields
+// left at their zero value use the cluster defaults.
+type PoolOptions struct {
+	// PGNum is the number of placement groups of the pool.
+	PGNum int
+	// PoolType selects a replicated or an erasure coded pool.
+	PoolType PoolType
+	// CrushRule is the name of the CRUSH rule used by the pool.
+	CrushRule string
+	// ErasureCodeProfile is the name of the erasure code profile of an
+	// erasure coded pool.
+	ErasureCodeProfile string
+}
+
+// PoolQuota holds the quotas of a pool. A value of zero means no quota.
+type PoolQuota struct {
+	MaxBytes   uint64 `json:"quota_max_bytes"`
+	MaxObjects uint64 `json:"quota_max_objects"`
+}
+
+// MakePoolWithCrushRule creates a new pool that uses the CRUSH rule with
+// the given rule number.
+//
+// Implements:
+//  int rados_pool_create_with_crush_rule(rados_t cluster,
+//                                        const char *pool_name,
+//                                        uint8_t crush_rule_num);
+func (c *Conn) MakePoolWithCrushRule(name string, crushRule uint8) error {
+	if err := c.ensure_connected(); err != nil {
+		return err
+	}
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+	ret := C.rados_pool_create_with_crush_rule(
+		c.cluster, cName, C.uint8_t(crushRule))
+	return getError(ret)
+}
+
+// MakePoolWithOptions creates a new pool with the given options.
+func (c *Conn) MakePoolWithOptions(name string, o *PoolOptions) error {
+	if err := c.ensure_connected(); err != nil {
+		return err
+	}
+	cmd := map[string]interface{}{
+		"prefix": "osd pool create",
+		"pool":   name,
+	}
+	if o != nil {
+		if o.PGNum > 0 {
+			cmd["pg_num"] = o.PGNum
+			cmd["pgp_num"] = o.PGNum
+		}
+		if o.PoolType != "" {
+			cmd["pool_type"] = string(o.PoolType)
+		}
+		if o.CrushRule != "" {
+			cmd["rule"] = o.CrushRule
+		}
+		if o.ErasureCodeProfile != "" {
+			cmd["erasure_code_profile"] = o.ErasureCodeProfile
+		}
+	}
+	return c.monCommandJSON(cmd, nil)
+}
+
+// GetPoolBaseTier returns the ID of the base tier of the pool with the
+// given ID. If the pool is not a cache tier its own ID is returned.
+//
+// Implements:
+//  int rados_pool_get_base_tier(rados_t cluster, int64_t pool,
+//                               int64_t* base_tier);
+func (c *Conn) GetPoolBaseTier(poolID int64) (int64, error) {
+	if err := c.ensure_connected(); err != nil {
+		return 0, err
+	}
+	var baseTier C.int64_t
+	ret := C.rados_pool_get_base_tier(c.cluster, C.int64_t(poolID), &baseTier)
+	if ret < 0 {
+		return 0, getError(ret)
+	}
+	return int64(baseTier), nil
+}
+
+// GetPoolQuota returns the quotas of the named pool.
+func (c *Conn) GetPoolQuota(pool string) (PoolQuota, error) {
+	var q PoolQuota
+	err := c.monCommandJSON(map[string]interface{}{
+		"prefix": "osd pool get-quota",
+		"pool":   pool,
+		"format": "json",
+	}, &q)
+	return q, err
+}
+
+// SetPoolQuota sets the quotas of the named pool. A value of zero removes
+// the corresponding quota.
+func (c *Conn) SetPoolQuota(pool string, q PoolQuota) error {
+	fields := []struct {
+		name  string
+		value uint64
+	}{
+		{"max_bytes", q.MaxBytes},
+		{"max_objects", q.MaxObjects},
+	}
+	for _, f := range fields {
+		err := c.monCommandJSON(map[string]interface{}{
+			"prefix": "osd pool set-quota",
+			"pool":   pool,
+			"field":  f.name,
+			"val":    strconv.FormatUint(f.value, 10),
+		}, nil)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetPoolPGNum returns the number of placement groups of the named pool.
+func (c *Conn) GetPoolPGNum(pool string) (int, error) {
+	var r struct {
+		PGNum int `json:"pg_num"`
+	}
+	err := c.getPoolVar(pool, "pg_num", &r)
+	return r.PGNum, err
+}
+
+// SetPoolPGNum sets the number of placement groups of the named pool.
+func (c *Conn) SetPoolPGNum(pool string, pgNum int) error {
+	return c.setPoolVar(pool, "pg_num", strconv.Itoa(pgNum))
+}
+
+// GetPoolSize returns the number of replicas of the objects in the named
+// pool.
+func (c *Conn) GetPoolSize(pool string) (int, error) {
+	var r struct {
+		Size int `json:"size"`
+	}
+	err := c.getPoolVar(pool, "size", &r)
+	return r.Size, err
+}
+
+// SetPoolSize sets the number of replicas of the objects in the named pool.
+func (c *Conn) SetPoolSize(pool string, size int) error {
+	return c.setPoolVar(pool, "size", strconv.Itoa(size))
+}
+
+func (c *Conn) getPoolVar(pool, name string, out interface{}) error {
+	return c.monCommandJSON(map[string]interface{}{
+		"prefix": "osd pool get",
+		"pool":   pool,
+		"var":    name,
+		"format": "json",
+	}, out)
+}
+
+func (c *Conn) setPoolVar(pool, name, value string) error {
+	return c.monCommandJSON(map[string]interface{}{
+		"prefix": "osd pool set",
+		"pool":   pool,
+		"var":    name,
+		"val":    value,
+	}, nil)
+}
+
+// monCommandJSON sends the JSON encoded cmd to the monitors and, if out is
+// not nil, decodes the JSON response into out.
+func (c *Conn) monCommandJSON(cmd map[string]interface{}, out interface{}) error {
+	if err := c.ensure_connected(); err != nil {
+		return err
+	}
+	args, err := json.Marshal(cmd)
+	if err != nil {
+		return err
+	}
+	buf, _, err := c.MonCommand(args)
+	if err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(buf, out)
+}
+
+// RequiresAlignment returns true if the pool of the I/O context requires
+// writes to be aligned, as is the case for some erasure coded pools.
+//
+// Implements:
+//  int rados_ioctx_pool_requires_alignment2(rados_ioctx_t io, int *req);
+func (ioctx *IOContext) RequiresAlignment() (bool, error) {
+	if err := ioctx.validate(); err != nil {
+		return false, err
+	}
+	var req C.int
+	ret := C.rados_ioctx_pool_requires_alignment2(ioctx.ioctx, &req)
+	if ret < 0 {
+		return false, getError(ret)
+	}
+	return req != 0, nil
+}
+
+// RequiredAlignment returns the alignment required for writes to the pool
+// of the I/O context.
+//
+// Implements:
+//  int rados_ioctx_pool_required_alignment2(rados_ioctx_t io,
+//                                           uint64_t *alignment);
+func (ioctx *IOContext) RequiredAlignment() (uint64, error) {
+	if err := ioctx.validate(); err != nil {
+		return 0, err
+	}
+	var alignment C.uint64_t
+	ret := C.rados_ioctx_pool_required_alignment2(ioctx.ioctx, &alignment)
+	if ret < 0 {
+		return 0, getError(ret)
+	}
+	return uint64(alignment), nil
+}
@@ -0,0 +1,492 @@
+package rados
+
+// #cgo LDFLAGS: -lrados
+// #include <stdlib.h>
+// #include <rados/librados.h>
+//
+import "C"
+
+import (
+	"time"
+	"unsafe"
+)
+
+// ReadOp manages a set of discrete read actions that will be performed
+// together on a single object in a single round trip. Steps that produce
+// output return a step object whose fields are populated once Operate has
+// been called.
+type ReadOp struct {
+	operation
+	op C.rados_read_op_t
+}
+
+// CreateReadOp returns a newly constructed read operation. The ReadOp must
+// be released by calling Release when it is no longer needed.
+//
+// Implements:
+//  rados_read_op_t rados_create_read_op(void)
+func CreateReadOp() *ReadOp {
+	return &ReadOp{
+		op: C.rados_create_read_op(),
+	}
+}
+
+// Release the resources associated with this read operation. The results
+// already stored in the step objects remain valid.
+//
+// Implements:
+//  void rados_release_read_op(rados_read_op_t read_op)
+func (r *ReadOp) Release() {
+	if r.op != nil {
+		C.rados_release_read_op(r.op)
+		r.op = nil
+	}
+	r.freeMem()
+}
+
+// Operate performs all the steps of the read operation on the object with
+// key oid. The flags apply to the operation as a whole. The results of the
+// individual steps, including their own errors, are available from the step
+// objects after Operate returns.
+//
+// Implements:
+//  int rados_read_op_operate(rados_read_op_t read_op,
+//                            rados_ioctx_t io,
+//                            const char *oid,
+//                            int flags)
+func (r *ReadOp) Operate(ioctx *IOContext, oid string, flags OperationFlags) error {
+	if err := ioctx.validate(); err != nil {
+		return err
+	}
+
+	cOid := C.CString(oid)
+	defer C.free(unsafe.Pointer(cOid))
+
+	ret := C.rados_read_op_operate(r.op, ioctx.ioctx, cOid, C.int(flags))
+	r.updateSteps()
+	return getError(ret)
+}
+
+// SetOpFlags sets the flags of the most recently added step of the read
+// operation.
+//
+// Implements:
+//  void rados_read_op_set_flags(rados_read_op_t read_op, int flags)
+func (r *ReadOp) SetOpFlags(flags OpFlags) {
+	C.rados_read_op_set_flags(r.op, C.int(flags))
+}
+
+// AssertExists ensures the object exists before the rest of the read
+// operation is performed.
+//
+// Implements:
+//  void rados_read_op_assert_exists(rados_read_op_t read_op)
+func (r *ReadOp) AssertExists() {
+	C.rados_read_op_assert_exists(r.op)
+}
+
+// AssertVersion ensures the object's version is equal to v before the rest
+// of the read operation is performed.
+//
+// Implements:
+//  void rados_read_op_assert_version(rados_read_op_t read_op, uint64_t ver)
+func (r *ReadOp) AssertVersion(v uint64) {
+	C.rados_read_op_assert_version(r.op, C.uint64_t(v))
+}
+
+// CmpXattr ensures that the comparison between the value of the xattr
+// named name and value is satisfied before the rest of the read operation
+// is performed.
+//
+// Implements:
+//  void rados_read_op_cmpxattr(rados_read_op_t read_op,
+//                              const char *name,
+//                              uint8_t comparison_operator,
+//                              const char *value,
+//                              size_t value_len)
+func (r *ReadOp) CmpXattr(name string, op ComparisonOp, value []byte) {
+	C.rados_read_op_cmpxattr(
+		r.op,
+		r.cString(name),
+		C.uint8_t(op),
+		r.cBytes(value),
+		C.size_t(len(value)))
+}
+
+// ReadOpStatStep holds the result of a Stat step of a ReadOp.
+type ReadOpStatStep struct {
+	// Size of the object in bytes.
+	Size uint64
+	// ModTime is the last modification time of the object.
+	ModTime time.Time
+	// Err is the result of this step.
+	Err error
+
+	cSize  *C.uint64_t
+	cMtime *C.time_t
+	cRval  *C.int
+}
+
+func (s *ReadOpStatStep) update() {
+	s.Err = getError(*s.cRval)
+	if s.Err == nil {
+		s.Size = uint64(*s.cSize)
+		s.ModTime = time.Unix(int64(*s.cMtime), 0)
+	}
+}
+
+func (*ReadOpStatStep) free() {}
+
+// Stat adds a step that fetches the size and modification time of the
+// object.
+//
+// Implements:
+//  void rados_read_op_stat(rados_read_op_t read_op,
+//                          uint64_t *psize,
+//                          time_t *pmtime,
+//                          int *prval)
+func (r *ReadOp) Stat() *ReadOpStatStep {
+	s := &ReadOpStatStep{
+		cSize:  (*C.uint64_t)(r.cAlloc(unsafe.Sizeof(C.uint64_t(0)))),
+		cMtime: (*C.time_t)(r.cAlloc(unsafe.Sizeof(C.time_t(0)))),
+		cRval:  r.cInt(),
+	}
+	r.addStep(s)
+	C.rados_read_op_stat(r.op, s.cSize, s.cMtime, s.cRval)
+	return s
+}
+
+// ReadOpReadStep holds the result of a Read step of a ReadOp.
+type ReadOpReadStep struct {
+	// BytesRead is the number of bytes copied into the buffer.
+	BytesRead int64
+	// Err is the result of this step.
+	Err error
+
+	buf        []byte
+	cBuf       *C.char
+	cBytesRead *C.size_t
+	cRval      *C.int
+}
+
+func (s *ReadOpReadStep) update() {
+	s.Err = getError(*s.cRval)
+	if s.Err == nil {
+		n := int(*s.cBytesRead)
+		if n > len(s.buf) {
+			n = len(s.buf)
+		}
+		copy(s.buf, C.GoBytes(unsafe.Pointer(s.cBuf), C.int(n)))
+		s.BytesRead = int64(n)
+	}
+}
+
+func (*ReadOpReadStep) free() {}
+
+// Read adds a step that reads up to len(buf) bytes of the object, starting
+// at byte offset offset, into buf.
+//
+// Implements:
+//  void rados_read_op_read(rados_read_op_t read_op,
+//                          uint64_t offset,
+//                          size_t len,
+//                          char *buffer,
+//                          size_t *bytes_read,
+//                          int *prval)
+func (r *ReadOp) Read(offset uint64, buf []byte) *ReadOpReadStep {
+	s := &ReadOpReadStep{
+		buf:        buf,
+		cBuf:       (*C.char)(r.cAlloc(uintptr(len(buf)))),
+		cBytesRead: (*C.size_t)(r.cAlloc(cSizeSize)),
+		cRval:      r.cInt(),
+	}
+	r.addStep(s)
+	C.rados_read_op_read(
+		r.op,
+		C.uint64_t(offset),
+		C.size_t(len(buf)),
+		s.cBuf,
+		s.cBytesRead,
+		s.cRval)
+	return s
+}
+
+// ReadOpGetXattrsStep holds the result of a GetXattrs step of a ReadOp.
+type ReadOpGetXattrsStep struct {
+	// Xattrs maps the names of the xattrs of the object to their values.
+	Xattrs map[string][]byte
+	// Err is the result of this step.
+	Err error
+
+	cIter *C.rados_xattrs_iter_t
+	cRval *C.int
+}
+
+func (s *ReadOpGetXattrsStep) update() {
+	s.Err = getError(*s.cRval)
+	if s.Err != nil {
+		return
+	}
+	s.Xattrs = make(map[string][]byte)
+	for {
+		var cName, cVal *C.char
+		var cLen C.size_t
+		ret := C.rados_getxattrs_next(*s.cIter, &cName, &cVal, &cLen)
+		if ret < 0 {
+			s.Err = getError(ret)
+			return
+		}
+		if cName == nil {
+			return
+		}
+		s.Xattrs[C.GoString(cName)] = C.GoBytes(unsafe.Pointer(cVal), C.int(cLen))
+	}
+}
+
+func (s *ReadOpGetXattrsStep) free() {
+	if *s.cIter != nil {
+		C.rados_getxattrs_end(*s.cIter)
+		*s.cIter = nil
+	}
+}
+
+// GetXattrs adds a step that fetches all the xattrs of the object.
+//
+// Implements:
+//  void rados_read_op_getxattrs(rados_read_op_t read_op,
+//                               rados_xattrs_iter_t *iter,
+//                               int *prval)
+func (r *ReadOp) GetXattrs() *ReadOpGetXattrsStep {
+	s := &ReadOpGetXattrsStep{
+		cIter: (*C.rados_xattrs_iter_t)(r.cAlloc(cPtrSize)),
+		cRval: r.cInt(),
+	}
+	r.addStep(s)
+	C.rados_read_op_getxattrs(r.op, s.cIter, s.cRval)
+	return s
+}
+
+// ReadOpOmapGetKeysStep holds the result of a GetOmapKeys step of a ReadOp.
+type ReadOpOmapGetKeysStep struct {
+	// Keys are the omap keys returned, in order.
+	Keys []string
+	// More is true if more keys are available beyond the ones returned.
+	More bool
+	// Err is the result of this step.
+	Err error
+
+	cIter *C.rados_omap_iter_t
+	cMore *C.uchar
+	cRval *C.int
+}
+
+func (s *ReadOpOmapGetKeysStep) update() {
+	s.Err = getError(*s.cRval)
+	if s.Err != nil {
+		return
+	}
+	s.More = *s.cMore != 0
+	s.Keys = []string{}
+	s.Err = iterateOmap(*s.cIter, func(key string, _ []byte) {
+		s.Keys = append(s.Keys, key)
+	})
+}
+
+func (s *ReadOpOmapGetKeysStep) free() {
+	if *s.cIter != nil {
+		C.rados_omap_get_end(*s.cIter)
+		*s.cIter = nil
+	}
+}
+
+// GetOmapKeys adds a step that lists up to maxReturn omap keys of the object
+// that sort after startAfter.
+//
+// Implements:
+//  void rados_read_op_omap_get_keys2(rados_read_op_t read_op,
+//                                    const char *start_after,
+//                                    uint64_t max_return,
+//                                    rados_omap_iter_t *iter,
+//                                    unsigned char *pmore,
+//                                    int *prval)
+func (r *ReadOp) GetOmapKeys(startAfter string, maxReturn uint64) *ReadOpOmapGetKeysStep {
+	s := &ReadOpOmapGetKeysStep{
+		cIter: (*C.rados_omap_iter_t)(r.cAlloc(cPtrSize)),
+		cMore: (*C.uchar)(r.cAlloc(1)),
+		cRval: r.cInt(),
+	}
+	r.addStep(s)
+	C.rados_read_op_omap_get_keys2(
+		r.op,
+		r.cString(startAfter),
+		C.uint64_t(maxReturn),
+		s.cIter,
+		s.cMore,
+		s.cRval)
+	return s
+}
+
+// ReadOpOmapGetValsStep holds the result of a GetOmapValues or
+// GetOmapValuesByKeys step of a ReadOp.
+type ReadOpOmapGetValsStep struct {
+	// Pairs maps the omap keys returned to their values.
+	Pairs map[string][]byte
+	// More is true if more key-value pairs are available beyond the ones
+	// returned. It is always false for GetOmapValuesByKeys.
+	More bool
+	// Err is the result of this step.
+	Err error
+
+	cIter *C.rados_omap_iter_t
+	cMore *C.uchar
+	cRval *C.int
+}
+
+func (s *ReadOpOmapGetValsStep) update() {
+	s.Err = getError(*s.cRval)
+	if s.Err != nil {
+		return
+	}
+	if s.cMore != nil {
+		s.More = *s.cMore != 0
+	}
+	s.Pairs = make(map[string][]byte)
+	s.Err = iterateOmap(*s.cIter, func(key string, value []byte) {
+		s.Pairs[key] = value
+	})
+}
+
+func (s *ReadOpOmapGetValsStep) free() {
+	if *s.cIter != nil {
+		C.rados_omap_get_end(*s.cIter)
+		*s.cIter = nil
+	}
+}
+
+// GetOmapValues adds a step that fetches up to maxReturn omap key-value
+// pairs of the object whose keys sort after startAfter and begin with
+// filterPrefix.
+//
+// Implements:
+//  void rados_read_op_omap_get_vals2(rados_read_op_t read_op,
+//                                    const char *start_after,
+//                                    const char *filter_prefix,
+//                                    uint64_t max_return,
+//                                    rados_omap_iter_t *iter,
+//                                    unsigned char *pmore,
+//                                    int *prval)
+func (r *ReadOp) GetOmapValues(startAfter, filterPrefix string, maxReturn uint64) *ReadOpOmapGetValsStep {
+	s := &ReadOpOmapGetValsStep{
+		cIter: (*C.rados_omap_iter_t)(r.cAlloc(cPtrSize)),
+		cMore: (*C.uchar)(r.cAlloc(1)),
+		cRval: r.cInt(),
+	}
+	r.addStep(s)
+	C.rados_read_op_omap_get_vals2(
+		r.op,
+		r.cString(startAfter),
+		r.cString(filterPrefix),
+		C.uint64_t(maxReturn),
+		s.cIter,
+		s.cMore,
+		s.cRval)
+	return s
+}
+
+// GetOmapValuesByKeys adds a step that fetches the values of the given omap
+// keys of the object. Keys that do not exist are not included in the
+// result.
+//
+// Implements:
+//  void rados_read_op_omap_get_vals_by_keys(rados_read_op_t read_op,
+//                                           char const* const* keys,
+//                                           size_t keys_len,
+//                                           rados_omap_iter_t *iter,
+//                                           int *prval)
+func (r *ReadOp) GetOmapValuesByKeys(keys []string) *ReadOpOmapGetValsStep {
+	s := &ReadOpOmapGetValsStep{
+		cIter: (*C.rados_omap_iter_t)(r.cAlloc(cPtrSize)),
+		cRval: r.cInt(),
+	}
+	r.addStep(s)
+	C.rados_read_op_omap_get_vals_by_keys(
+		r.op,
+		r.cStringArray(keys),
+		C.size_t(len(keys)),
+		s.cIter,
+		s.cRval)
+	return s
+}
+
+// ReadOpExecStep holds the result of an Exec step of a ReadOp.
+type ReadOpExecStep struct {
+	// Output of the object class method.
+	Output []byte
+	// Err is the result of this step.
+	Err error
+
+	cOut    **C.char
+	cOutLen *C.size_t
+	cRval   *C.int
+}
+
+func (s *ReadOpExecStep) update() {
+	s.Err = getError(*s.cRval)
+	if s.Err == nil && *s.cOut != nil {
+		s.Output = C.GoBytes(unsafe.Pointer(*s.cOut), C.int(*s.cOutLen))
+	}
+}
+
+func (s *ReadOpExecStep) free() {
+	if *s.cOut != nil {
+		C.rados_buffer_free(*s.cOut)
+		*s.cOut = nil
+	}
+}
+
+// Exec adds a step that calls the read-only method of the object class cls
+// on the object, passing it the input data in.
+//
+// Implements:
+//  void rados_read_op_exec(rados_read_op_t read_op,
+//                          const char *cls,
+//                          const char *method,
+//                          const char *in_buf,
+//                          size_t in_len,
+//                          char **out_buf,
+//                          size_t *out_len,
+//                          int *prval)
+func (r *ReadOp) Exec(cls, method string, in []byte) *ReadOpExecStep {
+	s := &ReadOpExecStep{
+		cOut:    (**C.char)(r.cAlloc(cPtrSize)),
+		cOutLen: (*C.size_t)(r.cAlloc(cSizeSize)),
+		cRval:   r.cInt(),
+	}
+	r.addStep(s)
+	C.rados_read_op_exec(
+		r.op,
+		r.cString(cls),
+		r.cString(method),
+		r.cBytes(in),
+		C.size_t(len(in)),
+		s.cOut,
+		s.cOutLen,
+		s.cRval)
+	return s
+}
+
+// iterateOmap calls fn for each key-value pair of an omap iterator.
+func iterateOmap(iter C.rados_omap_iter_t, fn func(key string, value []byte)) error {
+	for {
+		var cKey, cVal *C.char
+		var cLen C.size_t
+		ret := C.rados_omap_get_next(iter, &cKey, &cVal, &cLen)
+		if ret != 0 {
+			return getError(ret)
+		}
+		if cKey == nil {
+			return nil
+		}
+		fn(C.GoString(cKey), C.GoBytes(unsafe.Pointer(cVal), C.int(cLen)))
+	}
+}
@@ -0,0 +1,112 @@
+package rados
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (suite *RadosTestSuite) TestReadOpInvalidIOContext() {
+	op := CreateReadOp()
+	defer op.Release()
+	op.AssertExists()
+	err := op.Operate(&IOContext{}, "foo", OperationNoFlag)
+	assert.Equal(suite.T(), ErrInvalidIOContext, err)
+}
+
+func (suite *RadosTestSuite) TestReadOpSteps() {
+	suite.SetupConnection()
+	oid := suite.GenObjectName()
+
+	wop := CreateWriteOp()
+	defer wop.Release()
+	wop.Create(CreateExclusive)
+	wop.WriteFull([]byte("consistent data"))
+	wop.SetXattr("a", []byte("1"))
+	wop.SetXattr("b", []byte("2"))
+	wop.SetOmap(map[string][]byte{
+		"key1": []byte("val1"),
+		"key2": []byte("val2"),
+		"key3": []byte("val3"),
+		"zzz":  []byte("last"),
+	})
+	err := wop.Operate(suite.ioctx, oid, OperationNoFlag)
+	require.NoError(suite.T(), err)
+
+	op := CreateReadOp()
+	defer op.Release()
+	op.AssertExists()
+	statStep := op.Stat()
+	buf := make([]byte, 4)
+	readStep := op.Read(11, buf)
+	xattrsStep := op.GetXattrs()
+	keysStep := op.GetOmapKeys("", 2)
+	valsStep := op.GetOmapValues("", "key", 10)
+	byKeysStep := op.GetOmapValuesByKeys([]string{"key2", "zzz", "nope"})
+	err = op.Operate(suite.ioctx, oid, OperationNoFlag)
+	require.NoError(suite.T(), err)
+
+	assert.NoError(suite.T(), statStep.Err)
+	assert.EqualValues(suite.T(), 15, statStep.Size)
+	assert.False(suite.T(), statStep.ModTime.IsZero())
+
+	assert.NoError(suite.T(), readStep.Err)
+	assert.EqualValues(suite.T(), 4, readStep.BytesRead)
+	assert.Equal(suite.T(), "data", string(buf))
+
+	assert.NoError(suite.T(), xattrsStep.Err)
+	assert.Equal(suite.T(),
+		map[string][]byte{"a": []byte("1"), "b": []byte("2")},
+		xattrsStep.Xattrs)
+
+	assert.NoError(suite.T(), keysStep.Err)
+	assert.Equal(suite.T(), []string{"key1", "key2"}, keysStep.Keys)
+	assert.True(suite.T(), keysStep.More)
+
+	assert.NoError(suite.T(), valsStep.Err)
+	assert.Len(suite.T(), valsStep.Pairs, 3)
+	assert.Equal(suite.T(), []byte("val3"), valsStep.Pairs["key3"])
+	assert.False(suite.T(), valsStep.More)
+
+	assert.NoError(suite.T(), byKeysStep.Err)
+	assert.Equal(suite.T(),
+		map[string][]byte{"key2": []byte("val2"), "zzz": []byte("last")},
+		byKeysStep.Pairs)
+}
+
+func (suite *RadosTestSuite) TestReadOpErrors() {
+	suite.SetupConnection()
+	oid := suite.GenObjectName()
+
+	suite.T().Run("missingObject", func(t *testing.T) {
+		op := CreateReadOp()
+		defer op.Release()
+		statStep := op.Stat()
+		err := op.Operate(suite.ioctx, oid, OperationNoFlag)
+		assert.Equal(t, ErrNotFound, err)
+		assert.Error(t, statStep.Err)
+	})
+
+	err := suite.ioctx.WriteFull(oid, []byte("content"))
+	require.NoError(suite.T(), err)
+	err = suite.ioctx.SetXattr(oid, "state", []byte("ready"))
+	require.NoError(suite.T(), err)
+
+	suite.T().Run("cmpXattr", func(t *testing.T) {
+		op := CreateReadOp()
+		defer op.Release()
+		op.CmpXattr("state", CompareEqual, []byte("busy"))
+		op.Stat()
+		err := op.Operate(suite.ioctx, oid, OperationNoFlag)
+		assert.Error(t, err)
+	})
+
+	suite.T().Run("releaseWithoutOperate", func(t *testing.T) {
+		op := CreateReadOp()
+		op.GetXattrs()
+		op.GetOmapKeys("", 10)
+		op.GetOmapValuesByKeys([]string{"x"})
+		op.Release()
+	})
+}
@@ -0,0 +1,110 @@
+package rados
+
+// #cgo LDFLAGS: -lrados
+// #include <stdlib.h>
+// #include <rados/librados.h>
+import "C"
+
+import (
+	"sort"
+	"unsafe"
+)
+
+// CreateSelfManagedSnap allocates a new self-managed snapshot ID in the pool
+// of the I/O context. Unlike pool snapshots, self-managed snapshots only
+// apply to writes made with a write snapshot context that includes them.
+//
+// Listing the clones of an object is not supported: librados only provides
+// the list_snaps operation through its C++ API, there is no C function for
+// it. The content of an object at a given snapshot can still be read by
+// selecting the snapshot with SetReadSnap, a missing clone is reported as
+// ErrNotFound.
+//
+// Implements:
+//  int rados_ioctx_selfmanaged_snap_create(rados_ioctx_t io,
+//                                          rados_snap_t *snapid);
+func (ioctx *IOContext) CreateSelfManagedSnap() (SnapID, error) {
+	var snapID SnapID
+
+	if err := ioctx.validate(); err != nil {
+		return snapID, err
+	}
+
+	ret := C.rados_ioctx_selfmanaged_snap_create(
+		ioctx.ioctx,
+		(*C.rados_snap_t)(&snapID))
+	return snapID, getError(ret)
+}
+
+// RemoveSelfManagedSnap releases the self-managed snapshot ID. The clones
+// that belong only to this snapshot are trimmed by the OSDs in the
+// background.
+//
+// Implements:
+//  int rados_ioctx_selfmanaged_snap_remove(rados_ioctx_t io,
+//                                          rados_snap_t snapid);
+func (ioctx *IOContext) RemoveSelfManagedSnap(snapID SnapID) error {
+	if err := ioctx.validate(); err != nil {
+		return err
+	}
+
+	ret := C.rados_ioctx_selfmanaged_snap_remove(
+		ioctx.ioctx,
+		(C.rados_snap_t)(snapID))
+	return getError(ret)
+}
+
+// RollbackSelfManagedSnap rollbacks the object with key oid to the
+// self-managed snapshot. The write snapshot context of the I/O context
+// applies to the rollback as it does to any other write.
+//
+// Implements:
+//  int rados_ioctx_selfmanaged_snap_rollback(rados_ioctx_t io,
+//                                            const char *oid,
+//                                            rados_snap_t snapid);
+func (ioctx *IOContext) RollbackSelfManagedSnap(oid string, snapID SnapID) error {
+	if err := ioctx.validate(); err != nil {
+		return err
+	}
+
+	coid := C.CString(oid)
+	defer C.free(unsafe.Pointer(coid))
+
+	ret := C.rados_ioctx_selfmanaged_snap_rollback(
+		ioctx.ioctx,
+		coid,
+		(C.rados_snap_t)(snapID))
+	return getError(ret)
+}
+
+// SetSelfManagedSnapWriteContext sets the snapshot context used for the
+// writes made through the I/O context. seq is the most recent snapshot ID
+// allocated and snaps are the IDs of the snapshots that still exist. The
+// IDs are passed to librados in descending order as it requires, so snaps
+// may be given in any order.
+//
+// Implements:
+//  int rados_ioctx_selfmanaged_snap_set_write_ctx(rados_ioctx_t io,
+//                                                 rados_snap_t seq,
+//                                                 rados_snap_t *snaps,
+//                                                 int num_snaps);
+func (ioctx *IOContext) SetSelfManagedSnapWriteContext(seq SnapID, snaps []SnapID) error {
+	if err := ioctx.validate(); err != nil {
+		return err
+	}
+
+	sorted := make([]SnapID, len(snaps))
+	copy(sorted, snaps)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] > sorted[j] })
+
+	var cSnaps *C.rados_snap_t
+	if len(sorted) > 0 {
+		cSnaps = (*C.rados_snap_t)(unsafe.Pointer(&sorted[0]))
+	}
+	ret := C.rados_ioctx_selfmanaged_snap_set_write_ctx(
+		ioctx.ioctx,
+		(C.rados_snap_t)(seq),
+		cSnaps,
+		C.int(len(sorted)))
+	return getError(ret)
+}
@@ -0,0 +1,69 @@
+package rados
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (suite *RadosTestSuite) TestSelfManagedSnapInvalidIOContext() {
+	ioctx := &IOContext{}
+	_, err := ioctx.CreateSelfManagedSnap()
+	assert.Equal(suite.T(), ErrInvalidIOContext, err)
+	err = ioctx.RemoveSelfManagedSnap(1)
+	assert.Equal(suite.T(), ErrInvalidIOContext, err)
+	err = ioctx.RollbackSelfManagedSnap("foo", 1)
+	assert.Equal(suite.T(), ErrInvalidIOContext, err)
+	err = ioctx.SetSelfManagedSnapWriteContext(1, nil)
+	assert.Equal(suite.T(), ErrInvalidIOContext, err)
+}
+
+func (suite *RadosTestSuite) TestSelfManagedSnap() {
+	suite.SetupConnection()
+
+	// a pool can not mix pool and self-managed snapshots, so use a fresh
+	// pool rather than the one shared with the pool snapshot tests
+	pool := uuid.Must(uuid.NewV4()).String()
+	require.NoError(suite.T(), suite.conn.MakePool(pool))
+	defer suite.conn.DeletePool(pool)
+	ioctx, err := suite.conn.OpenIOContext(pool)
+	require.NoError(suite.T(), err)
+	defer ioctx.Destroy()
+
+	oid := suite.GenObjectName()
+	err = ioctx.WriteFull(oid, []byte("version 1"))
+	require.NoError(suite.T(), err)
+
+	snap1, err := ioctx.CreateSelfManagedSnap()
+	require.NoError(suite.T(), err)
+	err = ioctx.SetSelfManagedSnapWriteContext(snap1, []SnapID{snap1})
+	require.NoError(suite.T(), err)
+	err = ioctx.WriteFull(oid, []byte("version 2"))
+	require.NoError(suite.T(), err)
+
+	snap2, err := ioctx.CreateSelfManagedSnap()
+	require.NoError(suite.T(), err)
+	assert.True(suite.T(), snap2 > snap1)
+	err = ioctx.SetSelfManagedSnapWriteContext(snap2, []SnapID{snap1, snap2})
+	require.NoError(suite.T(), err)
+	err = ioctx.WriteFull(oid, []byte("version 3"))
+	require.NoError(suite.T(), err)
+
+	buf := make([]byte, 16)
+	assert.NoError(suite.T(), ioctx.SetReadSnap(snap1))
+	n, err := ioctx.Read(oid, buf, 0)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "version 1", string(buf[:n]))
+	assert.NoError(suite.T(), ioctx.SetReadSnap(SnapHead))
+
+	err = ioctx.RollbackSelfManagedSnap(oid, snap2)
+	assert.NoError(suite.T(), err)
+	n, err = ioctx.Read(oid, buf, 0)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "version 2", string(buf[:n]))
+
+	assert.NoError(suite.T(), ioctx.RemoveSelfManagedSnap(snap1))
+	assert.NoError(suite.T(), ioctx.RemoveSelfManagedSnap(snap2))
+	err = ioctx.SetSelfManagedSnapWriteContext(snap2, nil)
+	assert.NoError(suite.T(), err)
+}
@@ -0,0 +1,418 @@
+package rados
+
+/*
+#cgo LDFLAGS: -lrados
+#include <stdlib.h>
+#include <rados/librados.h>
+
+extern void watchNotifyCallback(uintptr_t, uint64_t, uint64_t, uint64_t,
+	void *, size_t);
+extern void watchErrorCallback(uintptr_t, uint64_t, int);
+
+// inline wrapper to cast uintptr_t to void*
+static inline int wrap_rados_watch2(rados_ioctx_t io, const char *o,
+	uint64_t *cookie, uintptr_t arg) {
+		return rados_watch2(io, o, cookie,
+			(rados_watchcb2_t)watchNotifyCallback,
+			(rados_watcherrcb_t)watchErrorCallback, (void*)arg);
+};
+*/
+import "C"
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/ceph/go-ceph/internal/callbacks"
+)
+
+// watchCallbacks tracks the active watchers.
+var watchCallbacks = callbacks.New()
+
+// NotifyEvent is received by a Watcher when a notification is sent to the
+// watched object. Each event must be acknowledged with Ack, otherwise the
+// notifier waits until its timeout expires.
+type NotifyEvent struct {
+	// ID of the notification.
+	ID uint64
+	// WatcherID is the ID (cookie) of the watch that received the
+	// notification.
+	WatcherID uint64
+	// NotifierID is the instance ID of the client that sent the
+	// notification.
+	NotifierID uint64
+	// Data is the payload of the notification.
+	Data []byte
+
+	watcher *Watcher
+}
+
+// NotifyAck is a reply to a notification sent with Notify.
+type NotifyAck struct {
+	// WatcherID is the ID (cookie) of the watch that replied.
+	WatcherID uint64
+	// NotifierID is the instance ID of the client that replied.
+	NotifierID uint64
+	// Response is the payload passed to Ack by the watcher.
+	Response []byte
+}
+
+// NotifyTimeout identifies a watcher that did not reply to a notification
+// sent with Notify before the timeout expired.
+type NotifyTimeout struct {
+	// WatcherID is the ID (cookie) of the watch that timed out.
+	WatcherID uint64
+	// NotifierID is the instance ID of the client that timed out.
+	NotifierID uint64
+}
+
+// Watcher receives notifications sent to an object. Notifications are
+// delivered over the Events channel and watch errors, such as a
+// disconnection from the OSD, over the Errors channel, in the order they
+// were received. Both channels must be consumed, otherwise the delivery of
+// the following notifications of the watcher is blocked. Notifications that
+// were not delivered yet are queued in memory, the librados callbacks never
+// wait for them to be read.
+type Watcher struct {
+	id      C.uint64_t
+	oid     string
+	ioctx   *IOContext
+	cbIndex uintptr
+	events  chan NotifyEvent
+	errors  chan error
+	done    chan struct{}
+	stopped chan struct{}
+	once    sync.Once
+
+	// mu protects the queue of events and errors not delivered yet,
+	// wake signals the delivery goroutine that the queue is not empty.
+	mu    sync.Mutex
+	queue []interface{}
+	wake  chan struct{}
+}
+
+// Watch starts watching the object with key oid for notifications.
+//
+// Implements:
+//  int rados_watch2(rados_ioctx_t io, const char *o, uint64_t *cookie,
+//                   rados_watchcb2_t watchcb,
+//                   rados_watcherrcb_t watcherrcb,
+//                   void *arg);
+func (ioctx *IOContext) Watch(oid string) (*Watcher, error) {
+	if err := ioctx.validate(); err != nil {
+		return nil, err
+	}
+
+	w := &Watcher{
+		oid:     oid,
+		ioctx:   ioctx,
+		events:  make(chan NotifyEvent),
+		errors:  make(chan error),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+		wake:    make(chan struct{}, 1),
+	}
+	w.cbIndex = watchCallbacks.Add(w)
+
+	cOid := C.CString(oid)
+	defer C.free(unsafe.Pointer(cOid))
+
+	ret := C.wrap_rados_watch2(
+		ioctx.ioctx,
+		cOid,
+		&w.id,
+		C.uintptr_t(w.cbIndex))
+	if ret != 0 {
+		watchCallbacks.Remove(w.cbIndex)
+		return nil, getError(ret)
+	}
+	go w.deliver()
+	return w, nil
+}
+
+// push queues an event or error for delivery. It is called by the librados
+// callbacks and does not block.
+func (w *Watcher) push(v interface{}) {
+	w.mu.Lock()
+	w.queue = append(w.queue, v)
+	w.mu.Unlock()
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// deliver sends the queued events and errors over the Events and Errors
+// channels until the watch is stopped.
+func (w *Watcher) deliver() {
+	defer close(w.stopped)
+	for {
+		w.mu.Lock()
+		q := w.queue
+		w.queue = nil
+		w.mu.Unlock()
+		for _, v := range q {
+			switch v := v.(type) {
+			case NotifyEvent:
+				select {
+				case w.events <- v:
+				case <-w.done:
+					return
+				}
+			case error:
+				select {
+				case w.errors <- v:
+				case <-w.done:
+					return
+				}
+			}
+		}
+		select {
+		case <-w.wake:
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// ID returns the ID (cookie) of the watch.
+func (w *Watcher) ID() uint64 {
+	return uint64(w.id)
+}
+
+// Events returns the channel over which notifications are delivered.
+func (w *Watcher) Events() <-chan NotifyEvent {
+	return w.events
+}
+
+// Errors returns the channel over which watch errors are delivered. After
+// an error the watch may no longer be registered with the OSD; Check can be
+// used to find out whether it is still valid.
+func (w *Watcher) Errors() <-chan error {
+	return w.errors
+}
+
+// Check returns the time since the watch was last confirmed by the OSD, or
+// an error if the watch is no longer valid.
+//
+// Implements:
+//  int rados_watch_check(rados_ioctx_t io, uint64_t cookie);
+func (w *Watcher) Check() (time.Duration, error) {
+	if err := w.ioctx.validate(); err != nil {
+		return 0, err
+	}
+	ret := C.rados_watch_check(w.ioctx.ioctx, w.id)
+	if ret < 0 {
+		return 0, getError(ret)
+	}
+	return time.Duration(ret) * time.Millisecond, nil
+}
+
+// Unwatch stops the watch. No events are delivered once Unwatch has
+// returned and the Events and Errors channels are closed, ending any range
+// loop over them. Queued events that were not read are dropped. Calling
+// Unwatch again has no effect.
+//
+// Implements:
+//  int rados_unwatch2(rados_ioctx_t io, uint64_t cookie);
+//  int rados_watch_flush(rados_t cluster);
+func (w *Watcher) Unwatch() error {
+	if err := w.ioctx.validate(); err != nil {
+		return err
+	}
+	var err error
+	w.once.Do(func() {
+		err = w.unwatch()
+	})
+	return err
+}
+
+func (w *Watcher) unwatch() error {
+	// stop the delivery goroutine, the only sender on the channels
+	close(w.done)
+	<-w.stopped
+	ret := C.rados_unwatch2(w.ioctx.ioctx, w.id)
+	watchCallbacks.Remove(w.cbIndex)
+	// wait for the callbacks that may still be running. They only queue
+	// their event, so this does not depend on any watcher being read.
+	fret := C.rados_watch_flush(C.rados_ioctx_get_cluster(w.ioctx.ioctx))
+	close(w.events)
+	close(w.errors)
+	if ret != 0 {
+		return getError(ret)
+	}
+	return getError(fret)
+}
+
+// Ack acknowledges the notification, passing response back to the
+// notifier.
+//
+// Implements:
+//  int rados_notify_ack(rados_ioctx_t io, const char *o,
+//                       uint64_t notify_id, uint64_t cookie,
+//                       const char *buf, int buf_len);
+func (e *NotifyEvent) Ack(response []byte) error {
+	if e.watcher == nil {
+		return ErrInvalidIOContext
+	}
+	ioctx := e.watcher.ioctx
+	if err := ioctx.validate(); err != nil {
+		return err
+	}
+
+	cOid := C.CString(e.watcher.oid)
+	defer C.free(unsafe.Pointer(cOid))
+
+	var buf *C.char
+	if len(response) > 0 {
+		buf = (*C.char)(unsafe.Pointer(&response[0]))
+	}
+	ret := C.rados_notify_ack(
+		ioctx.ioctx,
+		cOid,
+		C.uint64_t(e.ID),
+		C.uint64_t(e.WatcherID),
+		buf,
+		C.int(len(response)))
+	return getError(ret)
+}
+
+// Notify sends data to all the watchers of the object with key oid and
+// waits for them to reply or for the timeout to expire. The replies of the
+// watchers that acknowledged the notification are returned along with the
+// watchers that timed out. If any watcher timed out an error is returned as
+// well.
+//
+// Implements:
+//  int rados_notify2(rados_ioctx_t io, const char *o, const char *buf,
+//                    int buf_len, uint64_t timeout_ms,
+//                    char **reply_buffer, size_t *reply_buffer_len);
+func (ioctx *IOContext) Notify(oid string, data []byte, timeout time.Duration) ([]NotifyAck, []NotifyTimeout, error) {
+	if err := ioctx.validate(); err != nil {
+		return nil, nil, err
+	}
+
+	cOid := C.CString(oid)
+	defer C.free(unsafe.Pointer(cOid))
+
+	var buf *C.char
+	if len(data) > 0 {
+		buf = (*C.char)(unsafe.Pointer(&data[0]))
+	}
+	var (
+		reply    *C.char
+		replyLen C.size_t
+	)
+	ret := C.rados_notify2(
+		ioctx.ioctx,
+		cOid,
+		buf,
+		C.int(len(data)),
+		C.uint64_t(timeout/time.Millisecond),
+		&reply,
+		&replyLen)
+	if reply == nil {
+		return nil, nil, getError(ret)
+	}
+	defer C.rados_buffer_free(reply)
+
+	acks, timeouts, err := decodeNotifyResponse(
+		C.GoBytes(unsafe.Pointer(reply), C.int(replyLen)))
+	if err == nil {
+		err = getError(ret)
+	}
+	return acks, timeouts, err
+}
+
+// WatchFlush blocks until all the pending watch and notify callbacks of
+// the connection have been delivered.
+//
+// Implements:
+//  int rados_watch_flush(rados_t cluster);
+func (c *Conn) WatchFlush() error {
+	if err := c.ensure_connected(); err != nil {
+		return err
+	}
+	return getError(C.rados_watch_flush(c.cluster))
+}
+
+var errNotifyResponse = errors.New("invalid notify response")
+
+// decodeNotifyResponse decodes the reply buffer returned by rados_notify2.
+// It contains a map of (notifier id, cookie) pairs to acknowledgement
+// payloads followed by a set of (notifier id, cookie) pairs that timed out.
+func decodeNotifyResponse(b []byte) ([]NotifyAck, []NotifyTimeout, error) {
+	le := binary.LittleEndian
+	next := func(n int) ([]byte, bool) {
+		if len(b) < n {
+			return nil, false
+		}
+		v := b[:n]
+		b = b[n:]
+		return v, true
+	}
+
+	v, ok := next(4)
+	if !ok {
+		return nil, nil, errNotifyResponse
+	}
+	acks := make([]NotifyAck, le.Uint32(v))
+	for i := range acks {
+		v, ok = next(20)
+		if !ok {
+			return nil, nil, errNotifyResponse
+		}
+		acks[i].NotifierID = le.Uint64(v[0:8])
+		acks[i].WatcherID = le.Uint64(v[8:16])
+		if acks[i].Response, ok = next(int(le.Uint32(v[16:20]))); !ok {
+			return nil, nil, errNotifyResponse
+		}
+	}
+
+	v, ok = next(4)
+	if !ok {
+		return nil, nil, errNotifyResponse
+	}
+	timeouts := make([]NotifyTimeout, le.Uint32(v))
+	for i := range timeouts {
+		v, ok = next(16)
+		if !ok {
+			return nil, nil, errNotifyResponse
+		}
+		timeouts[i].NotifierID = le.Uint64(v[0:8])
+		timeouts[i].WatcherID = le.Uint64(v[8:16])
+	}
+	return acks, timeouts, nil
+}
+
+//export watchNotifyCallback
+func watchNotifyCallback(index uintptr, notifyID, cookie, notifierID C.uint64_t,
+	data unsafe.Pointer, dataLen C.size_t) {
+	v := watchCallbacks.Lookup(index)
+	if v == nil {
+		return
+	}
+	w := v.(*Watcher)
+	e := NotifyEvent{
+		ID:         uint64(notifyID),
+		WatcherID:  uint64(cookie),
+		NotifierID: uint64(notifierID),
+		watcher:    w,
+	}
+	if dataLen > 0 {
+		e.Data = C.GoBytes(data, C.int(dataLen))
+	}
+	w.push(e)
+}
+
+//export watchErrorCallback
+func watchErrorCallback(index uintptr, cookie C.uint64_t, err C.int) {
+	v := watchCallbacks.Lookup(index)
+	if v == nil {
+		return
+	}
+	v.(*Watcher).push(getError(err))
+}
@@ -0,0 +1,223 @@
+package rados
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (suite *RadosTestSuite) TestWatchNotify() {
+	suite.SetupConnection()
+	oid := suite.GenObjectName()
+	err := suite.ioctx.Create(oid, CreateExclusive)
+	require.NoError(suite.T(), err)
+
+	w, err := suite.ioctx.Watch(oid)
+	require.NoError(suite.T(), err)
+	assert.NotZero(suite.T(), w.ID())
+
+	_, err = w.Check()
+	assert.NoError(suite.T(), err)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for e := range w.Events() {
+			assert.Equal(suite.T(), w.ID(), e.WatcherID)
+			assert.NoError(suite.T(), e.Ack(append([]byte("re:"), e.Data...)))
+		}
+	}()
+
+	acks, timeouts, err := suite.ioctx.Notify(oid, []byte("ping"), 10*time.Second)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), timeouts, 0)
+	if assert.Len(suite.T(), acks, 1) {
+		assert.Equal(suite.T(), w.ID(), acks[0].WatcherID)
+		assert.Equal(suite.T(), []byte("re:ping"), acks[0].Response)
+	}
+
+	err = w.Unwatch()
+	assert.NoError(suite.T(), err)
+	// the events channel is closed by Unwatch, ending the loop
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		suite.T().Error("events channel not closed after Unwatch")
+	}
+	_, ok := <-w.Errors()
+	assert.False(suite.T(), ok)
+	assert.NoError(suite.T(), w.Unwatch())
+
+	acks, timeouts, err = suite.ioctx.Notify(oid, []byte("ping"), time.Second)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), acks, 0)
+	assert.Len(suite.T(), timeouts, 0)
+}
+
+func (suite *RadosTestSuite) TestWatchNotifyTimeout() {
+	suite.SetupConnection()
+	oid := suite.GenObjectName()
+	err := suite.ioctx.Create(oid, CreateExclusive)
+	require.NoError(suite.T(), err)
+
+	w, err := suite.ioctx.Watch(oid)
+	require.NoError(suite.T(), err)
+	defer func() { assert.NoError(suite.T(), w.Unwatch()) }()
+
+	// consume the event without acknowledging it
+	go func() {
+		for range w.Events() {
+		}
+	}()
+
+	_, timeouts, err := suite.ioctx.Notify(oid, nil, time.Second)
+	assert.Error(suite.T(), err)
+	if assert.Len(suite.T(), timeouts, 1) {
+		assert.Equal(suite.T(), w.ID(), timeouts[0].WatcherID)
+	}
+}
+
+func (suite *RadosTestSuite) TestUnwatchUnreadEvent() {
+	suite.SetupConnection()
+	oid := suite.GenObjectName()
+	err := suite.ioctx.Create(oid, CreateExclusive)
+	require.NoError(suite.T(), err)
+
+	w, err := suite.ioctx.Watch(oid)
+	require.NoError(suite.T(), err)
+
+	// nobody reads the event, it stays queued
+	_, timeouts, err := suite.ioctx.Notify(oid, nil, time.Second)
+	assert.Error(suite.T(), err)
+	assert.Len(suite.T(), timeouts, 1)
+
+	ch := make(chan error)
+	go func() {
+		ch <- w.Unwatch()
+	}()
+	select {
+	case err = <-ch:
+		assert.NoError(suite.T(), err)
+	case <-time.After(10 * time.Second):
+		suite.T().Fatal("Unwatch blocked by an unread event")
+	}
+	for range w.Events() {
+	}
+}
+
+func (suite *RadosTestSuite) TestUnwatchOtherUnreadEvent() {
+	suite.SetupConnection()
+	oid1 := suite.GenObjectName()
+	oid2 := suite.GenObjectName()
+	require.NoError(suite.T(), suite.ioctx.Create(oid1, CreateExclusive))
+	require.NoError(suite.T(), suite.ioctx.Create(oid2, CreateExclusive))
+
+	w1, err := suite.ioctx.Watch(oid1)
+	require.NoError(suite.T(), err)
+	defer func() { assert.NoError(suite.T(), w1.Unwatch()) }()
+	w2, err := suite.ioctx.Watch(oid2)
+	require.NoError(suite.T(), err)
+
+	// the event of the first watcher is never read, it must not hold up
+	// the unwatch of the second one
+	_, _, err = suite.ioctx.Notify(oid1, nil, time.Second)
+	assert.Error(suite.T(), err)
+
+	ch := make(chan error)
+	go func() {
+		ch <- w2.Unwatch()
+	}()
+	select {
+	case err = <-ch:
+		assert.NoError(suite.T(), err)
+	case <-time.After(10 * time.Second):
+		suite.T().Fatal("Unwatch blocked by an unread event of another watcher")
+	}
+}
+
+func (suite *RadosTestSuite) TestWatchInvalid() {
+	suite.SetupConnection()
+
+	_, err := (&IOContext{}).Watch("foo")
+	assert.Equal(suite.T(), ErrInvalidIOContext, err)
+
+	_, err = suite.ioctx.Watch(suite.GenObjectName())
+	assert.Equal(suite.T(), ErrNotFound, err)
+
+	err = (&NotifyEvent{}).Ack(nil)
+	assert.Equal(suite.T(), ErrInvalidIOContext, err)
+}
+
+func TestDecodeNotifyResponse(t *testing.T) {
+	le := binary.LittleEndian
+	u32 := func(v uint32) []byte {
+		b := make([]byte, 4)
+		le.PutUint32(b, v)
+		return b
+	}
+	u64 := func(v uint64) []byte {
+		b := make([]byte, 8)
+		le.PutUint64(b, v)
+		return b
+	}
+	var b []byte
+	b = append(b, u32(2)...)
+	b = append(b, u64(4100)...)
+	b = append(b, u64(1)...)
+	b = append(b, u32(5)...)
+	b = append(b, []byte("hello")...)
+	b = append(b, u64(4101)...)
+	b = append(b, u64(2)...)
+	b = append(b, u32(0)...)
+	b = append(b, u32(1)...)
+	b = append(b, u64(4102)...)
+	b = append(b, u64(3)...)
+
+	acks, timeouts, err := decodeNotifyResponse(b)
+	assert.NoError(t, err)
+	assert.Equal(t, []NotifyAck{
+		{WatcherID: 1, NotifierID: 4100, Response: []byte("hello")},
+		{WatcherID: 2, NotifierID: 4101, Response: []byte{}},
+	}, acks)
+	assert.Equal(t, []NotifyTimeout{
+		{WatcherID: 3, NotifierID: 4102},
+	}, timeouts)
+
+	for i := 0; i < len(b); i++ {
+		_, _, err = decodeNotifyResponse(b[:i])
+		assert.Error(t, err)
+	}
+}
+
+func TestWatcherDelivery(t *testing.T) {
+	w := &Watcher{
+		events:  make(chan NotifyEvent),
+		errors:  make(chan error),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+		wake:    make(chan struct{}, 1),
+	}
+	go w.deliver()
+
+	// pushing never blocks, even with nobody reading
+	for i := uint64(1); i <= 3; i++ {
+		w.push(NotifyEvent{ID: i})
+	}
+	w.push(ErrNotFound)
+	for i := uint64(1); i <= 3; i++ {
+		e := <-w.events
+		assert.Equal(t, i, e.ID)
+	}
+	assert.Equal(t, ErrNotFound, <-w.errors)
+
+	w.push(NotifyEvent{ID: 4})
+	close(w.done)
+	select {
+	case <-w.stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("delivery not stopped")
+	}
+}
@@ -0,0 +1,314 @@
+package rados
+
+// #cgo LDFLAGS: -lrados
+// #include <stdlib.h>
+// #include <rados/librados.h>
+//
+import "C"
+
+import (
+	"unsafe"
+)
+
+// WriteOp manages a set of discrete actions that will be performed together
+// atomically on a single object. Steps are staged by calling the methods of
+// the WriteOp and are only sent to the cluster when Operate is called. Either
+// all the steps are applied or none of them are.
+type WriteOp struct {
+	operation
+	op C.rados_write_op_t
+}
+
+// CreateWriteOp returns a newly constructed write operation. The WriteOp
+// must be released by calling Release when it is no longer needed.
+//
+// Implements:
+//  rados_write_op_t rados_create_write_op(void)
+func CreateWriteOp() *WriteOp {
+	return &WriteOp{
+		op: C.rados_create_write_op(),
+	}
+}
+
+// Release the resources associated with this write operation.
+//
+// Implements:
+//  void rados_release_write_op(rados_write_op_t write_op)
+func (w *WriteOp) Release() {
+	if w.op != nil {
+		C.rados_release_write_op(w.op)
+		w.op = nil
+	}
+	w.freeMem()
+}
+
+// Operate performs all the steps of the write operation on the object with
+// key oid. The flags apply to the operation as a whole.
+//
+// Implements:
+//  int rados_write_op_operate(rados_write_op_t write_op,
+//                             rados_ioctx_t io,
+//                             const char *oid,
+//                             time_t *mtime,
+//                             int flags)
+func (w *WriteOp) Operate(ioctx *IOContext, oid string, flags OperationFlags) error {
+	if err := ioctx.validate(); err != nil {
+		return err
+	}
+
+	cOid := C.CString(oid)
+	defer C.free(unsafe.Pointer(cOid))
+
+	ret := C.rados_write_op_operate(w.op, ioctx.ioctx, cOid, nil, C.int(flags))
+	w.updateSteps()
+	return getError(ret)
+}
+
+// SetOpFlags sets the flags of the most recently added step of the write
+// operation.
+//
+// Implements:
+//  void rados_write_op_set_flags(rados_write_op_t write_op, int flags)
+func (w *WriteOp) SetOpFlags(flags OpFlags) {
+	C.rados_write_op_set_flags(w.op, C.int(flags))
+}
+
+// AssertExists ensures the object exists before the rest of the write
+// operation is applied. If the object does not exist Operate fails with
+// ErrNotFound.
+//
+// Implements:
+//  void rados_write_op_assert_exists(rados_write_op_t write_op)
+func (w *WriteOp) AssertExists() {
+	C.rados_write_op_assert_exists(w.op)
+}
+
+// AssertVersion ensures the object's version is equal to v before the rest
+// of the write operation is applied.
+//
+// Implements:
+//  void rados_write_op_assert_version(rados_write_op_t write_op, uint64_t ver)
+func (w *WriteOp) AssertVersion(v uint64) {
+	C.rados_write_op_assert_version(w.op, C.uint64_t(v))
+}
+
+// CmpXattr ensures that the comparison between the value of the xattr
+// named name and value is satisfied before the rest of the write operation
+// is applied.
+//
+// Implements:
+//  void rados_write_op_cmpxattr(rados_write_op_t write_op,
+//                               const char *name,
+//                               uint8_t comparison_operator,
+//                               const char *value,
+//                               size_t value_len)
+func (w *WriteOp) CmpXattr(name string, op ComparisonOp, value []byte) {
+	C.rados_write_op_cmpxattr(
+		w.op,
+		w.cString(name),
+		C.uint8_t(op),
+		w.cBytes(value),
+		C.size_t(len(value)))
+}
+
+// Create a new object as part of the write operation.
+//
+// Implements:
+//  void rados_write_op_create(rados_write_op_t write_op, int exclusive,
+//                             const char* category)
+func (w *WriteOp) Create(exclusive CreateOption) {
+	C.rados_write_op_create(w.op, C.int(exclusive), nil)
+}
+
+// Write the bytes of b to the object starting at byte offset offset.
+//
+// Implements:
+//  void rados_write_op_write(rados_write_op_t write_op,
+//                            const char *buffer,
+//                            size_t len,
+//                            uint64_t offset)
+func (w *WriteOp) Write(b []byte, offset uint64) {
+	C.rados_write_op_write(
+		w.op,
+		w.cBytes(b),
+		C.size_t(len(b)),
+		C.uint64_t(offset))
+}
+
+// WriteFull replaces the content of the object with the bytes of b.
+//
+// Implements:
+//  void rados_write_op_write_full(rados_write_op_t write_op,
+//                                 const char *buffer,
+//                                 size_t len)
+func (w *WriteOp) WriteFull(b []byte) {
+	C.rados_write_op_write_full(w.op, w.cBytes(b), C.size_t(len(b)))
+}
+
+// WriteSame writes the bytes of b repeatedly to the object until writeLen
+// bytes have been written, starting at byte offset offset.
+//
+// Implements:
+//  void rados_write_op_writesame(rados_write_op_t write_op,
+//                                const char *buffer,
+//                                size_t data_len,
+//                                size_t write_len,
+//                                uint64_t offset)
+func (w *WriteOp) WriteSame(b []byte, writeLen, offset uint64) {
+	C.rados_write_op_writesame(
+		w.op,
+		w.cBytes(b),
+		C.size_t(len(b)),
+		C.size_t(writeLen),
+		C.uint64_t(offset))
+}
+
+// Append the bytes of b to the end of the object.
+//
+// Implements:
+//  void rados_write_op_append(rados_write_op_t write_op,
+//                             const char *buffer,
+//                             size_t len)
+func (w *WriteOp) Append(b []byte) {
+	C.rados_write_op_append(w.op, w.cBytes(b), C.size_t(len(b)))
+}
+
+// Truncate resizes the object to size bytes.
+//
+// Implements:
+//  void rados_write_op_truncate(rados_write_op_t write_op, uint64_t offset)
+func (w *WriteOp) Truncate(size uint64) {
+	C.rados_write_op_truncate(w.op, C.uint64_t(size))
+}
+
+// Zero the length bytes of the object starting at byte offset offset.
+//
+// Implements:
+//  void rados_write_op_zero(rados_write_op_t write_op,
+//                           uint64_t offset,
+//                           uint64_t len)
+func (w *WriteOp) Zero(offset, length uint64) {
+	C.rados_write_op_zero(w.op, C.uint64_t(offset), C.uint64_t(length))
+}
+
+// Remove the object.
+//
+// Implements:
+//  void rados_write_op_remove(rados_write_op_t write_op)
+func (w *WriteOp) Remove() {
+	C.rados_write_op_remove(w.op)
+}
+
+// SetXattr sets the xattr named name on the object to value.
+//
+// Implements:
+//  void rados_write_op_setxattr(rados_write_op_t write_op,
+//                               const char *name,
+//                               const char *value,
+//                               size_t value_len)
+func (w *WriteOp) SetXattr(name string, value []byte) {
+	C.rados_write_op_setxattr(
+		w.op,
+		w.cString(name),
+		w.cBytes(value),
+		C.size_t(len(value)))
+}
+
+// RmXattr removes the xattr named name from the object.
+//
+// Implements:
+//  void rados_write_op_rmxattr(rados_write_op_t write_op, const char *name)
+func (w *WriteOp) RmXattr(name string) {
+	C.rados_write_op_rmxattr(w.op, w.cString(name))
+}
+
+// SetOmap sets the key-value pairs in the omap of the object.
+//
+// Implements:
+//  void rados_write_op_omap_set(rados_write_op_t write_op,
+//                               char const* const* keys,
+//                               char const* const* vals,
+//                               const size_t *lens,
+//                               size_t num)
+func (w *WriteOp) SetOmap(pairs map[string][]byte) {
+	num := uintptr(len(pairs))
+	cKeys := w.cAlloc(num * cPtrSize)
+	cValues := w.cAlloc(num * cPtrSize)
+	cLengths := w.cAlloc(num * cSizeSize)
+
+	i := uintptr(0)
+	for key, value := range pairs {
+		*(**C.char)(unsafe.Pointer(uintptr(cKeys) + i*cPtrSize)) = w.cString(key)
+		*(**C.char)(unsafe.Pointer(uintptr(cValues) + i*cPtrSize)) = w.cBytes(value)
+		*(*C.size_t)(unsafe.Pointer(uintptr(cLengths) + i*cSizeSize)) = C.size_t(len(value))
+		i++
+	}
+
+	C.rados_write_op_omap_set(
+		w.op,
+		(**C.char)(cKeys),
+		(**C.char)(cValues),
+		(*C.size_t)(cLengths),
+		C.size_t(num))
+}
+
+// RmOmapKeys removes the specified keys from the omap of the object.
+//
+// Implements:
+//  void rados_write_op_omap_rm_keys(rados_write_op_t write_op,
+//                                   char const* const* keys,
+//                                   size_t keys_len)
+func (w *WriteOp) RmOmapKeys(keys []string) {
+	C.rados_write_op_omap_rm_keys(
+		w.op,
+		w.cStringArray(keys),
+		C.size_t(len(keys)))
+}
+
+// CleanOmap removes all the keys from the omap of the object.
+//
+// Implements:
+//  void rados_write_op_omap_clear(rados_write_op_t write_op)
+func (w *WriteOp) CleanOmap() {
+	C.rados_write_op_omap_clear(w.op)
+}
+
+// WriteOpExecStep holds the result of an Exec step of a WriteOp.
+type WriteOpExecStep struct {
+	// Err is the result of this step.
+	Err error
+
+	cRval *C.int
+}
+
+func (s *WriteOpExecStep) update() {
+	s.Err = getError(*s.cRval)
+}
+
+func (*WriteOpExecStep) free() {}
+
+// Exec adds a step that calls the method of the object class cls on the
+// object, passing it the input data in. The output of the method is
+// discarded.
+//
+// Implements:
+//  void rados_write_op_exec(rados_write_op_t write_op,
+//                           const char *cls,
+//                           const char *method,
+//                           const char *in_buf,
+//                           size_t in_len,
+//                           int *prval)
+func (w *WriteOp) Exec(cls, method string, in []byte) *WriteOpExecStep {
+	s := &WriteOpExecStep{
+		cRval: w.cInt(),
+	}
+	w.addStep(s)
+	C.rados_write_op_exec(
+		w.op,
+		w.cString(cls),
+		w.cString(method),
+		w.cBytes(in),
+		C.size_t(len(in)),
+		s.cRval)
+	return s
+}
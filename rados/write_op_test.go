@@ -0,0 +1,164 @@
+package rados
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (suite *RadosTestSuite) TestWriteOpCreate() {
+	suite.SetupConnection()
+	oid := suite.GenObjectName()
+
+	op := CreateWriteOp()
+	defer op.Release()
+	op.Create(CreateExclusive)
+	err := op.Operate(suite.ioctx, oid, OperationNoFlag)
+	assert.NoError(suite.T(), err)
+
+	op2 := CreateWriteOp()
+	defer op2.Release()
+	op2.Create(CreateExclusive)
+	err = op2.Operate(suite.ioctx, oid, OperationNoFlag)
+	assert.Equal(suite.T(), ErrObjectExists, err)
+}
+
+func (suite *RadosTestSuite) TestWriteOpInvalidIOContext() {
+	op := CreateWriteOp()
+	defer op.Release()
+	op.Create(CreateIdempotent)
+	err := op.Operate(&IOContext{}, "foo", OperationNoFlag)
+	assert.Equal(suite.T(), ErrInvalidIOContext, err)
+}
+
+func (suite *RadosTestSuite) TestWriteOpWriteAndOmap() {
+	suite.SetupConnection()
+	oid := suite.GenObjectName()
+
+	op := CreateWriteOp()
+	defer op.Release()
+	op.Create(CreateExclusive)
+	op.WriteFull([]byte("hello"))
+	op.Append([]byte(" world"))
+	op.SetXattr("color", []byte("blue"))
+	op.SetOmap(map[string][]byte{
+		"index.1": []byte("a"),
+		"index.2": []byte("b"),
+		"index.3": []byte(""),
+	})
+	err := op.Operate(suite.ioctx, oid, OperationNoFlag)
+	require.NoError(suite.T(), err)
+
+	data := make([]byte, 64)
+	n, err := suite.ioctx.Read(oid, data, 0)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "hello world", string(data[:n]))
+
+	xattr := make([]byte, 16)
+	n, err = suite.ioctx.GetXattr(oid, "color", xattr)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "blue", string(xattr[:n]))
+
+	omap, err := suite.ioctx.GetAllOmapValues(oid, "", "", 10)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), omap, 3)
+	assert.Equal(suite.T(), []byte("b"), omap["index.2"])
+
+	op2 := CreateWriteOp()
+	defer op2.Release()
+	op2.AssertExists()
+	op2.Write([]byte("J"), 6)
+	op2.Truncate(7)
+	op2.RmXattr("color")
+	op2.RmOmapKeys([]string{"index.1", "index.3"})
+	err = op2.Operate(suite.ioctx, oid, OperationNoFlag)
+	require.NoError(suite.T(), err)
+
+	n, err = suite.ioctx.Read(oid, data, 0)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "hello J", string(data[:n]))
+
+	_, err = suite.ioctx.GetXattr(oid, "color", xattr)
+	assert.Error(suite.T(), err)
+
+	omap, err = suite.ioctx.GetAllOmapValues(oid, "", "", 10)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), omap, 1)
+	assert.Contains(suite.T(), omap, "index.2")
+}
+
+func (suite *RadosTestSuite) TestWriteOpAtomicity() {
+	suite.SetupConnection()
+	oid := suite.GenObjectName()
+
+	err := suite.ioctx.WriteFull(oid, []byte("original"))
+	require.NoError(suite.T(), err)
+
+	suite.T().Run("assertVersionFails", func(t *testing.T) {
+		op := CreateWriteOp()
+		defer op.Release()
+		op.AssertVersion(9999)
+		op.WriteFull([]byte("replaced"))
+		op.SetOmap(map[string][]byte{"k": []byte("v")})
+		err := op.Operate(suite.ioctx, oid, OperationNoFlag)
+		assert.Error(t, err)
+
+		data := make([]byte, 64)
+		n, err := suite.ioctx.Read(oid, data, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, "original", string(data[:n]))
+		omap, err := suite.ioctx.GetOmapValues(oid, "", "", 10)
+		assert.NoError(t, err)
+		assert.Len(t, omap, 0)
+	})
+
+	suite.T().Run("cmpXattr", func(t *testing.T) {
+		err := suite.ioctx.SetXattr(oid, "state", []byte("ready"))
+		require.NoError(t, err)
+
+		op := CreateWriteOp()
+		defer op.Release()
+		op.CmpXattr("state", CompareEqual, []byte("busy"))
+		op.WriteFull([]byte("replaced"))
+		err = op.Operate(suite.ioctx, oid, OperationNoFlag)
+		assert.Error(t, err)
+
+		op2 := CreateWriteOp()
+		defer op2.Release()
+		op2.CmpXattr("state", CompareEqual, []byte("ready"))
+		op2.WriteFull([]byte("replaced"))
+		err = op2.Operate(suite.ioctx, oid, OperationNoFlag)
+		assert.NoError(t, err)
+	})
+
+	suite.T().Run("assertExists", func(t *testing.T) {
+		op := CreateWriteOp()
+		defer op.Release()
+		op.AssertExists()
+		op.Write([]byte("data"), 0)
+		err := op.Operate(suite.ioctx, oid+"_missing", OperationNoFlag)
+		assert.Equal(t, ErrNotFound, err)
+	})
+
+	suite.T().Run("opFlags", func(t *testing.T) {
+		op := CreateWriteOp()
+		defer op.Release()
+		op.RmXattr("not-there")
+		op.SetOpFlags(OpFlagFailOk)
+		op.SetXattr("other", []byte("x"))
+		err := op.Operate(suite.ioctx, oid, OperationNoFlag)
+		assert.NoError(t, err)
+	})
+
+	suite.T().Run("remove", func(t *testing.T) {
+		op := CreateWriteOp()
+		defer op.Release()
+		op.Remove()
+		err := op.Operate(suite.ioctx, oid, OperationNoFlag)
+		assert.NoError(t, err)
+
+		_, err = suite.ioctx.Stat(oid)
+		assert.Equal(t, ErrNotFound, err)
+	})
+}
@@ -0,0 +1,321 @@
+package rbd
+
+/*
+#cgo LDFLAGS: -lrbd
+#include <stdlib.h>
+#include <rbd/librbd.h>
+
+extern void rbdAioCompleteCallback(rbd_completion_t, uintptr_t);
+
+// inline wrapper to cast uintptr_t to void*
+static inline int wrap_rbd_aio_create_completion(uintptr_t arg,
+	rbd_completion_t *c) {
+		return rbd_aio_create_completion((void*)arg,
+			(rbd_callback_t)rbdAioCompleteCallback, c);
+};
+*/
+import "C"
+
+import (
+	"sync"
+	"unsafe"
+
+	"github.com/ceph/go-ceph/internal/callbacks"
+	"github.com/ceph/go-ceph/rados"
+)
+
+// aioCallbacks tracks the asynchronous operations that have not yet
+// completed.
+var aioCallbacks = callbacks.New()
+
+// AioCompletion tracks an asynchronous I/O operation started by one of the
+// Aio functions of Image. Completion is signalled by librbd through a
+// callback, so neither waiting on the Done channel nor registering a
+// function with OnComplete blocks an OS thread per operation.
+// Release must be called once the completion is no longer needed.
+type AioCompletion struct {
+	c       C.rbd_completion_t
+	cbIndex uintptr
+	done    chan struct{}
+	ret     C.ssize_t
+
+	lock       sync.Mutex
+	onComplete func(*AioCompletion)
+
+	// the buffers passed to librbd must stay valid until the operation
+	// completes, so the data is staged in C memory. For reads the data is
+	// copied into buf once the operation completes.
+	buf         []byte
+	cBuf        unsafe.Pointer
+	cMem        []unsafe.Pointer
+	mismatchOff *C.uint64_t
+}
+
+// newAioCompletion creates a completion that is registered for the librbd
+// completion callback.
+//
+// Implements:
+//  int rbd_aio_create_completion(void *cb_arg,
+//                                rbd_callback_t complete_cb,
+//                                rbd_completion_t *c);
+func newAioCompletion() (*AioCompletion, error) {
+	ac := &AioCompletion{
+		done: make(chan struct{}),
+	}
+	ac.cbIndex = aioCallbacks.Add(ac)
+	ret := C.wrap_rbd_aio_create_completion(C.uintptr_t(ac.cbIndex), &ac.c)
+	if ret != 0 {
+		aioCallbacks.Remove(ac.cbIndex)
+		return nil, getError(ret)
+	}
+	return ac, nil
+}
+
+// cBytes returns a copy of b in C memory that is freed on Release.
+func (ac *AioCompletion) cBytes(b []byte) *C.char {
+	if len(b) == 0 {
+		return nil
+	}
+	p := C.CBytes(b)
+	ac.cMem = append(ac.cMem, p)
+	return (*C.char)(p)
+}
+
+// started checks the return code of the function that started the
+// asynchronous operation, cleaning up the completion if it failed to start.
+func (ac *AioCompletion) started(ret C.int) (*AioCompletion, error) {
+	if ret < 0 {
+		aioCallbacks.Remove(ac.cbIndex)
+		C.rbd_aio_release(ac.c)
+		ac.c = nil
+		ac.freeMem()
+		return nil, getError(ret)
+	}
+	return ac, nil
+}
+
+func (ac *AioCompletion) freeMem() {
+	if ac.cBuf != nil {
+		C.free(ac.cBuf)
+		ac.cBuf = nil
+	}
+	for _, p := range ac.cMem {
+		C.free(p)
+	}
+	ac.cMem = nil
+	ac.mismatchOff = nil
+}
+
+// Done returns a channel that is closed when the asynchronous operation has
+// completed.
+func (ac *AioCompletion) Done() <-chan struct{} {
+	return ac.done
+}
+
+// IsComplete returns true if the asynchronous operation has completed.
+func (ac *AioCompletion) IsComplete() bool {
+	select {
+	case <-ac.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// OnComplete registers fn to be called once the asynchronous operation has
+// completed. If the operation has already completed fn is called right
+// away. Otherwise fn is called from a librbd thread and must not block.
+// Only one function can be registered, a later call replaces it.
+func (ac *AioCompletion) OnComplete(fn func(*AioCompletion)) {
+	ac.lock.Lock()
+	if !ac.IsComplete() {
+		ac.onComplete = fn
+		ac.lock.Unlock()
+		return
+	}
+	ac.lock.Unlock()
+	fn(ac)
+}
+
+// Wait blocks until the asynchronous operation has completed and returns
+// its error, if any.
+func (ac *AioCompletion) Wait() error {
+	<-ac.done
+	if ac.ret < 0 {
+		return getError(C.int(ac.ret))
+	}
+	return nil
+}
+
+// ReturnValue blocks until the asynchronous operation has completed and
+// returns its return value. For reads this is the number of bytes read. A
+// negative value is an error code.
+func (ac *AioCompletion) ReturnValue() int64 {
+	<-ac.done
+	return int64(ac.ret)
+}
+
+// MismatchOffset blocks until the asynchronous operation has completed and
+// returns the offset of the first byte that did not match for an
+// AioCompareAndWrite operation that failed the comparison.
+func (ac *AioCompletion) MismatchOffset() uint64 {
+	<-ac.done
+	if ac.mismatchOff == nil {
+		return 0
+	}
+	return uint64(*ac.mismatchOff)
+}
+
+// Release the resources associated with the completion. Release waits for
+// the asynchronous operation to complete before releasing it.
+//
+// Implements:
+//  void rbd_aio_release(rbd_completion_t c);
+func (ac *AioCompletion) Release() {
+	<-ac.done
+	if ac.c != nil {
+		C.rbd_aio_release(ac.c)
+		ac.c = nil
+	}
+	ac.freeMem()
+}
+
+//export rbdAioCompleteCallback
+func rbdAioCompleteCallback(c C.rbd_completion_t, index uintptr) {
+	ac := aioCallbacks.Lookup(index).(*AioCompletion)
+	aioCallbacks.Remove(index)
+	ac.ret = C.rbd_aio_get_return_value(c)
+	if ac.ret > 0 && ac.cBuf != nil {
+		copy(ac.buf, C.GoBytes(ac.cBuf, C.int(ac.ret)))
+	}
+
+	ac.lock.Lock()
+	close(ac.done)
+	fn := ac.onComplete
+	ac.lock.Unlock()
+	if fn != nil {
+		fn(ac)
+	}
+}
+
+// AioRead asynchronously reads len(data) bytes from the image starting at
+// byte offset off into data. data must not be accessed until the operation
+// has completed.
+//
+// Implements:
+//  int rbd_aio_read(rbd_image_t image, uint64_t off, size_t len,
+//                   char *buf, rbd_completion_t c);
+func (image *Image) AioRead(data []byte, off uint64) (*AioCompletion, error) {
+	if err := image.validate(imageIsOpen); err != nil {
+		return nil, err
+	}
+	ac, err := newAioCompletion()
+	if err != nil {
+		return nil, err
+	}
+
+	ac.buf = data
+	if len(data) > 0 {
+		ac.cBuf = C.malloc(C.size_t(len(data)))
+	}
+	ret := C.rbd_aio_read(
+		image.image,
+		C.uint64_t(off),
+		C.size_t(len(data)),
+		(*C.char)(ac.cBuf),
+		ac.c)
+	return ac.started(ret)
+}
+
+// AioWrite asynchronously writes data to the image starting at byte offset
+// off.
+//
+// Implements:
+//  int rbd_aio_write(rbd_image_t image, uint64_t off, size_t len,
+//                    const char *buf, rbd_completion_t c);
+func (image *Image) AioWrite(data []byte, off uint64) (*AioCompletion, error) {
+	if err := image.validate(imageIsOpen); err != nil {
+		return nil, err
+	}
+	ac, err := newAioCompletion()
+	if err != nil {
+		return nil, err
+	}
+
+	ret := C.rbd_aio_write(
+		image.image,
+		C.uint64_t(off),
+		C.size_t(len(data)),
+		ac.cBytes(data),
+		ac.c)
+	return ac.started(ret)
+}
+
+// AioDiscard asynchronously discards length bytes of the image starting at
+// byte offset off.
+//
+// Implements:
+//  int rbd_aio_discard(rbd_image_t image, uint64_t off, uint64_t len,
+//                      rbd_completion_t c);
+func (image *Image) AioDiscard(off, length uint64) (*AioCompletion, error) {
+	if err := image.validate(imageIsOpen); err != nil {
+		return nil, err
+	}
+	ac, err := newAioCompletion()
+	if err != nil {
+		return nil, err
+	}
+
+	ret := C.rbd_aio_discard(
+		image.image,
+		C.uint64_t(off),
+		C.uint64_t(length),
+		ac.c)
+	return ac.started(ret)
+}
+
+// AioWriteSame asynchronously writes data repeatedly to the image until n
+// bytes have been written, starting at byte offset ofs. n must be a
+// multiple of len(data).
+//
+// Implements:
+//  int rbd_aio_writesame(rbd_image_t image, uint64_t off, size_t len,
+//                        const char *buf, size_t data_len,
+//                        rbd_completion_t c, int op_flags);
+func (image *Image) AioWriteSame(ofs, n uint64, data []byte, flags rados.OpFlags) (*AioCompletion, error) {
+	if err := image.validate(imageIsOpen); err != nil {
+		return nil, err
+	}
+	ac, err := newAioCompletion()
+	if err != nil {
+		return nil, err
+	}
+
+	ret := C.rbd_aio_writesame(
+		image.image,
+		C.uint64_t(ofs),
+		C.size_t(n),
+		ac.cBytes(data),
+		C.size_t(len(data)),
+		ac.c,
+		C.int(flags))
+	return ac.started(ret)
+}
+
+// AioFlush asynchronously flushes all the cached writes of the image to
+// storage.
+//
+// Implements:
+//  int rbd_aio_flush(rbd_image_t image, rbd_completion_t c);
+func (image *Image) AioFlush() (*AioCompletion, error) {
+	if err := image.validate(imageIsOpen); err != nil {
+		return nil, err
+	}
+	ac, err := newAioCompletion()
+	if err != nil {
+		return nil, err
+	}
+
+	ret := C.rbd_aio_flush(image.image, ac.c)
+	return ac.started(ret)
+}
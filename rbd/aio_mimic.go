@@ -0,0 +1,56 @@
+// +build !luminous
+//
+// Ceph Mimic introduced rbd_aio_compare_and_write().
+
+package rbd
+
+// #cgo LDFLAGS: -lrbd
+// #include <errno.h>
+// #include <stdlib.h>
+// #include <rbd/librbd.h>
+import "C"
+
+import (
+	"unsafe"
+
+	"github.com/ceph/go-ceph/rados"
+)
+
+// AioCompareAndWrite asynchronously compares the data of the image starting
+// at byte offset off with cmp and, if they are equal, writes data in their
+// place. cmp and data must have the same length. If the comparison fails the
+// operation fails and MismatchOffset returns the offset of the first byte
+// that differs.
+//
+// Implements:
+//  ssize_t rbd_aio_compare_and_write(rbd_image_t image, uint64_t off,
+//                                    size_t len, const char *cmp_buf,
+//                                    const char *buf, rbd_completion_t c,
+//                                    uint64_t *mismatch_off, int op_flags);
+func (image *Image) AioCompareAndWrite(off uint64, cmp, data []byte, flags rados.OpFlags) (*AioCompletion, error) {
+	if err := image.validate(imageIsOpen); err != nil {
+		return nil, err
+	}
+	if len(cmp) != len(data) {
+		return nil, getError(-C.EINVAL)
+	}
+	ac, err := newAioCompletion()
+	if err != nil {
+		return nil, err
+	}
+
+	mismatchOff := C.calloc(1, C.size_t(unsafe.Sizeof(C.uint64_t(0))))
+	ac.cMem = append(ac.cMem, mismatchOff)
+	ac.mismatchOff = (*C.uint64_t)(mismatchOff)
+
+	ret := C.rbd_aio_compare_and_write(
+		image.image,
+		C.uint64_t(off),
+		C.size_t(len(data)),
+		ac.cBytes(cmp),
+		ac.cBytes(data),
+		ac.c,
+		ac.mismatchOff,
+		C.int(flags))
+	return ac.started(C.int(ret))
+}
@@ -0,0 +1,59 @@
+// +build !luminous
+
+package rbd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAioCompareAndWrite(t *testing.T) {
+	conn := radosConnect(t)
+	poolName := GetUUID()
+	err := conn.MakePool(poolName)
+	require.NoError(t, err)
+
+	ioctx, err := conn.OpenIOContext(poolName)
+	require.NoError(t, err)
+
+	defer func() {
+		ioctx.Destroy()
+		conn.DeletePool(poolName)
+		conn.Shutdown()
+	}()
+
+	name := GetUUID()
+	options := NewRbdImageOptions()
+	defer options.Destroy()
+	err = CreateImage(ioctx, name, testImageSize, options)
+	require.NoError(t, err)
+	defer RemoveImage(ioctx, name)
+
+	img, err := OpenImage(ioctx, name, NoSnapshot)
+	require.NoError(t, err)
+	defer img.Close()
+
+	_, err = img.WriteAt([]byte("0123456789"), 0)
+	require.NoError(t, err)
+
+	_, err = img.AioCompareAndWrite(0, []byte("0123"), []byte("ab"), 0)
+	assert.Error(t, err)
+
+	ac, err := img.AioCompareAndWrite(0, []byte("0123"), []byte("abcd"), 0)
+	require.NoError(t, err)
+	assert.NoError(t, ac.Wait())
+	ac.Release()
+
+	ac, err = img.AioCompareAndWrite(0, []byte("abXd"), []byte("wxyz"), 0)
+	require.NoError(t, err)
+	assert.Error(t, ac.Wait())
+	assert.Equal(t, uint64(2), ac.MismatchOffset())
+	ac.Release()
+
+	buf := make([]byte, 10)
+	_, err = img.ReadAt(buf, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "abcd456789", string(buf))
+}
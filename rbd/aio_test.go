@@ -0,0 +1,141 @@
+package rbd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAioImageNotOpen(t *testing.T) {
+	image := &Image{}
+	_, err := image.AioRead(make([]byte, 8), 0)
+	assert.Equal(t, ErrImageNotOpen, err)
+	_, err = image.AioWrite([]byte("data"), 0)
+	assert.Equal(t, ErrImageNotOpen, err)
+	_, err = image.AioDiscard(0, 8)
+	assert.Equal(t, ErrImageNotOpen, err)
+	_, err = image.AioWriteSame(0, 8, []byte("data"), 0)
+	assert.Equal(t, ErrImageNotOpen, err)
+	_, err = image.AioFlush()
+	assert.Equal(t, ErrImageNotOpen, err)
+}
+
+func TestAioReadWrite(t *testing.T) {
+	conn := radosConnect(t)
+	poolName := GetUUID()
+	err := conn.MakePool(poolName)
+	require.NoError(t, err)
+
+	ioctx, err := conn.OpenIOContext(poolName)
+	require.NoError(t, err)
+
+	defer func() {
+		ioctx.Destroy()
+		conn.DeletePool(poolName)
+		conn.Shutdown()
+	}()
+
+	name := GetUUID()
+	options := NewRbdImageOptions()
+	defer options.Destroy()
+	err = CreateImage(ioctx, name, testImageSize, options)
+	require.NoError(t, err)
+	defer RemoveImage(ioctx, name)
+
+	img, err := OpenImage(ioctx, name, NoSnapshot)
+	require.NoError(t, err)
+	defer img.Close()
+
+	t.Run("writeRead", func(t *testing.T) {
+		ac, err := img.AioWrite([]byte("hello async world"), 0)
+		require.NoError(t, err)
+		assert.NoError(t, ac.Wait())
+		assert.True(t, ac.IsComplete())
+		ac.Release()
+
+		ac, err = img.AioFlush()
+		require.NoError(t, err)
+		select {
+		case <-ac.Done():
+		case <-time.After(10 * time.Second):
+			t.Fatal("timed out waiting for flush")
+		}
+		assert.NoError(t, ac.Wait())
+		ac.Release()
+
+		buf := make([]byte, 17)
+		ac, err = img.AioRead(buf, 0)
+		require.NoError(t, err)
+		assert.NoError(t, ac.Wait())
+		assert.Equal(t, int64(17), ac.ReturnValue())
+		ac.Release()
+		assert.Equal(t, "hello async world", string(buf))
+	})
+
+	t.Run("callback", func(t *testing.T) {
+		results := make(chan int64, 1)
+		ac, err := img.AioWrite([]byte("callback"), 4096)
+		require.NoError(t, err)
+		ac.OnComplete(func(ac *AioCompletion) {
+			results <- ac.ReturnValue()
+		})
+		select {
+		case ret := <-results:
+			assert.True(t, ret >= 0)
+		case <-time.After(10 * time.Second):
+			t.Fatal("timed out waiting for callback")
+		}
+		ac.Release()
+
+		// registering after completion calls the function right away
+		called := false
+		ac.OnComplete(func(*AioCompletion) { called = true })
+		assert.True(t, called)
+	})
+
+	t.Run("writeSameDiscard", func(t *testing.T) {
+		ac, err := img.AioWriteSame(8192, 64, []byte("abcd"), 0)
+		require.NoError(t, err)
+		assert.NoError(t, ac.Wait())
+		ac.Release()
+
+		buf := make([]byte, 64)
+		ac, err = img.AioRead(buf, 8192)
+		require.NoError(t, err)
+		assert.NoError(t, ac.Wait())
+		ac.Release()
+		assert.Equal(t, bytes.Repeat([]byte("abcd"), 16), buf)
+
+		ac, err = img.AioDiscard(8192, 64)
+		require.NoError(t, err)
+		assert.NoError(t, ac.Wait())
+		ac.Release()
+
+		ac, err = img.AioRead(buf, 8192)
+		require.NoError(t, err)
+		assert.NoError(t, ac.Wait())
+		ac.Release()
+		assert.Equal(t, make([]byte, 64), buf)
+	})
+
+	t.Run("manyInFlight", func(t *testing.T) {
+		completions := make([]*AioCompletion, 64)
+		for i := range completions {
+			ac, err := img.AioWrite(bytes.Repeat([]byte{byte(i)}, 512), uint64(i*512))
+			require.NoError(t, err)
+			completions[i] = ac
+		}
+		for _, ac := range completions {
+			assert.NoError(t, ac.Wait())
+			ac.Release()
+		}
+
+		buf := make([]byte, 512)
+		_, err := img.ReadAt(buf, 63*512)
+		assert.NoError(t, err)
+		assert.Equal(t, bytes.Repeat([]byte{63}, 512), buf)
+	})
+}
@@ -75,4 +75,5 @@ var (
 
 const (
 	errRange = rbdError(-C.ERANGE)
+	errExist = rbdError(-C.EEXIST)
 )
@@ -0,0 +1,511 @@
+package rbd
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+
+	"github.com/ceph/go-ceph/rados"
+)
+
+// The stream format used by ExportDiff and ImportDiff is the one written and
+// read by the "rbd export-diff" and "rbd import-diff" commands. A stream
+// starts with a header line followed by a sequence of records, each starting
+// with a single tag byte. Version 2 streams additionally carry the length of
+// the record payload after the tag. All integers are little endian.
+const (
+	diffHeaderV1 = "rbd diff v1\n"
+	diffHeaderV2 = "rbd diff v2\n"
+
+	diffTagFromSnap = 'f'
+	diffTagToSnap   = 't'
+	diffTagSize     = 's'
+	diffTagWrite    = 'w'
+	diffTagZero     = 'z'
+	diffTagEnd      = 'e'
+
+	// exportChunkSize is the largest amount of data read from or written to
+	// an image at once while exporting or importing.
+	exportChunkSize = 4 * 1024 * 1024
+)
+
+// ErrInvalidDiff may be returned by ImportDiff if the stream is not in the
+// rbd diff format.
+var ErrInvalidDiff = errors.New("invalid rbd diff stream")
+
+// diffWriter encodes the records of a version 1 rbd diff stream.
+type diffWriter struct {
+	w   io.Writer
+	buf [17]byte
+}
+
+func (dw *diffWriter) header() error {
+	_, err := io.WriteString(dw.w, diffHeaderV1)
+	return err
+}
+
+func (dw *diffWriter) snap(tag byte, name string) error {
+	dw.buf[0] = tag
+	binary.LittleEndian.PutUint32(dw.buf[1:], uint32(len(name)))
+	if _, err := dw.w.Write(dw.buf[:5]); err != nil {
+		return err
+	}
+	_, err := io.WriteString(dw.w, name)
+	return err
+}
+
+func (dw *diffWriter) size(size uint64) error {
+	dw.buf[0] = diffTagSize
+	binary.LittleEndian.PutUint64(dw.buf[1:], size)
+	_, err := dw.w.Write(dw.buf[:9])
+	return err
+}
+
+// extent writes the start of a write or zero record. The data of a write
+// record must be written to dw.w right after.
+func (dw *diffWriter) extent(tag byte, offset, length uint64) error {
+	dw.buf[0] = tag
+	binary.LittleEndian.PutUint64(dw.buf[1:], offset)
+	binary.LittleEndian.PutUint64(dw.buf[9:], length)
+	_, err := dw.w.Write(dw.buf[:17])
+	return err
+}
+
+func (dw *diffWriter) end() error {
+	dw.buf[0] = diffTagEnd
+	_, err := dw.w.Write(dw.buf[:1])
+	return err
+}
+
+// diffRecord is a single decoded record of an rbd diff stream. For write
+// records data must be consumed before reading the next record.
+type diffRecord struct {
+	tag    byte
+	name   string
+	size   uint64
+	offset uint64
+	length uint64
+	data   io.Reader
+}
+
+// diffReader decodes version 1 and version 2 rbd diff streams.
+type diffReader struct {
+	r  *bufio.Reader
+	v2 bool
+}
+
+func newDiffReader(r io.Reader) (*diffReader, error) {
+	dr := &diffReader{r: bufio.NewReader(r)}
+	hdr := make([]byte, len(diffHeaderV1))
+	if _, err := io.ReadFull(dr.r, hdr); err != nil {
+		return nil, dr.unexpected(err)
+	}
+	switch string(hdr) {
+	case diffHeaderV1:
+	case diffHeaderV2:
+		dr.v2 = true
+	default:
+		return nil, ErrInvalidDiff
+	}
+	return dr, nil
+}
+
+// unexpected converts a premature end of the stream into ErrInvalidDiff.
+func (*diffReader) unexpected(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return ErrInvalidDiff
+	}
+	return err
+}
+
+func (dr *diffReader) uint32() (uint32, error) {
+	var b [4]byte
+	if _, err := io.ReadFull(dr.r, b[:]); err != nil {
+		return 0, dr.unexpected(err)
+	}
+	return binary.LittleEndian.Uint32(b[:]), nil
+}
+
+func (dr *diffReader) uint64() (uint64, error) {
+	var b [8]byte
+	if _, err := io.ReadFull(dr.r, b[:]); err != nil {
+		return 0, dr.unexpected(err)
+	}
+	return binary.LittleEndian.Uint64(b[:]), nil
+}
+
+func (dr *diffReader) next() (diffRecord, error) {
+	var rec diffRecord
+	tag, err := dr.r.ReadByte()
+	if err != nil {
+		return rec, dr.unexpected(err)
+	}
+	rec.tag = tag
+	if tag == diffTagEnd {
+		return rec, nil
+	}
+
+	var recLen uint64
+	if dr.v2 {
+		if recLen, err = dr.uint64(); err != nil {
+			return rec, err
+		}
+	}
+	switch tag {
+	case diffTagFromSnap, diffTagToSnap:
+		n, err := dr.uint32()
+		if err != nil {
+			return rec, err
+		}
+		name := make([]byte, n)
+		if _, err = io.ReadFull(dr.r, name); err != nil {
+			return rec, dr.unexpected(err)
+		}
+		rec.name = string(name)
+	case diffTagSize:
+		if rec.size, err = dr.uint64(); err != nil {
+			return rec, err
+		}
+	case diffTagWrite, diffTagZero:
+		if rec.offset, err = dr.uint64(); err != nil {
+			return rec, err
+		}
+		if rec.length, err = dr.uint64(); err != nil {
+			return rec, err
+		}
+		if tag == diffTagWrite {
+			rec.data = io.LimitReader(dr.r, int64(rec.length))
+		}
+	default:
+		if !dr.v2 {
+			return rec, ErrInvalidDiff
+		}
+		// version 2 streams allow unknown records to be skipped
+		if _, err = io.CopyN(ioutil.Discard, dr.r, int64(recLen)); err != nil {
+			return rec, dr.unexpected(err)
+		}
+	}
+	return rec, nil
+}
+
+// ExportDiff writes the changes made to the image between the snapshots
+// fromSnap and toSnap to w, in the format of the "rbd export-diff" command.
+// If fromSnap is NoSnapshot all the data of the image is exported. If toSnap
+// is NoSnapshot the changes up to the current state of the image are
+// exported.
+func (image *Image) ExportDiff(fromSnap, toSnap string, w io.Writer) error {
+	if err := image.validate(imageNeedsIOContext | imageNeedsName); err != nil {
+		return err
+	}
+
+	src, err := OpenImageReadOnly(image.ioctx, image.name, toSnap)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	size, err := src.GetSize()
+	if err != nil {
+		return err
+	}
+
+	dw := &diffWriter{w: w}
+	if err = dw.header(); err != nil {
+		return err
+	}
+	if fromSnap != NoSnapshot {
+		if err = dw.snap(diffTagFromSnap, fromSnap); err != nil {
+			return err
+		}
+	}
+	if toSnap != NoSnapshot {
+		if err = dw.snap(diffTagToSnap, toSnap); err != nil {
+			return err
+		}
+	}
+	if err = dw.size(size); err != nil {
+		return err
+	}
+
+	var cbErr error
+	buf := make([]byte, exportChunkSize)
+	err = src.DiffIterate(DiffIterateConfig{
+		SnapName:      fromSnap,
+		Offset:        0,
+		Length:        size,
+		IncludeParent: IncludeParent,
+		WholeObject:   DisableWholeObject,
+		Callback: func(offset, length uint64, exists int, _ interface{}) int {
+			if exists == 0 {
+				cbErr = dw.extent(diffTagZero, offset, length)
+			} else {
+				cbErr = exportExtent(src, dw, buf, offset, length)
+			}
+			if cbErr != nil {
+				return -1
+			}
+			return 0
+		},
+	})
+	if cbErr != nil {
+		return cbErr
+	}
+	if err != nil {
+		return err
+	}
+	return dw.end()
+}
+
+// exportExtent writes a write record holding the data of the given extent of
+// the image.
+func exportExtent(src *Image, dw *diffWriter, buf []byte, offset, length uint64) error {
+	if err := dw.extent(diffTagWrite, offset, length); err != nil {
+		return err
+	}
+	for length > 0 {
+		n := length
+		if n > uint64(len(buf)) {
+			n = uint64(len(buf))
+		}
+		if _, err := src.ReadAt(buf[:n], int64(offset)); err != nil {
+			return err
+		}
+		if _, err := dw.w.Write(buf[:n]); err != nil {
+			return err
+		}
+		offset += n
+		length -= n
+	}
+	return nil
+}
+
+// ImportDiff applies a stream in the format of the "rbd export-diff" command
+// to the image. Both version 1 and version 2 streams are supported. If the
+// stream starts from a snapshot, that snapshot must exist on the image,
+// otherwise ErrNotFound is returned. If the stream ends at a snapshot, that
+// snapshot must not exist yet and is created once all the changes have been
+// applied.
+func (image *Image) ImportDiff(r io.Reader) error {
+	if err := image.validate(imageIsOpen); err != nil {
+		return err
+	}
+
+	dr, err := newDiffReader(r)
+	if err != nil {
+		return err
+	}
+
+	toSnap := NoSnapshot
+	buf := make([]byte, exportChunkSize)
+	for {
+		rec, err := dr.next()
+		if err != nil {
+			return err
+		}
+		switch rec.tag {
+		case diffTagFromSnap:
+			found, err := image.hasSnapshot(rec.name)
+			if err != nil {
+				return err
+			}
+			if !found {
+				return ErrNotFound
+			}
+		case diffTagToSnap:
+			found, err := image.hasSnapshot(rec.name)
+			if err != nil {
+				return err
+			}
+			if found {
+				return errExist
+			}
+			toSnap = rec.name
+		case diffTagSize:
+			size, err := image.GetSize()
+			if err != nil {
+				return err
+			}
+			if size != rec.size {
+				if err = image.Resize(rec.size); err != nil {
+					return err
+				}
+			}
+		case diffTagWrite:
+			if err = importExtent(image, rec, buf); err != nil {
+				return err
+			}
+		case diffTagZero:
+			if err = image.writeZeroes(rec.offset, rec.length); err != nil {
+				return err
+			}
+		case diffTagEnd:
+			if err = image.Flush(); err != nil {
+				return err
+			}
+			if toSnap != NoSnapshot {
+				_, err = image.CreateSnapshot(toSnap)
+			}
+			return err
+		}
+	}
+}
+
+// importExtent writes the data of a write record to the image.
+func importExtent(image *Image, rec diffRecord, buf []byte) error {
+	offset, length := rec.offset, rec.length
+	for length > 0 {
+		n := length
+		if n > uint64(len(buf)) {
+			n = uint64(len(buf))
+		}
+		if _, err := io.ReadFull(rec.data, buf[:n]); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return ErrInvalidDiff
+			}
+			return err
+		}
+		if _, err := image.WriteAt(buf[:n], int64(offset)); err != nil {
+			return err
+		}
+		offset += n
+		length -= n
+	}
+	return nil
+}
+
+// zeroBlock is the pattern used by writeZeroes.
+var zeroBlock = make([]byte, 512)
+
+// writeZeroes makes length bytes of the image starting at offset read as
+// zeros. Unlike Discard this also zeroes parts of objects.
+func (image *Image) writeZeroes(offset, length uint64) error {
+	bs := uint64(len(zeroBlock))
+	if head := length - length%bs; head > 0 {
+		if _, err := image.WriteSame(offset, head, zeroBlock, rados.OpFlagNone); err != nil {
+			return err
+		}
+		offset += head
+		length -= head
+	}
+	if length > 0 {
+		_, err := image.WriteAt(zeroBlock[:length], int64(offset))
+		return err
+	}
+	return nil
+}
+
+func (image *Image) hasSnapshot(name string) (bool, error) {
+	snaps, err := image.GetSnapshotNames()
+	if err != nil {
+		return false, err
+	}
+	for _, s := range snaps {
+		if s.Name == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Export writes the full content of the image, as seen at the snapshot the
+// image was opened at, to w. The output is the raw image data, like the
+// default format of the "rbd export" command.
+func (image *Image) Export(w io.Writer) error {
+	if err := image.validate(imageIsOpen); err != nil {
+		return err
+	}
+
+	size, err := image.GetSize()
+	if err != nil {
+		return err
+	}
+
+	buf := make([]byte, exportChunkSize)
+	for offset := uint64(0); offset < size; {
+		n := size - offset
+		if n > uint64(len(buf)) {
+			n = uint64(len(buf))
+		}
+		if _, err = image.ReadAt(buf[:n], int64(offset)); err != nil {
+			return err
+		}
+		if _, err = w.Write(buf[:n]); err != nil {
+			return err
+		}
+		offset += n
+	}
+	return nil
+}
+
+// Import creates a new image with the given name and options and fills it
+// with the raw image data read from r, like the "rbd import" command. The
+// size of the image is the amount of data read. Parts of the data that only
+// hold zeros are not written, keeping the image sparse. If the import fails
+// the new image is removed again.
+func Import(ioctx *rados.IOContext, name string, r io.Reader, rio *ImageOptions) error {
+	if ioctx == nil {
+		return ErrNoIOContext
+	}
+
+	if err := CreateImage(ioctx, name, 0, rio); err != nil {
+		return err
+	}
+	image, err := OpenImage(ioctx, name, NoSnapshot)
+	if err == nil {
+		err = importImage(image, r)
+		if cerr := image.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if err != nil {
+		_ = RemoveImage(ioctx, name)
+	}
+	return err
+}
+
+func importImage(image *Image, r io.Reader) error {
+	var offset, size uint64
+	buf := make([]byte, exportChunkSize)
+	for {
+		n, err := io.ReadFull(r, buf)
+		if err == io.EOF {
+			break
+		}
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return err
+		}
+		end := offset + uint64(n)
+		if end > size {
+			// grow the image in large steps to avoid resizing it for every
+			// chunk of data
+			size = 2 * end
+			if err := image.Resize(size); err != nil {
+				return err
+			}
+		}
+		if !isZero(buf[:n]) {
+			if _, err := image.WriteAt(buf[:n], int64(offset)); err != nil {
+				return err
+			}
+		}
+		offset = end
+		if err == io.ErrUnexpectedEOF {
+			break
+		}
+	}
+	if offset != size {
+		return image.Resize(offset)
+	}
+	return nil
+}
+
+func isZero(b []byte) bool {
+	for _, c := range b {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
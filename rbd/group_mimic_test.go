@@ -0,0 +1,163 @@
+// +build !luminous
+
+package rbd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupCreateRemoveRename(t *testing.T) {
+	conn := radosConnect(t)
+	poolName := GetUUID()
+	err := conn.MakePool(poolName)
+	require.NoError(t, err)
+
+	ioctx, err := conn.OpenIOContext(poolName)
+	require.NoError(t, err)
+
+	defer func() {
+		ioctx.Destroy()
+		conn.DeletePool(poolName)
+		conn.Shutdown()
+	}()
+
+	t.Run("NullIOContext", func(t *testing.T) {
+		err := GroupCreate(nil, "foo")
+		assert.Equal(t, ErrNoIOContext, err)
+		_, err = GroupList(nil)
+		assert.Equal(t, ErrNoIOContext, err)
+		err = GroupImageAdd(ioctx, "foo", nil, "bar")
+		assert.Equal(t, ErrNoIOContext, err)
+		_, err = GroupSnapList(nil, "foo")
+		assert.Equal(t, ErrNoIOContext, err)
+	})
+
+	groups, err := GroupList(ioctx)
+	assert.NoError(t, err)
+	assert.Len(t, groups, 0)
+
+	err = GroupCreate(ioctx, "group1")
+	assert.NoError(t, err)
+	err = GroupCreate(ioctx, "group2")
+	assert.NoError(t, err)
+	err = GroupCreate(ioctx, "group2")
+	assert.Error(t, err)
+
+	groups, err = GroupList(ioctx)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"group1", "group2"}, groups)
+
+	err = GroupRename(ioctx, "group2", "group3")
+	assert.NoError(t, err)
+	groups, err = GroupList(ioctx)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"group1", "group3"}, groups)
+
+	err = GroupRemove(ioctx, "group1")
+	assert.NoError(t, err)
+	err = GroupRemove(ioctx, "group3")
+	assert.NoError(t, err)
+	groups, err = GroupList(ioctx)
+	assert.NoError(t, err)
+	assert.Len(t, groups, 0)
+}
+
+func TestGroupImagesAndSnapshots(t *testing.T) {
+	conn := radosConnect(t)
+	poolName := GetUUID()
+	err := conn.MakePool(poolName)
+	require.NoError(t, err)
+
+	ioctx, err := conn.OpenIOContext(poolName)
+	require.NoError(t, err)
+
+	defer func() {
+		ioctx.Destroy()
+		conn.DeletePool(poolName)
+		conn.Shutdown()
+	}()
+
+	group := "dbgroup"
+	err = GroupCreate(ioctx, group)
+	require.NoError(t, err)
+	defer GroupRemove(ioctx, group)
+
+	options := NewRbdImageOptions()
+	defer options.Destroy()
+	names := []string{GetUUID(), GetUUID()}
+	for _, name := range names {
+		err = CreateImage(ioctx, name, testImageSize, options)
+		require.NoError(t, err)
+		defer RemoveImage(ioctx, name)
+		err = GroupImageAdd(ioctx, group, ioctx, name)
+		require.NoError(t, err)
+	}
+
+	images, err := GroupImageList(ioctx, group)
+	assert.NoError(t, err)
+	if assert.Len(t, images, 2) {
+		for _, gi := range images {
+			assert.Contains(t, names, gi.Name)
+			assert.Equal(t, GroupImageStateAttached, gi.State)
+		}
+	}
+
+	img, err := OpenImage(ioctx, names[0], NoSnapshot)
+	require.NoError(t, err)
+	gi, err := img.GetGroup()
+	assert.NoError(t, err)
+	assert.Equal(t, group, gi.Name)
+
+	_, err = img.WriteAt([]byte("before"), 0)
+	assert.NoError(t, err)
+
+	err = GroupSnapCreate(ioctx, group, "snap1")
+	assert.NoError(t, err)
+	err = GroupSnapRename(ioctx, group, "snap1", "snap2")
+	assert.NoError(t, err)
+
+	snaps, err := GroupSnapList(ioctx, group)
+	assert.NoError(t, err)
+	if assert.Len(t, snaps, 1) {
+		assert.Equal(t, "snap2", snaps[0].Name)
+		assert.Equal(t, GroupSnapStateComplete, snaps[0].State)
+	}
+
+	_, err = img.WriteAt([]byte("after!"), 0)
+	assert.NoError(t, err)
+	assert.NoError(t, img.Close())
+
+	err = GroupSnapRollback(ioctx, group, "snap2")
+	assert.NoError(t, err)
+
+	img, err = OpenImage(ioctx, names[0], NoSnapshot)
+	require.NoError(t, err)
+	buf := make([]byte, 6)
+	_, err = img.ReadAt(buf, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "before", string(buf))
+	assert.NoError(t, img.Close())
+
+	err = GroupSnapRemove(ioctx, group, "snap2")
+	assert.NoError(t, err)
+	snaps, err = GroupSnapList(ioctx, group)
+	assert.NoError(t, err)
+	assert.Len(t, snaps, 0)
+
+	err = GroupImageRemove(ioctx, group, ioctx, names[0])
+	assert.NoError(t, err)
+	img, err = OpenImage(ioctx, names[1], NoSnapshot)
+	require.NoError(t, err)
+	id, err := img.GetId()
+	assert.NoError(t, err)
+	assert.NoError(t, img.Close())
+	err = GroupImageRemoveByID(ioctx, group, ioctx, id)
+	assert.NoError(t, err)
+
+	images, err = GroupImageList(ioctx, group)
+	assert.NoError(t, err)
+	assert.Len(t, images, 0)
+}
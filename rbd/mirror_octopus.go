@@ -0,0 +1,655 @@
+// +build !luminous,!mimic,!nautilus
+//
+// Ceph Octopus is the first release that supports snapshot based mirroring
+// and the site name based peer management functions.
+
+package rbd
+
+// #cgo LDFLAGS: -lrbd
+// #include <stdlib.h>
+// #include <rados/librados.h>
+// #include <rbd/librbd.h>
+import "C"
+
+import (
+	"time"
+	"unsafe"
+
+	"github.com/ceph/go-ceph/internal/retry"
+	"github.com/ceph/go-ceph/rados"
+)
+
+// MirrorMode is used to indicate the mirroring mode of a pool.
+type MirrorMode int64
+
+const (
+	// MirrorModeDisabled disables mirroring.
+	MirrorModeDisabled = MirrorMode(C.RBD_MIRROR_MODE_DISABLED)
+	// MirrorModeImage enables mirroring on a per-image basis.
+	MirrorModeImage = MirrorMode(C.RBD_MIRROR_MODE_IMAGE)
+	// MirrorModePool enables mirroring of all the journaled images of the
+	// pool.
+	MirrorModePool = MirrorMode(C.RBD_MIRROR_MODE_POOL)
+)
+
+// String representation of MirrorMode.
+func (m MirrorMode) String() string {
+	switch m {
+	case MirrorModeDisabled:
+		return "disabled"
+	case MirrorModeImage:
+		return "image"
+	case MirrorModePool:
+		return "pool"
+	default:
+		return "<unknown>"
+	}
+}
+
+// ImageMirrorMode is used to indicate the mirroring approach of an image.
+type ImageMirrorMode int64
+
+const (
+	// ImageMirrorModeJournal uses the journal to propagate the changes of
+	// the image.
+	ImageMirrorModeJournal = ImageMirrorMode(C.RBD_MIRROR_IMAGE_MODE_JOURNAL)
+	// ImageMirrorModeSnapshot uses mirror snapshots to propagate the
+	// changes of the image.
+	ImageMirrorModeSnapshot = ImageMirrorMode(C.RBD_MIRROR_IMAGE_MODE_SNAPSHOT)
+)
+
+// String representation of ImageMirrorMode.
+func (m ImageMirrorMode) String() string {
+	switch m {
+	case ImageMirrorModeJournal:
+		return "journal"
+	case ImageMirrorModeSnapshot:
+		return "snapshot"
+	default:
+		return "<unknown>"
+	}
+}
+
+// MirrorImageState represents the mirroring state of an image.
+type MirrorImageState int64
+
+const (
+	// MirrorImageDisabling is the representation of
+	// RBD_MIRROR_IMAGE_DISABLING from librbd.
+	MirrorImageDisabling = MirrorImageState(C.RBD_MIRROR_IMAGE_DISABLING)
+	// MirrorImageEnabled is the representation of
+	// RBD_MIRROR_IMAGE_ENABLED from librbd.
+	MirrorImageEnabled = MirrorImageState(C.RBD_MIRROR_IMAGE_ENABLED)
+	// MirrorImageDisabled is the representation of
+	// RBD_MIRROR_IMAGE_DISABLED from librbd.
+	MirrorImageDisabled = MirrorImageState(C.RBD_MIRROR_IMAGE_DISABLED)
+)
+
+// String representation of MirrorImageState.
+func (s MirrorImageState) String() string {
+	switch s {
+	case MirrorImageDisabling:
+		return "disabling"
+	case MirrorImageEnabled:
+		return "enabled"
+	case MirrorImageDisabled:
+		return "disabled"
+	default:
+		return "<unknown>"
+	}
+}
+
+// MirrorImageStatusState is the replication state of an image on a site.
+type MirrorImageStatusState int64
+
+const (
+	// MirrorImageStatusStateUnknown is the representation of
+	// MIRROR_IMAGE_STATUS_STATE_UNKNOWN from librbd.
+	MirrorImageStatusStateUnknown = MirrorImageStatusState(C.MIRROR_IMAGE_STATUS_STATE_UNKNOWN)
+	// MirrorImageStatusStateError is the representation of
+	// MIRROR_IMAGE_STATUS_STATE_ERROR from librbd.
+	MirrorImageStatusStateError = MirrorImageStatusState(C.MIRROR_IMAGE_STATUS_STATE_ERROR)
+	// MirrorImageStatusStateSyncing is the representation of
+	// MIRROR_IMAGE_STATUS_STATE_SYNCING from librbd.
+	MirrorImageStatusStateSyncing = MirrorImageStatusState(C.MIRROR_IMAGE_STATUS_STATE_SYNCING)
+	// MirrorImageStatusStateStartingReplay is the representation of
+	// MIRROR_IMAGE_STATUS_STATE_STARTING_REPLAY from librbd.
+	MirrorImageStatusStateStartingReplay = MirrorImageStatusState(C.MIRROR_IMAGE_STATUS_STATE_STARTING_REPLAY)
+	// MirrorImageStatusStateReplaying is the representation of
+	// MIRROR_IMAGE_STATUS_STATE_REPLAYING from librbd.
+	MirrorImageStatusStateReplaying = MirrorImageStatusState(C.MIRROR_IMAGE_STATUS_STATE_REPLAYING)
+	// MirrorImageStatusStateStoppingReplay is the representation of
+	// MIRROR_IMAGE_STATUS_STATE_STOPPING_REPLAY from librbd.
+	MirrorImageStatusStateStoppingReplay = MirrorImageStatusState(C.MIRROR_IMAGE_STATUS_STATE_STOPPING_REPLAY)
+	// MirrorImageStatusStateStopped is the representation of
+	// MIRROR_IMAGE_STATUS_STATE_STOPPED from librbd.
+	MirrorImageStatusStateStopped = MirrorImageStatusState(C.MIRROR_IMAGE_STATUS_STATE_STOPPED)
+)
+
+// String representation of MirrorImageStatusState.
+func (s MirrorImageStatusState) String() string {
+	switch s {
+	case MirrorImageStatusStateUnknown:
+		return "unknown"
+	case MirrorImageStatusStateError:
+		return "error"
+	case MirrorImageStatusStateSyncing:
+		return "syncing"
+	case MirrorImageStatusStateStartingReplay:
+		return "starting_replay"
+	case MirrorImageStatusStateReplaying:
+		return "replaying"
+	case MirrorImageStatusStateStoppingReplay:
+		return "stopping_replay"
+	case MirrorImageStatusStateStopped:
+		return "stopped"
+	default:
+		return "<unknown>"
+	}
+}
+
+// MirrorPeerDirection is the direction in which images are replicated with a
+// peer site.
+type MirrorPeerDirection int64
+
+const (
+	// MirrorPeerDirectionRx receives images from the peer.
+	MirrorPeerDirectionRx = MirrorPeerDirection(C.RBD_MIRROR_PEER_DIRECTION_RX)
+	// MirrorPeerDirectionTx sends images to the peer.
+	MirrorPeerDirectionTx = MirrorPeerDirection(C.RBD_MIRROR_PEER_DIRECTION_TX)
+	// MirrorPeerDirectionRxTx both receives images from and sends images
+	// to the peer.
+	MirrorPeerDirectionRxTx = MirrorPeerDirection(C.RBD_MIRROR_PEER_DIRECTION_RX_TX)
+)
+
+// String representation of MirrorPeerDirection.
+func (d MirrorPeerDirection) String() string {
+	switch d {
+	case MirrorPeerDirectionRx:
+		return "rx-only"
+	case MirrorPeerDirectionTx:
+		return "tx-only"
+	case MirrorPeerDirectionRxTx:
+		return "rx-tx"
+	default:
+		return "<unknown>"
+	}
+}
+
+// MirrorPeerSite describes a peer site of a mirrored pool.
+type MirrorPeerSite struct {
+	UUID       string
+	Direction  MirrorPeerDirection
+	SiteName   string
+	MirrorUUID string
+	ClientName string
+	LastSeen   time.Time
+}
+
+// MirrorImageInfo holds the mirroring information of an image.
+type MirrorImageInfo struct {
+	GlobalID string
+	State    MirrorImageState
+	Primary  bool
+}
+
+// SiteMirrorImageStatus is the replication status of an image on one site.
+type SiteMirrorImageStatus struct {
+	// MirrorUUID identifies the site. It is empty for the local site.
+	MirrorUUID  string
+	State       MirrorImageStatusState
+	Description string
+	LastUpdate  time.Time
+	Up          bool
+}
+
+// GlobalMirrorImageStatus is the replication status of an image on all the
+// sites.
+type GlobalMirrorImageStatus struct {
+	Name         string
+	Info         MirrorImageInfo
+	SiteStatuses []SiteMirrorImageStatus
+}
+
+// LocalStatus returns the replication status of the image on the local
+// site. ErrNotFound is returned if the local status is not available.
+func (s GlobalMirrorImageStatus) LocalStatus() (SiteMirrorImageStatus, error) {
+	for _, ss := range s.SiteStatuses {
+		if ss.MirrorUUID == "" {
+			return ss, nil
+		}
+	}
+	return SiteMirrorImageStatus{}, ErrNotFound
+}
+
+// SetMirrorMode is used to enable or disable mirroring on the pool of the
+// given ioctx.
+//
+// Implements:
+//  int rbd_mirror_mode_set(rados_ioctx_t io_ctx,
+//                          rbd_mirror_mode_t mirror_mode);
+func SetMirrorMode(ioctx *rados.IOContext, mode MirrorMode) error {
+	if ioctx == nil {
+		return ErrNoIOContext
+	}
+	ret := C.rbd_mirror_mode_set(
+		cephIoctx(ioctx),
+		C.rbd_mirror_mode_t(mode))
+	return getError(ret)
+}
+
+// GetMirrorMode returns the mirroring mode of the pool of the given ioctx.
+//
+// Implements:
+//  int rbd_mirror_mode_get(rados_ioctx_t io_ctx,
+//                          rbd_mirror_mode_t *mirror_mode);
+func GetMirrorMode(ioctx *rados.IOContext) (MirrorMode, error) {
+	if ioctx == nil {
+		return MirrorModeDisabled, ErrNoIOContext
+	}
+	var mode C.rbd_mirror_mode_t
+	ret := C.rbd_mirror_mode_get(cephIoctx(ioctx), &mode)
+	if err := getError(ret); err != nil {
+		return MirrorModeDisabled, err
+	}
+	return MirrorMode(mode), nil
+}
+
+// GetMirrorUUID returns the mirror UUID of the pool of the given ioctx.
+//
+// Implements:
+//  int rbd_mirror_uuid_get(rados_ioctx_t io_ctx, char *uuid,
+//                          size_t *max_len);
+func GetMirrorUUID(ioctx *rados.IOContext) (string, error) {
+	if ioctx == nil {
+		return "", ErrNoIOContext
+	}
+	var (
+		err error
+		buf []byte
+	)
+	retry.WithSizes(64, 4096, func(size int) retry.Hint {
+		cSize := C.size_t(size)
+		buf = make([]byte, cSize)
+		ret := C.rbd_mirror_uuid_get(
+			cephIoctx(ioctx),
+			(*C.char)(unsafe.Pointer(&buf[0])),
+			&cSize)
+		err = getErrorIfNegative(ret)
+		return retry.Size(int(cSize)).If(err == errRange)
+	})
+	if err != nil {
+		return "", err
+	}
+	return C.GoString((*C.char)(unsafe.Pointer(&buf[0]))), nil
+}
+
+// SetMirrorSiteName sets the name of the site of the cluster. The site name
+// is used to identify the cluster to its mirroring peers.
+//
+// Implements:
+//  int rbd_mirror_site_name_set(rados_t cluster, const char *name);
+func SetMirrorSiteName(conn *rados.Conn, name string) error {
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+	ret := C.rbd_mirror_site_name_set(C.rados_t(conn.Cluster()), cName)
+	return getError(ret)
+}
+
+// GetMirrorSiteName returns the name of the site of the cluster.
+//
+// Implements:
+//  int rbd_mirror_site_name_get(rados_t cluster, char *name,
+//                               size_t *max_len);
+func GetMirrorSiteName(conn *rados.Conn) (string, error) {
+	var (
+		err error
+		buf []byte
+	)
+	retry.WithSizes(128, 4096, func(size int) retry.Hint {
+		cSize := C.size_t(size)
+		buf = make([]byte, cSize)
+		ret := C.rbd_mirror_site_name_get(
+			C.rados_t(conn.Cluster()),
+			(*C.char)(unsafe.Pointer(&buf[0])),
+			&cSize)
+		err = getErrorIfNegative(ret)
+		return retry.Size(int(cSize)).If(err == errRange)
+	})
+	if err != nil {
+		return "", err
+	}
+	return C.GoString((*C.char)(unsafe.Pointer(&buf[0]))), nil
+}
+
+// AddMirrorPeerSite adds a peer site to the pool of the given ioctx and
+// returns the UUID of the new peer.
+//
+// Implements:
+//  int rbd_mirror_peer_site_add(rados_ioctx_t io_ctx, char *uuid,
+//                               size_t uuid_max_length,
+//                               rbd_mirror_peer_direction_t direction,
+//                               const char *site_name,
+//                               const char *client_name);
+func AddMirrorPeerSite(ioctx *rados.IOContext, siteName, clientName string,
+	direction MirrorPeerDirection) (string, error) {
+
+	if ioctx == nil {
+		return "", ErrNoIOContext
+	}
+
+	cSiteName := C.CString(siteName)
+	defer C.free(unsafe.Pointer(cSiteName))
+	cClientName := C.CString(clientName)
+	defer C.free(unsafe.Pointer(cClientName))
+
+	// a UUID is 36 characters, leave plenty of room for the terminator
+	buf := make([]byte, 64)
+	ret := C.rbd_mirror_peer_site_add(
+		cephIoctx(ioctx),
+		(*C.char)(unsafe.Pointer(&buf[0])),
+		C.size_t(len(buf)),
+		C.rbd_mirror_peer_direction_t(direction),
+		cSiteName,
+		cClientName)
+	if err := getError(ret); err != nil {
+		return "", err
+	}
+	return C.GoString((*C.char)(unsafe.Pointer(&buf[0]))), nil
+}
+
+// RemoveMirrorPeerSite removes the peer site with the given UUID from the
+// pool of the given ioctx.
+//
+// Implements:
+//  int rbd_mirror_peer_site_remove(rados_ioctx_t io_ctx, const char *uuid);
+func RemoveMirrorPeerSite(ioctx *rados.IOContext, uuid string) error {
+	if ioctx == nil {
+		return ErrNoIOContext
+	}
+	cUUID := C.CString(uuid)
+	defer C.free(unsafe.Pointer(cUUID))
+	ret := C.rbd_mirror_peer_site_remove(cephIoctx(ioctx), cUUID)
+	return getError(ret)
+}
+
+// SetMirrorPeerSiteName changes the site name of the peer site with the
+// given UUID.
+//
+// Implements:
+//  int rbd_mirror_peer_site_set_name(rados_ioctx_t io_ctx,
+//                                    const char *uuid,
+//                                    const char *site_name);
+func SetMirrorPeerSiteName(ioctx *rados.IOContext, uuid, siteName string) error {
+	if ioctx == nil {
+		return ErrNoIOContext
+	}
+	cUUID := C.CString(uuid)
+	defer C.free(unsafe.Pointer(cUUID))
+	cSiteName := C.CString(siteName)
+	defer C.free(unsafe.Pointer(cSiteName))
+	ret := C.rbd_mirror_peer_site_set_name(cephIoctx(ioctx), cUUID, cSiteName)
+	return getError(ret)
+}
+
+// SetMirrorPeerSiteClientName changes the client name used to connect to
+// the peer site with the given UUID.
+//
+// Implements:
+//  int rbd_mirror_peer_site_set_client_name(rados_ioctx_t io_ctx,
+//                                           const char *uuid,
+//                                           const char *client_name);
+func SetMirrorPeerSiteClientName(ioctx *rados.IOContext, uuid, clientName string) error {
+	if ioctx == nil {
+		return ErrNoIOContext
+	}
+	cUUID := C.CString(uuid)
+	defer C.free(unsafe.Pointer(cUUID))
+	cClientName := C.CString(clientName)
+	defer C.free(unsafe.Pointer(cClientName))
+	ret := C.rbd_mirror_peer_site_set_client_name(
+		cephIoctx(ioctx), cUUID, cClientName)
+	return getError(ret)
+}
+
+// ListMirrorPeerSite returns the peer sites of the pool of the given ioctx.
+//
+// Implements:
+//  int rbd_mirror_peer_site_list(rados_ioctx_t io_ctx,
+//                                rbd_mirror_peer_site_t *peers,
+//                                int *max_peers);
+func ListMirrorPeerSite(ioctx *rados.IOContext) ([]MirrorPeerSite, error) {
+	if ioctx == nil {
+		return nil, ErrNoIOContext
+	}
+	var (
+		err    error
+		cPeers []C.rbd_mirror_peer_site_t
+		cNum   C.int
+	)
+	retry.WithSizes(16, 4096, func(size int) retry.Hint {
+		cNum = C.int(size)
+		cPeers = make([]C.rbd_mirror_peer_site_t, cNum)
+		ret := C.rbd_mirror_peer_site_list(cephIoctx(ioctx), &cPeers[0], &cNum)
+		err = getErrorIfNegative(ret)
+		return retry.Size(int(cNum)).If(err == errRange)
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer C.rbd_mirror_peer_site_list_cleanup(&cPeers[0], cNum)
+
+	peers := make([]MirrorPeerSite, cNum)
+	for i := range peers {
+		p := &cPeers[i]
+		peers[i] = MirrorPeerSite{
+			UUID:       C.GoString(p.uuid),
+			Direction:  MirrorPeerDirection(p.direction),
+			SiteName:   C.GoString(p.site_name),
+			MirrorUUID: C.GoString(p.mirror_uuid),
+			ClientName: C.GoString(p.client_name),
+			LastSeen:   time.Unix(int64(p.last_seen), 0),
+		}
+	}
+	return peers, nil
+}
+
+// MirrorImageStatusSummary returns the number of images of the pool of the
+// given ioctx in each replication state.
+//
+// Implements:
+//  int rbd_mirror_image_status_summary(rados_ioctx_t io_ctx,
+//                                      rbd_mirror_image_status_state_t *states,
+//                                      int *counts, size_t *maxlen);
+func MirrorImageStatusSummary(ioctx *rados.IOContext) (map[MirrorImageStatusState]uint, error) {
+	if ioctx == nil {
+		return nil, ErrNoIOContext
+	}
+	// there are seven states, make room for a few more
+	var (
+		cStates [16]C.rbd_mirror_image_status_state_t
+		cCounts [16]C.int
+		cLen    = C.size_t(len(cStates))
+	)
+	ret := C.rbd_mirror_image_status_summary(
+		cephIoctx(ioctx), &cStates[0], &cCounts[0], &cLen)
+	if err := getErrorIfNegative(ret); err != nil {
+		return nil, err
+	}
+
+	m := make(map[MirrorImageStatusState]uint, cLen)
+	for i := 0; i < int(cLen); i++ {
+		m[MirrorImageStatusState(cStates[i])] = uint(cCounts[i])
+	}
+	return m, nil
+}
+
+// MirrorEnable enables mirroring of the image using the given mode.
+//
+// Implements:
+//  int rbd_mirror_image_enable2(rbd_image_t image,
+//                               rbd_mirror_image_mode_t mode);
+func (image *Image) MirrorEnable(mode ImageMirrorMode) error {
+	if err := image.validate(imageIsOpen); err != nil {
+		return err
+	}
+	ret := C.rbd_mirror_image_enable2(
+		image.image,
+		C.rbd_mirror_image_mode_t(mode))
+	return getError(ret)
+}
+
+// MirrorDisable disables mirroring of the image. Set force to disable
+// mirroring of a non-primary image.
+//
+// Implements:
+//  int rbd_mirror_image_disable(rbd_image_t image, bool force);
+func (image *Image) MirrorDisable(force bool) error {
+	if err := image.validate(imageIsOpen); err != nil {
+		return err
+	}
+	ret := C.rbd_mirror_image_disable(image.image, C.bool(force))
+	return getError(ret)
+}
+
+// MirrorPromote promotes the image to primary. Set force to promote the
+// image while the current primary can not be demoted, for example when the
+// peer site is unreachable.
+//
+// Implements:
+//  int rbd_mirror_image_promote(rbd_image_t image, bool force);
+func (image *Image) MirrorPromote(force bool) error {
+	if err := image.validate(imageIsOpen); err != nil {
+		return err
+	}
+	ret := C.rbd_mirror_image_promote(image.image, C.bool(force))
+	return getError(ret)
+}
+
+// MirrorDemote demotes the image to non-primary.
+//
+// Implements:
+//  int rbd_mirror_image_demote(rbd_image_t image);
+func (image *Image) MirrorDemote() error {
+	if err := image.validate(imageIsOpen); err != nil {
+		return err
+	}
+	ret := C.rbd_mirror_image_demote(image.image)
+	return getError(ret)
+}
+
+// MirrorResync flags a non-primary image to be resynchronized from the
+// primary image.
+//
+// Implements:
+//  int rbd_mirror_image_resync(rbd_image_t image);
+func (image *Image) MirrorResync() error {
+	if err := image.validate(imageIsOpen); err != nil {
+		return err
+	}
+	ret := C.rbd_mirror_image_resync(image.image)
+	return getError(ret)
+}
+
+// CreateMirrorSnapshot creates a mirror snapshot of an image that is
+// mirrored in snapshot mode and returns its ID.
+//
+// Implements:
+//  int rbd_mirror_image_create_snapshot(rbd_image_t image,
+//                                       uint64_t *snap_id);
+func (image *Image) CreateMirrorSnapshot() (uint64, error) {
+	if err := image.validate(imageIsOpen); err != nil {
+		return 0, err
+	}
+	var snapID C.uint64_t
+	ret := C.rbd_mirror_image_create_snapshot(image.image, &snapID)
+	return uint64(snapID), getError(ret)
+}
+
+// GetMirrorImageInfo returns the mirroring information of the image.
+//
+// Implements:
+//  int rbd_mirror_image_get_info(rbd_image_t image,
+//                                rbd_mirror_image_info_t *mirror_image_info,
+//                                size_t info_size);
+func (image *Image) GetMirrorImageInfo() (*MirrorImageInfo, error) {
+	if err := image.validate(imageIsOpen); err != nil {
+		return nil, err
+	}
+	var cInfo C.rbd_mirror_image_info_t
+	ret := C.rbd_mirror_image_get_info(
+		image.image,
+		&cInfo,
+		C.size_t(unsafe.Sizeof(cInfo)))
+	if err := getError(ret); err != nil {
+		return nil, err
+	}
+	defer C.rbd_mirror_image_get_info_cleanup(&cInfo)
+
+	info := convertMirrorImageInfo(&cInfo)
+	return &info, nil
+}
+
+// GetImageMirrorMode returns the mirroring mode of the image.
+//
+// Implements:
+//  int rbd_mirror_image_get_mode(rbd_image_t image,
+//                                rbd_mirror_image_mode_t *mode);
+func (image *Image) GetImageMirrorMode() (ImageMirrorMode, error) {
+	if err := image.validate(imageIsOpen); err != nil {
+		return ImageMirrorModeJournal, err
+	}
+	var mode C.rbd_mirror_image_mode_t
+	ret := C.rbd_mirror_image_get_mode(image.image, &mode)
+	return ImageMirrorMode(mode), getError(ret)
+}
+
+// GetGlobalMirrorStatus returns the replication status of the image on all
+// the sites.
+//
+// Implements:
+//  int rbd_mirror_image_get_global_status(rbd_image_t image,
+//        rbd_mirror_image_global_status_t *mirror_image_global_status,
+//        size_t status_size);
+func (image *Image) GetGlobalMirrorStatus() (GlobalMirrorImageStatus, error) {
+	if err := image.validate(imageIsOpen); err != nil {
+		return GlobalMirrorImageStatus{}, err
+	}
+	var cStatus C.rbd_mirror_image_global_status_t
+	ret := C.rbd_mirror_image_get_global_status(
+		image.image,
+		&cStatus,
+		C.size_t(unsafe.Sizeof(cStatus)))
+	if err := getError(ret); err != nil {
+		return GlobalMirrorImageStatus{}, err
+	}
+	defer C.rbd_mirror_image_global_status_cleanup(&cStatus)
+
+	status := GlobalMirrorImageStatus{
+		Name:         C.GoString(cStatus.name),
+		Info:         convertMirrorImageInfo(&cStatus.info),
+		SiteStatuses: make([]SiteMirrorImageStatus, cStatus.site_statuses_count),
+	}
+	size := unsafe.Sizeof(*cStatus.site_statuses)
+	for i := range status.SiteStatuses {
+		ss := (*C.rbd_mirror_image_site_status_t)(unsafe.Pointer(
+			uintptr(unsafe.Pointer(cStatus.site_statuses)) + uintptr(i)*size))
+		status.SiteStatuses[i] = SiteMirrorImageStatus{
+			MirrorUUID:  C.GoString(ss.mirror_uuid),
+			State:       MirrorImageStatusState(ss.state),
+			Description: C.GoString(ss.description),
+			LastUpdate:  time.Unix(int64(ss.last_update), 0),
+			Up:          bool(ss.up),
+		}
+	}
+	return status, nil
+}
+
+func convertMirrorImageInfo(cInfo *C.rbd_mirror_image_info_t) MirrorImageInfo {
+	return MirrorImageInfo{
+		GlobalID: C.GoString(cInfo.global_id),
+		State:    MirrorImageState(cInfo.state),
+		Primary:  bool(cInfo.primary),
+	}
+}
+
@@ -0,0 +1,191 @@
+// +build !luminous,!mimic,!nautilus
+
+package rbd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMirrorMode(t *testing.T) {
+	conn := radosConnect(t)
+	poolName := GetUUID()
+	err := conn.MakePool(poolName)
+	require.NoError(t, err)
+
+	ioctx, err := conn.OpenIOContext(poolName)
+	require.NoError(t, err)
+
+	defer func() {
+		ioctx.Destroy()
+		conn.DeletePool(poolName)
+		conn.Shutdown()
+	}()
+
+	t.Run("NullIOContext", func(t *testing.T) {
+		err := SetMirrorMode(nil, MirrorModePool)
+		assert.Equal(t, ErrNoIOContext, err)
+		_, err = GetMirrorMode(nil)
+		assert.Equal(t, ErrNoIOContext, err)
+		_, err = ListMirrorPeerSite(nil)
+		assert.Equal(t, ErrNoIOContext, err)
+		_, err = MirrorImageStatusSummary(nil)
+		assert.Equal(t, ErrNoIOContext, err)
+	})
+
+	t.Run("SetGetMode", func(t *testing.T) {
+		mode, err := GetMirrorMode(ioctx)
+		assert.NoError(t, err)
+		assert.Equal(t, MirrorModeDisabled, mode)
+
+		err = SetMirrorMode(ioctx, MirrorModePool)
+		assert.NoError(t, err)
+		mode, err = GetMirrorMode(ioctx)
+		assert.NoError(t, err)
+		assert.Equal(t, MirrorModePool, mode)
+		assert.Equal(t, "pool", mode.String())
+
+		uuid, err := GetMirrorUUID(ioctx)
+		assert.NoError(t, err)
+		assert.NotEqual(t, "", uuid)
+
+		err = SetMirrorMode(ioctx, MirrorModeImage)
+		assert.NoError(t, err)
+		mode, err = GetMirrorMode(ioctx)
+		assert.NoError(t, err)
+		assert.Equal(t, MirrorModeImage, mode)
+	})
+
+	t.Run("SiteName", func(t *testing.T) {
+		err := SetMirrorSiteName(conn, "site-a")
+		assert.NoError(t, err)
+		name, err := GetMirrorSiteName(conn)
+		assert.NoError(t, err)
+		assert.Equal(t, "site-a", name)
+	})
+
+	t.Run("Peers", func(t *testing.T) {
+		peers, err := ListMirrorPeerSite(ioctx)
+		assert.NoError(t, err)
+		assert.Len(t, peers, 0)
+
+		uuid, err := AddMirrorPeerSite(
+			ioctx, "site-b", "client.mirror", MirrorPeerDirectionRxTx)
+		require.NoError(t, err)
+		assert.NotEqual(t, "", uuid)
+
+		err = SetMirrorPeerSiteName(ioctx, uuid, "site-c")
+		assert.NoError(t, err)
+		err = SetMirrorPeerSiteClientName(ioctx, uuid, "client.other")
+		assert.NoError(t, err)
+
+		peers, err = ListMirrorPeerSite(ioctx)
+		assert.NoError(t, err)
+		if assert.Len(t, peers, 1) {
+			assert.Equal(t, uuid, peers[0].UUID)
+			assert.Equal(t, "site-c", peers[0].SiteName)
+			assert.Equal(t, "client.other", peers[0].ClientName)
+			assert.Equal(t, MirrorPeerDirectionRxTx, peers[0].Direction)
+		}
+
+		err = RemoveMirrorPeerSite(ioctx, uuid)
+		assert.NoError(t, err)
+		peers, err = ListMirrorPeerSite(ioctx)
+		assert.NoError(t, err)
+		assert.Len(t, peers, 0)
+	})
+}
+
+func TestMirrorImage(t *testing.T) {
+	conn := radosConnect(t)
+	poolName := GetUUID()
+	err := conn.MakePool(poolName)
+	require.NoError(t, err)
+
+	ioctx, err := conn.OpenIOContext(poolName)
+	require.NoError(t, err)
+
+	defer func() {
+		ioctx.Destroy()
+		conn.DeletePool(poolName)
+		conn.Shutdown()
+	}()
+
+	err = SetMirrorMode(ioctx, MirrorModeImage)
+	require.NoError(t, err)
+
+	name := GetUUID()
+	options := NewRbdImageOptions()
+	defer options.Destroy()
+	err = CreateImage(ioctx, name, testImageSize, options)
+	require.NoError(t, err)
+	defer RemoveImage(ioctx, name)
+
+	img, err := OpenImage(ioctx, name, NoSnapshot)
+	require.NoError(t, err)
+	defer img.Close()
+
+	t.Run("NotOpen", func(t *testing.T) {
+		closed := GetImage(ioctx, name)
+		err := closed.MirrorEnable(ImageMirrorModeSnapshot)
+		assert.Equal(t, ErrImageNotOpen, err)
+		_, err = closed.GetMirrorImageInfo()
+		assert.Equal(t, ErrImageNotOpen, err)
+		_, err = closed.GetGlobalMirrorStatus()
+		assert.Equal(t, ErrImageNotOpen, err)
+	})
+
+	err = img.MirrorEnable(ImageMirrorModeSnapshot)
+	require.NoError(t, err)
+
+	mode, err := img.GetImageMirrorMode()
+	assert.NoError(t, err)
+	assert.Equal(t, ImageMirrorModeSnapshot, mode)
+
+	info, err := img.GetMirrorImageInfo()
+	require.NoError(t, err)
+	assert.Equal(t, MirrorImageEnabled, info.State)
+	assert.True(t, info.Primary)
+	assert.NotEqual(t, "", info.GlobalID)
+
+	snapID, err := img.CreateMirrorSnapshot()
+	assert.NoError(t, err)
+	assert.NotZero(t, snapID)
+
+	status, err := img.GetGlobalMirrorStatus()
+	assert.NoError(t, err)
+	assert.Equal(t, name, status.Name)
+	assert.Equal(t, info.GlobalID, status.Info.GlobalID)
+
+	summary, err := MirrorImageStatusSummary(ioctx)
+	assert.NoError(t, err)
+	total := uint(0)
+	for _, count := range summary {
+		total += count
+	}
+	assert.Equal(t, uint(1), total)
+
+	err = img.MirrorDemote()
+	assert.NoError(t, err)
+	info, err = img.GetMirrorImageInfo()
+	assert.NoError(t, err)
+	assert.False(t, info.Primary)
+
+	err = img.MirrorPromote(false)
+	assert.NoError(t, err)
+	info, err = img.GetMirrorImageInfo()
+	assert.NoError(t, err)
+	assert.True(t, info.Primary)
+
+	// a primary image can not be resynced
+	err = img.MirrorResync()
+	assert.Error(t, err)
+
+	err = img.MirrorDisable(false)
+	assert.NoError(t, err)
+	info, err = img.GetMirrorImageInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, MirrorImageDisabled, info.State)
+}